@@ -0,0 +1,14 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// CreateAPIKey issues a key for the client's tenant with the name, scopes
+// and expiry of k. The key itself is only ever returned here.
+func (c *Client) CreateAPIKey(ctx context.Context, k APIKey) (NewAPIKey, error) {
+	var out NewAPIKey
+	err := c.do(ctx, http.MethodPost, "/api/api-keys", nil, k, &out)
+	return out, err
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func (c *Client) ListCategories(ctx context.Context, p ListParams) ([]Category, error) {
+	q := url.Values{}
+	setPage(q, p.Limit, p.Offset)
+
+	var out page[Category]
+	if err := c.do(ctx, http.MethodGet, "/api/categories", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+// AllCategories iterates over every category from p.Offset on, fetching
+// p.Limit at a time. It stops at the first error, which it yields.
+func (c *Client) AllCategories(ctx context.Context, p ListParams) iter.Seq2[Category, error] {
+	return paginate(p.Limit, p.Offset, func(limit, offset int) ([]Category, error) {
+		return c.ListCategories(ctx, ListParams{Limit: limit, Offset: offset})
+	})
+}
+
+func (c *Client) GetCategory(ctx context.Context, id int) (Category, error) {
+	var out Category
+	err := c.do(ctx, http.MethodGet, "/api/categories/"+strconv.Itoa(id), nil, nil, &out)
+	return out, err
+}
+
+func (c *Client) CreateCategory(ctx context.Context, cat Category) (Category, error) {
+	var out Category
+	err := c.do(ctx, http.MethodPost, "/api/categories", nil, cat, &out)
+	return out, err
+}
+
+func (c *Client) UpdateCategory(ctx context.Context, id int, cat Category) (Category, error) {
+	var out Category
+	err := c.do(ctx, http.MethodPut, "/api/categories/"+strconv.Itoa(id), nil, cat, &out)
+	return out, err
+}
+
+func (c *Client) DeleteCategory(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/api/categories/"+strconv.Itoa(id), nil, nil, nil)
+}
@@ -0,0 +1,302 @@
+// Package client calls the POS API over HTTP for other Go services. It
+// unwraps the API's success/error envelope, turns error responses into Go
+// errors and retries requests that failed on the way, with backoff.
+//
+//	c := client.New("http://localhost:8081")
+//	p, err := c.GetProduct(ctx, 42)
+//	if errors.Is(err, client.ErrNotFound) {
+//		...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"pos-api/internal/domain"
+)
+
+// Errors that an APIError wraps, by status code, for errors.Is.
+var (
+	ErrNotFound     = domain.ErrNotFound
+	ErrInvalid      = domain.ErrInvalid
+	ErrConflict     = domain.ErrConflict
+	ErrForbidden    = domain.ErrForbidden
+	ErrUnauthorized = domain.ErrUnauthorized
+)
+
+// APIError is an error response from the API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("pos-api: %d %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns ErrNotFound for a 404, ErrInvalid for a 400,
+// ErrUnauthorized for a 401, ErrForbidden for a 403 and ErrConflict for a
+// 409.
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusBadRequest:
+		return ErrInvalid
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// Client calls one POS API server. Its fields may be changed before the
+// first call.
+type Client struct {
+	// BaseURL is the server's address, such as http://localhost:8081.
+	BaseURL    string
+	HTTPClient *http.Client
+	// Actor and Reason are sent as X-Actor and X-Change-Reason, naming who
+	// makes changes in the audit log. WithReason overrides Reason per call.
+	Actor  string
+	Reason string
+	// Tenant, an ID or slug, is sent as X-Tenant-ID to name the tenant the
+	// calls work for. Left empty, the server's default tenant is used, or
+	// the API key's.
+	Tenant string
+	// APIKey is sent as a Bearer token in the Authorization header.
+	APIKey string
+	// MaxRetries is how many times a request is sent again after a network
+	// error, a 429 or a 502, 503 or 504. POSTs carry an Idempotency-Key, so
+	// the server applies them once however often they are sent. Backoff is
+	// the wait before the first retry; it doubles for each further one, up
+	// to MaxBackoff, unless the server sends Retry-After.
+	MaxRetries int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// New returns a client for the server at baseURL that retries three times,
+// starting at 200ms apart.
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		MaxRetries: 3,
+		Backoff:    200 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+	}
+}
+
+type contextKey int
+
+const (
+	idempotencyKey contextKey = iota
+	reasonKey
+)
+
+// WithIdempotencyKey makes the POST sent with ctx carry key as its
+// Idempotency-Key. Without one, each POST gets a new random key, which is
+// reused for its retries; passing a key of your own also makes the call safe
+// to repeat after the client gives up.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey, key)
+}
+
+// WithReason sets the X-Change-Reason of the calls made with ctx.
+func WithReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, reasonKey, reason)
+}
+
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+}
+
+// do sends a request with in, if not nil, as its JSON body and decodes the
+// data of the response into out, if not nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+	resp, err := c.request(ctx, method, path, query, "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decode(resp, out)
+}
+
+// request sends a request, retrying it as the client is set up to, and
+// returns the first response that is not retried. The caller closes its body.
+func (c *Client) request(ctx context.Context, method, path string, query url.Values, contentType string, body []byte) (*http.Response, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	key := ""
+	if method == http.MethodPost {
+		key, _ = ctx.Value(idempotencyKey).(string)
+		if key == "" {
+			key = newIdempotencyKey()
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, u, contentType, body, key)
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+		case retryable(resp):
+			wait = retryAfter(resp)
+			resp.Body.Close()
+			err = &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		default:
+			return resp, nil
+		}
+		if attempt >= c.MaxRetries {
+			return nil, err
+		}
+
+		if wait == 0 {
+			wait = c.backoff(attempt)
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, u, contentType string, body []byte, key string) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	if c.Actor != "" {
+		req.Header.Set("X-Actor", c.Actor)
+	}
+	if c.Tenant != "" {
+		req.Header.Set("X-Tenant-ID", c.Tenant)
+	}
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	reason := c.Reason
+	if v, ok := ctx.Value(reasonKey).(string); ok {
+		reason = v
+	}
+	if reason != "" {
+		req.Header.Set("X-Change-Reason", reason)
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return hc.Do(req)
+}
+
+func decode(resp *http.Response, out any) error {
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		if resp.StatusCode >= 400 {
+			return &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		}
+		return fmt.Errorf("pos-api: decoding response: %w", err)
+	}
+	if resp.StatusCode >= 400 || !env.Success {
+		msg := env.Error
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: msg}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(env.Data, out)
+}
+
+// retryable reports whether resp is worth sending the request again for. A
+// 409 with a Retry-After means the first try of the same POST is still
+// running on the server.
+func retryable(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusConflict:
+		return resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// retryAfter reads the Retry-After header in seconds, or zero.
+func retryAfter(resp *http.Response) time.Duration {
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// backoff is the wait before retry attempt+1: Backoff doubled attempt times,
+// at most MaxBackoff, with up to a fifth taken off at random so that clients
+// retrying together spread out.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.Backoff
+	for i := 0; i < attempt && (c.MaxBackoff <= 0 || d < c.MaxBackoff); i++ {
+		d *= 2
+	}
+	if c.MaxBackoff > 0 && d > c.MaxBackoff {
+		d = c.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d - time.Duration(rand.Int64N(int64(d)/5+1))
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = crand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,233 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/http/handler"
+	"pos-api/internal/http/httputil"
+	"pos-api/internal/http/routes"
+	"pos-api/internal/repository_memory"
+	"pos-api/internal/service"
+)
+
+// testServer serves the catalog routes over the memory repositories, with
+// the server's idempotency and tenant middleware. The first failures
+// requests get a failStatus, after the request has run when failAfter is
+// set, as if the response was lost on the way back.
+type testServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	failures   int
+	failStatus int
+	failAfter  bool
+	requests   int
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	outbox := repository_memory.NewOutboxRepo()
+	changes := repository_memory.NewChangeLog()
+	audit := repository_memory.NewAuditRepo()
+	categories := repository_memory.NewCategoryRepo(audit, outbox, changes)
+	products := repository_memory.NewProductRepo(audit, outbox, changes)
+	tenants := service.NewTenantService(repository_memory.NewTenantRepo(), domain.Settings{})
+
+	mux := http.NewServeMux()
+	routes.Register(mux, routes.Handlers{
+		Category: handler.NewCategoryHandler(service.NewCategoryService(categories)),
+		Product:  handler.NewProductHandler(service.NewProductService(products, categories)),
+	})
+	api := httputil.WithIdempotency(mux, time.Hour)
+	api = httputil.WithActor(httputil.WithTenant(api, tenants.Resolve, "default"))
+
+	ts := &testServer{}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.mu.Lock()
+		ts.requests++
+		fail, status, after := ts.failures > 0, ts.failStatus, ts.failAfter
+		if fail {
+			ts.failures--
+		}
+		ts.mu.Unlock()
+
+		if !fail {
+			api.ServeHTTP(w, r)
+			return
+		}
+		if after {
+			api.ServeHTTP(httptest.NewRecorder(), r)
+		}
+		w.Header().Set("Retry-After", "0")
+		http.Error(w, http.StatusText(status), status)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// fail makes the next n requests get status, after running if after is set.
+func (ts *testServer) fail(n, status int, after bool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.failures, ts.failStatus, ts.failAfter, ts.requests = n, status, after, 0
+}
+
+func (ts *testServer) requestCount() int {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.requests
+}
+
+func (ts *testServer) client() *Client {
+	c := New(ts.URL)
+	c.HTTPClient = ts.Client()
+	c.Backoff = time.Millisecond
+	c.MaxBackoff = 5 * time.Millisecond
+	return c
+}
+
+func TestAllCategoriesPages(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.client()
+	ctx := context.Background()
+	for i := 1; i <= 7; i++ {
+		if _, err := c.CreateCategory(ctx, Category{Name: "Category " + strconv.Itoa(i)}); err != nil {
+			t.Fatalf("CreateCategory: %v", err)
+		}
+	}
+
+	ts.fail(0, 0, false)
+	seen := make(map[int]bool)
+	for cat, err := range c.AllCategories(ctx, ListParams{Limit: 3}) {
+		if err != nil {
+			t.Fatalf("AllCategories: %v", err)
+		}
+		if seen[cat.ID] {
+			t.Errorf("category %d listed twice", cat.ID)
+		}
+		seen[cat.ID] = true
+	}
+	if len(seen) != 7 {
+		t.Errorf("listed %d categories, want 7", len(seen))
+	}
+	// Pages of 3, 3 and 1; the short page ends the listing.
+	if n := ts.requestCount(); n != 3 {
+		t.Errorf("fetched %d pages, want 3", n)
+	}
+}
+
+func TestErrorsUnwrap(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.client()
+	ctx := context.Background()
+
+	_, err := c.GetProduct(ctx, 404)
+	var apiErr *APIError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetProduct of a missing product = %v, want a 404 APIError wrapping ErrNotFound", err)
+	}
+	if _, err := c.CreateProduct(ctx, Product{Name: "Tea", ReorderPoint: -1}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("CreateProduct with a negative reorder point = %v, want ErrInvalid", err)
+	}
+	if _, err := c.CreateProduct(ctx, Product{Name: "Tea", SKU: "TEA"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateProduct(ctx, Product{Name: "Green tea", SKU: "TEA"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("CreateProduct with a taken SKU = %v, want ErrConflict", err)
+	}
+
+	c.Tenant = "no-such-tenant"
+	if _, err := c.ListCategories(ctx, ListParams{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ListCategories for a missing tenant = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			ts := newTestServer(t)
+			c := ts.client()
+
+			ts.fail(2, status, false)
+			if _, err := c.ListCategories(context.Background(), ListParams{}); err != nil {
+				t.Fatalf("ListCategories after two %ds: %v", status, err)
+			}
+			if n := ts.requestCount(); n != 3 {
+				t.Errorf("sent %d requests, want 3", n)
+			}
+
+			ts.fail(c.MaxRetries+1, status, false)
+			_, err := c.ListCategories(context.Background(), ListParams{})
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != status {
+				t.Errorf("ListCategories after running out of retries = %v, want a %d APIError", err, status)
+			}
+		})
+	}
+}
+
+func TestRetriedPostIsAppliedOnce(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.client()
+	ctx := context.Background()
+
+	// The first response is lost after the category was created.
+	ts.fail(1, http.StatusBadGateway, true)
+	created, err := c.CreateCategory(ctx, Category{Name: "Drinks"})
+	if err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	cats, err := c.ListCategories(ctx, ListParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cats) != 1 || cats[0].ID != created.ID {
+		t.Errorf("categories = %+v, want only %d", cats, created.ID)
+	}
+}
+
+func TestIdempotencyKeyReplays(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.client()
+	ctx := WithIdempotencyKey(context.Background(), "create-tea")
+
+	first, err := c.CreateProduct(ctx, Product{Name: "Tea", SKU: "TEA"})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	again, err := c.CreateProduct(ctx, Product{Name: "Tea", SKU: "TEA"})
+	if err != nil {
+		t.Fatalf("CreateProduct again: %v", err)
+	}
+	if again.ID != first.ID || !again.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("replayed product %+v, want the first response %+v", again, first)
+	}
+
+	// A new key runs the request again, and the SKU is taken by then.
+	if _, err := c.CreateProduct(context.Background(), Product{Name: "Tea", SKU: "TEA"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("CreateProduct with a new key = %v, want ErrConflict", err)
+	}
+}
+
+func TestIdempotencyKeyWithAnotherBody(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.client()
+	ctx := WithIdempotencyKey(context.Background(), "create-tea")
+
+	if _, err := c.CreateProduct(ctx, Product{Name: "Tea", SKU: "TEA"}); err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	_, err := c.CreateProduct(ctx, Product{Name: "Coffee", SKU: "COFFEE"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("CreateProduct with the same key and another body = %v, want a 422", err)
+	}
+}
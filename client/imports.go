@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ImportParams controls an import.
+type ImportParams struct {
+	// Format is csv or xlsx; left empty, it is detected from the file.
+	Format string
+	// DryRun validates the file without applying it.
+	DryRun bool
+	// Mapping maps import fields to header names in the file.
+	Mapping map[string]string
+	// PollInterval is how often an import the server runs in the background
+	// is checked on, 500ms when zero.
+	PollInterval time.Duration
+}
+
+// ImportProducts imports a CSV or XLSX file of products and waits for the
+// result. A file with invalid rows is not applied; the result then has
+// Applied false and lists the errors, and no error is returned.
+func (c *Client) ImportProducts(ctx context.Context, r io.Reader, p ImportParams) (ImportResult, error) {
+	return c.importFile(ctx, "/api/products/import", r, p)
+}
+
+// ImportCategories imports a CSV or XLSX file of categories, as
+// ImportProducts does.
+func (c *Client) ImportCategories(ctx context.Context, r io.Reader, p ImportParams) (ImportResult, error) {
+	return c.importFile(ctx, "/api/categories/import", r, p)
+}
+
+// GetImportJob reports the progress of an import running in the background.
+func (c *Client) GetImportJob(ctx context.Context, id int) (ImportJob, error) {
+	var out ImportJob
+	err := c.do(ctx, http.MethodGet, "/api/imports/"+strconv.Itoa(id), nil, nil, &out)
+	return out, err
+}
+
+func (c *Client) importFile(ctx context.Context, path string, r io.Reader, p ImportParams) (ImportResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	q := url.Values{}
+	if p.Format != "" {
+		q.Set("format", p.Format)
+	}
+	if p.DryRun {
+		q.Set("dry_run", "true")
+	}
+	if len(p.Mapping) > 0 {
+		mapping, err := json.Marshal(p.Mapping)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		q.Set("mapping", string(mapping))
+	}
+
+	resp, err := c.request(ctx, http.MethodPost, path, q, "application/octet-stream", data)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusUnprocessableEntity:
+		// The rows were checked and some are invalid.
+		var env envelope
+		var res ImportResult
+		if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+			return ImportResult{}, fmt.Errorf("pos-api: decoding response: %w", err)
+		}
+		err := json.Unmarshal(env.Data, &res)
+		return res, err
+	case http.StatusAccepted:
+		var job ImportJob
+		if err := decode(resp, &job); err != nil {
+			return ImportResult{}, err
+		}
+		return c.waitImport(ctx, job.ID, p.PollInterval)
+	}
+	var res ImportResult
+	err = decode(resp, &res)
+	return res, err
+}
+
+// waitImport polls import job id until it finishes.
+func (c *Client) waitImport(ctx context.Context, id int, every time.Duration) (ImportResult, error) {
+	if every <= 0 {
+		every = 500 * time.Millisecond
+	}
+	for {
+		job, err := c.GetImportJob(ctx, id)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		if job.Result != nil {
+			return *job.Result, nil
+		}
+		if job.FinishedAt != nil {
+			return ImportResult{}, fmt.Errorf("pos-api: import %d failed: %s", id, job.Error)
+		}
+
+		t := time.NewTimer(every)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ImportResult{}, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// ExportProducts writes every product matching p's filters to w, in format
+// csv, jsonl or xlsx. p's Limit and Offset are ignored.
+func (c *Client) ExportProducts(ctx context.Context, p ProductListParams, format string, w io.Writer) error {
+	q := url.Values{"format": {format}}
+	if p.Query != "" {
+		q.Set("q", p.Query)
+	}
+	if p.CategoryID != 0 {
+		q.Set("category_id", strconv.Itoa(p.CategoryID))
+	}
+	return c.export(ctx, "/api/products/export", q, w)
+}
+
+// ExportCategories writes every category to w, in format csv, jsonl or xlsx.
+func (c *Client) ExportCategories(ctx context.Context, format string, w io.Writer) error {
+	return c.export(ctx, "/api/categories/export", url.Values{"format": {format}}, w)
+}
+
+func (c *Client) export(ctx context.Context, path string, q url.Values, w io.Writer) error {
+	resp, err := c.request(ctx, http.MethodGet, path, q, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return decode(resp, nil)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
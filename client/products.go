@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func (c *Client) ListProducts(ctx context.Context, p ProductListParams) ([]Product, error) {
+	q := url.Values{}
+	if p.Query != "" {
+		q.Set("q", p.Query)
+	}
+	if p.CategoryID != 0 {
+		q.Set("category_id", strconv.Itoa(p.CategoryID))
+	}
+	setPage(q, p.Limit, p.Offset)
+
+	var out page[Product]
+	if err := c.do(ctx, http.MethodGet, "/api/products", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+// AllProducts iterates over every product matching p, from p.Offset on,
+// fetching p.Limit at a time. It stops at the first error, which it yields.
+func (c *Client) AllProducts(ctx context.Context, p ProductListParams) iter.Seq2[Product, error] {
+	return paginate(p.Limit, p.Offset, func(limit, offset int) ([]Product, error) {
+		p.Limit, p.Offset = limit, offset
+		return c.ListProducts(ctx, p)
+	})
+}
+
+func (c *Client) GetProduct(ctx context.Context, id int) (Product, error) {
+	var out Product
+	err := c.do(ctx, http.MethodGet, "/api/products/"+strconv.Itoa(id), nil, nil, &out)
+	return out, err
+}
+
+// LookupProduct finds the product a barcode belongs to.
+func (c *Client) LookupProduct(ctx context.Context, barcode string) (Product, error) {
+	var out Product
+	err := c.do(ctx, http.MethodGet, "/api/products/lookup", url.Values{"barcode": {barcode}}, nil, &out)
+	return out, err
+}
+
+func (c *Client) CreateProduct(ctx context.Context, p Product) (Product, error) {
+	var out Product
+	err := c.do(ctx, http.MethodPost, "/api/products", nil, p, &out)
+	return out, err
+}
+
+// UpdateProduct replaces product id with p.
+func (c *Client) UpdateProduct(ctx context.Context, id int, p Product) (Product, error) {
+	var out Product
+	err := c.do(ctx, http.MethodPut, "/api/products/"+strconv.Itoa(id), nil, p, &out)
+	return out, err
+}
+
+func (c *Client) DeleteProduct(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/api/products/"+strconv.Itoa(id), nil, nil, nil)
+}
+
+func setPage(q url.Values, limit, offset int) {
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		q.Set("offset", strconv.Itoa(offset))
+	}
+}
+
+// paginate yields the items of successive pages until a short page.
+func paginate[T any](limit, offset int, list func(limit, offset int) ([]T, error)) iter.Seq2[T, error] {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	return func(yield func(T, error) bool) {
+		for {
+			items, err := list(limit, offset)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(items) < limit {
+				return
+			}
+			offset += len(items)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package client
+
+import "pos-api/internal/domain"
+
+// The API's own types, as they travel in JSON.
+type (
+	Product        = domain.Product
+	ProductOption  = domain.ProductOption
+	ProductVariant = domain.ProductVariant
+	Category       = domain.Category
+	ImportResult   = domain.ImportResult
+	ImportRowError = domain.ImportRowError
+	ImportJob      = domain.ImportJob
+	APIKey         = domain.APIKey
+	NewAPIKey      = domain.NewAPIKey
+)
+
+// ListParams pages through a list. A zero Limit takes the server's default
+// of 50; it is at most 200.
+type ListParams struct {
+	Limit  int
+	Offset int
+}
+
+// ProductListParams narrows the product list as GET /api/products does.
+type ProductListParams struct {
+	// Query matches part of a product's name or SKU.
+	Query      string
+	CategoryID int
+	Limit      int
+	Offset     int
+}
+
+type page[T any] struct {
+	Items  []T `json:"items"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"pos-api/client"
+)
+
+// createAPIKey issues an API key. With -direct it runs as the operator of
+// the deployment, so it can issue the first key with the tenants scopes.
+func createAPIKey(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("api-keys create", "-name NAME -scopes LIST [-expires TIME]")
+	name := fs.String("name", "", "name of the program the key is for")
+	scopes := fs.String("scopes", "", "comma-separated scopes, such as products:read,categories:write")
+	expires := fs.String("expires", "", "expiry time in RFC 3339, such as 2027-01-01T00:00:00Z (default never)")
+	if _, err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	k := client.APIKey{Name: *name}
+	for _, s := range strings.Split(*scopes, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			k.Scopes = append(k.Scopes, s)
+		}
+	}
+	if *expires != "" {
+		t, err := time.Parse(time.RFC3339, *expires)
+		if err != nil {
+			return fmt.Errorf("-expires: %w", err)
+		}
+		k.ExpiresAt = &t
+	}
+
+	created, err := a.client.CreateAPIKey(ctx, k)
+	if err != nil {
+		return err
+	}
+	return a.out.print(created, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "ID\tNAME\tSCOPES\tKEY")
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", created.ID, created.Name, strings.Join(created.Scopes, ","), created.Key)
+	})
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"text/tabwriter"
+
+	"pos-api/client"
+)
+
+func listCategories(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("categories list", "[flags]")
+	limit := fs.Int("limit", 50, "categories per page, at most 200")
+	offset := fs.Int("offset", 0, "categories to skip")
+	all := fs.Bool("all", false, "list every category from the offset on, not one page")
+	if _, err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	p := client.ListParams{Limit: *limit, Offset: *offset}
+	var items []client.Category
+	if *all {
+		for item, err := range a.client.AllCategories(ctx, p) {
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+	} else {
+		var err error
+		if items, err = a.client.ListCategories(ctx, p); err != nil {
+			return err
+		}
+	}
+	if items == nil {
+		items = []client.Category{}
+	}
+	return a.out.print(items, func(tw *tabwriter.Writer) {
+		categoryHeader(tw)
+		for _, item := range items {
+			categoryRow(tw, item)
+		}
+	})
+}
+
+func getCategory(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("categories get", "ID")
+	pos, err := parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(pos[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client.GetCategory(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printCategory(a, c)
+}
+
+func createCategory(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("categories create", "[flags]")
+	set := categoryFlags(fs)
+	if _, err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	var c client.Category
+	if err := set(a, &c); err != nil {
+		return err
+	}
+	created, err := a.client.CreateCategory(ctx, c)
+	if err != nil {
+		return err
+	}
+	return printCategory(a, created)
+}
+
+// updateCategory changes the fields given in the file and flags, keeping the
+// rest as they are.
+func updateCategory(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("categories update", "ID [flags]")
+	set := categoryFlags(fs)
+	pos, err := parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(pos[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client.GetCategory(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := set(a, &c); err != nil {
+		return err
+	}
+	updated, err := a.client.UpdateCategory(ctx, id, c)
+	if err != nil {
+		return err
+	}
+	return printCategory(a, updated)
+}
+
+func deleteCategories(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("categories delete", "ID...")
+	pos, err := parse(fs, args, 1, -1)
+	if err != nil {
+		return err
+	}
+	return deleteEach(a, "category", pos, func(id int) error {
+		return a.client.DeleteCategory(ctx, id)
+	})
+}
+
+// categoryFlags is productFlags for categories.
+func categoryFlags(fs *flag.FlagSet) func(a *app, c *client.Category) error {
+	file := fs.String("f", "", "JSON or YAML file with the category's fields, - for stdin")
+	name := fs.String("name", "", "name")
+	description := fs.String("description", "", "description")
+
+	return func(a *app, c *client.Category) error {
+		if *file != "" {
+			if err := readInput(a, *file, c); err != nil {
+				return err
+			}
+		}
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "name":
+				c.Name = *name
+			case "description":
+				c.Description = *description
+			}
+		})
+		return nil
+	}
+}
+
+func printCategory(a *app, c client.Category) error {
+	return a.out.print(c, func(tw *tabwriter.Writer) {
+		categoryHeader(tw)
+		categoryRow(tw, c)
+	})
+}
+
+func categoryHeader(tw *tabwriter.Writer) {
+	fmt.Fprintln(tw, "ID\tNAME\tDESCRIPTION")
+}
+
+func categoryRow(tw *tabwriter.Writer, c client.Category) {
+	fmt.Fprintf(tw, "%d\t%s\t%s\n", c.ID, c.Name, c.Description)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"pos-api/client"
+	"pos-api/database"
+	"pos-api/internal/auth"
+	"pos-api/internal/config"
+	"pos-api/internal/domain"
+	"pos-api/internal/http/handler"
+	"pos-api/internal/http/httputil"
+	"pos-api/internal/http/routes"
+	"pos-api/internal/repository_postgres"
+	"pos-api/internal/service"
+	"pos-api/internal/tenant"
+)
+
+// directClient returns a client served in this process by the server's own
+// product, category, import and API key handlers, over the database
+// config.Load names. Changes made this way are validated, audited and
+// published just as they are through the API, for the tenant the client
+// names or else the configured default; the running server's relay delivers
+// their events. Whoever can reach the database runs the deployment, so
+// requests are made as its operator, with every scope.
+func directClient() (*client.Client, func() error, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading config: %w", err)
+	}
+	db, err := database.InitDB(cfg.DatabaseURL, cfg.AllowRLSBypass)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connecting to the database: %w", err)
+	}
+
+	tenantService := service.NewTenantService(repository_postgres.NewTenantRepo(db), domain.Settings{
+		TaxRate:         cfg.TaxRate,
+		Location:        cfg.Location,
+		ValuationMethod: cfg.ValuationMethod,
+	})
+	categoryRepo := repository_postgres.NewCategoryRepo(db)
+	productRepo := repository_postgres.NewProductRepo(db)
+	mux := http.NewServeMux()
+	routes.Register(mux, routes.Handlers{
+		Category: handler.NewCategoryHandler(service.NewCategoryService(categoryRepo)),
+		Product:  handler.NewProductHandler(service.NewProductService(productRepo, categoryRepo)),
+		Import:   handler.NewImportHandler(service.NewImportService(productRepo, categoryRepo)),
+		APIKey:   handler.NewAPIKeyHandler(service.NewAPIKeyService(repository_postgres.NewAPIKeyRepo(db))),
+	})
+
+	ln := newPipeListener()
+	srv := &http.Server{Handler: httputil.WithActor(asOperator(httputil.WithTenant(mux, tenantService.Resolve, cfg.DefaultTenant)))}
+	go srv.Serve(ln)
+
+	c := client.New("http://posctl.local")
+	c.HTTPClient = &http.Client{Transport: &http.Transport{DialContext: ln.DialContext}}
+	c.MaxRetries = 0
+	return c, func() error { return errors.Join(srv.Close(), db.Close()) }, nil
+}
+
+// asOperator authenticates every request with a key that has every scope.
+func asOperator(next http.Handler) http.Handler {
+	key := domain.APIKey{Name: "posctl", TenantID: tenant.Default}
+	for _, resource := range domain.APIKeyResources {
+		key.Scopes = append(key.Scopes, resource+":write")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(auth.With(r.Context(), key)))
+	})
+}
+
+// pipeListener hands the server connections made in memory, so direct mode
+// listens on no port.
+type pipeListener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{conns: make(chan net.Conn), closed: make(chan struct{})}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+// DialContext connects to the server behind l.
+func (l *pipeListener) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, server := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "posctl" }
@@ -0,0 +1,208 @@
+// Command posctl manages the catalog from the command line: products,
+// categories, imports and exports, and the API keys that call the API. It
+// talks to the API over HTTP or, with -direct, to the database the server
+// uses.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"pos-api/client"
+)
+
+const usage = `Usage: posctl [flags] <command> [args]
+
+Commands:
+  products list [-q TEXT] [-category ID] [-limit N] [-offset N] [-all]
+  products get ID
+  products lookup BARCODE
+  products create [-f FILE] [-name NAME] [-sku SKU] [-price N] ...
+  products update ID [-f FILE] [-name NAME] [-sku SKU] [-price N] ...
+  products delete ID...
+  categories list [-limit N] [-offset N] [-all]
+  categories get ID
+  categories create [-f FILE] [-name NAME] [-description TEXT]
+  categories update ID [-f FILE] [-name NAME] [-description TEXT]
+  categories delete ID...
+  import products|categories FILE [-format csv|xlsx] [-dry-run] [-mapping JSON]
+  export products|categories [-format csv|jsonl|xlsx] [-file PATH]
+  api-keys create -name NAME -scopes LIST [-expires TIME]
+
+Run "posctl <command> <subcommand> -h" for the flags of a subcommand.
+
+Flags:
+`
+
+// app is what a subcommand works with.
+type app struct {
+	client *client.Client
+	out    *printer
+	stdin  io.Reader
+}
+
+// A subcommand runs with the arguments after its name.
+type subcommand func(ctx context.Context, a *app, args []string) error
+
+var commands = map[string]map[string]subcommand{
+	"products": {
+		"list":   listProducts,
+		"get":    getProduct,
+		"lookup": lookupProduct,
+		"create": createProduct,
+		"update": updateProduct,
+		"delete": deleteProducts,
+	},
+	"categories": {
+		"list":   listCategories,
+		"get":    getCategory,
+		"create": createCategory,
+		"update": updateCategory,
+		"delete": deleteCategories,
+	},
+	"import": {
+		"products":   importProducts,
+		"categories": importCategories,
+	},
+	"export": {
+		"products":   exportProducts,
+		"categories": exportCategories,
+	},
+	"api-keys": {
+		"create": createAPIKey,
+	},
+}
+
+// errUsage reports arguments that do not make a valid command line.
+var errUsage = errors.New("usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("posctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	profileName := fs.String("profile", "", "profile from the config file (default $POSCTL_PROFILE, then the file's current profile)")
+	url := fs.String("url", "", "API address, overriding the profile")
+	direct := fs.Bool("direct", false, "use the database configured as for the server instead of the API")
+	output := fs.String("o", "table", "output format: table, json or yaml")
+	actorName := fs.String("actor", "", "name recorded in the audit log, overriding the profile")
+	reason := fs.String("reason", "", "reason recorded in the audit log")
+	tenantRef := fs.String("tenant", "", "tenant to work for, by ID or slug, overriding the profile")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	rest := fs.Args()
+	if len(rest) < 2 {
+		fs.Usage()
+		return 2
+	}
+	cmd, ok := commands[rest[0]][rest[1]]
+	if !ok {
+		if subs, ok := commands[rest[0]]; ok {
+			fmt.Fprintf(stderr, "posctl: unknown %s command %q; want one of %s\n", rest[0], rest[1], strings.Join(names(subs), ", "))
+		} else {
+			fmt.Fprintf(stderr, "posctl: unknown command %q\n", rest[0])
+		}
+		return 2
+	}
+
+	out, err := newPrinter(*output, stdout)
+	if err != nil {
+		fmt.Fprintln(stderr, "posctl:", err)
+		return 2
+	}
+	p, err := loadProfile(*profileName)
+	if err != nil {
+		fmt.Fprintln(stderr, "posctl:", err)
+		return 1
+	}
+	if *url != "" {
+		p.URL, p.Direct = *url, false
+	}
+	if *direct {
+		p.Direct = true
+	}
+	if *actorName != "" {
+		p.Actor = *actorName
+	}
+	if *tenantRef != "" {
+		p.Tenant = *tenantRef
+	}
+
+	c, closeFn, err := connect(p)
+	if err != nil {
+		fmt.Fprintln(stderr, "posctl:", err)
+		return 1
+	}
+	defer closeFn()
+	c.Reason = *reason
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err = cmd(ctx, &app{client: c, out: out, stdin: stdin}, rest[2:])
+	switch {
+	case errors.Is(err, flag.ErrHelp), errors.Is(err, errUsage):
+		return 2
+	case err != nil:
+		fmt.Fprintln(stderr, "posctl:", err)
+		return 1
+	}
+	return 0
+}
+
+func names(m map[string]subcommand) []string {
+	out := make([]string, 0, len(m))
+	for name := range m {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// newFlags returns the flag set of a subcommand, printing its errors and
+// usage to stderr.
+func newFlags(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: posctl %s %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses args, which may mix flags and positional arguments, and
+// checks that between min and max positional arguments are left; a negative
+// max means no limit.
+func parse(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) < min || (max >= 0 && len(positional) > max) {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return positional, nil
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"pos-api/client"
+)
+
+func listProducts(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("products list", "[flags]")
+	query := fs.String("q", "", "only products whose name or SKU contains this")
+	category := fs.Int("category", 0, "only products in this category")
+	limit := fs.Int("limit", 50, "products per page, at most 200")
+	offset := fs.Int("offset", 0, "products to skip")
+	all := fs.Bool("all", false, "list every product from the offset on, not one page")
+	if _, err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	p := client.ProductListParams{Query: *query, CategoryID: *category, Limit: *limit, Offset: *offset}
+	var items []client.Product
+	if *all {
+		for item, err := range a.client.AllProducts(ctx, p) {
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+	} else {
+		var err error
+		if items, err = a.client.ListProducts(ctx, p); err != nil {
+			return err
+		}
+	}
+	if items == nil {
+		items = []client.Product{}
+	}
+	return a.out.print(items, func(tw *tabwriter.Writer) {
+		productHeader(tw)
+		for _, item := range items {
+			productRow(tw, item)
+		}
+	})
+}
+
+func getProduct(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("products get", "ID")
+	pos, err := parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(pos[0])
+	if err != nil {
+		return err
+	}
+
+	p, err := a.client.GetProduct(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printProduct(a, p)
+}
+
+func lookupProduct(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("products lookup", "BARCODE")
+	pos, err := parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	p, err := a.client.LookupProduct(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	return printProduct(a, p)
+}
+
+func createProduct(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("products create", "[flags]")
+	set := productFlags(fs)
+	if _, err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	var p client.Product
+	if err := set(a, &p); err != nil {
+		return err
+	}
+	created, err := a.client.CreateProduct(ctx, p)
+	if err != nil {
+		return err
+	}
+	return printProduct(a, created)
+}
+
+// updateProduct changes the fields given in the file and flags, keeping the
+// rest as they are.
+func updateProduct(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("products update", "ID [flags]")
+	set := productFlags(fs)
+	pos, err := parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(pos[0])
+	if err != nil {
+		return err
+	}
+
+	p, err := a.client.GetProduct(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := set(a, &p); err != nil {
+		return err
+	}
+	updated, err := a.client.UpdateProduct(ctx, id, p)
+	if err != nil {
+		return err
+	}
+	return printProduct(a, updated)
+}
+
+func deleteProducts(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("products delete", "ID...")
+	pos, err := parse(fs, args, 1, -1)
+	if err != nil {
+		return err
+	}
+	return deleteEach(a, "product", pos, func(id int) error {
+		return a.client.DeleteProduct(ctx, id)
+	})
+}
+
+// productFlags defines the flags that set product fields and returns a
+// function applying the file, then the flags that were given, to a product.
+func productFlags(fs *flag.FlagSet) func(a *app, p *client.Product) error {
+	file := fs.String("f", "", "JSON or YAML file with the product's fields, - for stdin")
+	name := fs.String("name", "", "name")
+	sku := fs.String("sku", "", "SKU")
+	barcodes := fs.String("barcodes", "", "comma-separated barcodes")
+	category := fs.Int("category", 0, "category ID, 0 for none")
+	price := fs.Int("price", 0, "price")
+	quantity := fs.Int("quantity", 0, "quantity on hand")
+	reorderPoint := fs.Int("reorder-point", 0, "quantity at or below which the product is low on stock")
+	reorderQuantity := fs.Int("reorder-quantity", 0, "usual quantity to restock")
+
+	return func(a *app, p *client.Product) error {
+		if *file != "" {
+			if err := readInput(a, *file, p); err != nil {
+				return err
+			}
+		}
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "name":
+				p.Name = *name
+			case "sku":
+				p.SKU = *sku
+			case "barcodes":
+				p.Barcodes = splitList(*barcodes)
+			case "category":
+				p.CategoryID = *category
+			case "price":
+				p.Price = *price
+			case "quantity":
+				p.Quantity = *quantity
+			case "reorder-point":
+				p.ReorderPoint = *reorderPoint
+			case "reorder-quantity":
+				p.ReorderQuantity = *reorderQuantity
+			}
+		})
+		return nil
+	}
+}
+
+func printProduct(a *app, p client.Product) error {
+	return a.out.print(p, func(tw *tabwriter.Writer) {
+		productHeader(tw)
+		productRow(tw, p)
+	})
+}
+
+func productHeader(tw *tabwriter.Writer) {
+	fmt.Fprintln(tw, "ID\tNAME\tSKU\tCATEGORY\tPRICE\tQUANTITY\tBARCODES")
+}
+
+func productRow(tw *tabwriter.Writer, p client.Product) {
+	category := "-"
+	if p.CategoryID != 0 {
+		category = strconv.Itoa(p.CategoryID)
+	}
+	fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%d\t%s\n", p.ID, p.Name, p.SKU, category, p.Price, p.Quantity, strings.Join(p.Barcodes, ","))
+}
+
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id %q", s)
+	}
+	return id, nil
+}
+
+// readInput decodes the file at path, or stdin for -, into v.
+func readInput(a *app, path string, v any) error {
+	if path == "-" {
+		return decodeInput(a.stdin, v)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := decodeInput(f, v); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	out := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// deleteEach deletes the entities with the given IDs in turn, stopping at the
+// first that fails, and reports those deleted.
+func deleteEach(a *app, kind string, ids []string, del func(id int) error) error {
+	deleted := []int{}
+	var err error
+	for _, s := range ids {
+		var id int
+		if id, err = parseID(s); err != nil {
+			break
+		}
+		if err = del(id); err != nil {
+			err = fmt.Errorf("deleting %s %d: %w", kind, id, err)
+			break
+		}
+		deleted = append(deleted, id)
+	}
+
+	if perr := a.out.print(map[string]any{"deleted": deleted}, func(tw *tabwriter.Writer) {
+		for _, id := range deleted {
+			fmt.Fprintf(tw, "deleted %s %d\n", kind, id)
+		}
+	}); perr != nil && err == nil {
+		err = perr
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"pos-api/client"
+)
+
+// defaultURL is where the API listens when run locally.
+const defaultURL = "http://localhost:8081"
+
+// profile says which server posctl works with. Profiles are kept in a YAML
+// file, $POSCTL_CONFIG or posctl/config.yaml in the user's config directory:
+//
+//	current: local
+//	profiles:
+//	  local:
+//	    url: http://localhost:8081
+//	    actor: ops
+//	    tenant: acme
+//	    api_key: pos_0123abcd_...
+//	  shop-db:
+//	    direct: true
+//
+// A direct profile reads DATABASE_URL and the rest of the server's settings
+// as the server does, from the environment and .env. $POSCTL_API_KEY
+// overrides the profile's API key.
+type profile struct {
+	URL    string `mapstructure:"url"`
+	Direct bool   `mapstructure:"direct"`
+	Actor  string `mapstructure:"actor"`
+	Tenant string `mapstructure:"tenant"`
+	APIKey string `mapstructure:"api_key"`
+}
+
+// loadProfile returns the named profile, or the current one when name is
+// empty. Without a config file, or with no profile chosen, it is the local
+// API.
+func loadProfile(name string) (profile, error) {
+	v := viper.New()
+	path := os.Getenv("POSCTL_CONFIG")
+	explicit := path != ""
+	if !explicit {
+		if dir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(dir, "posctl", "config.yaml")
+		}
+	}
+	if path != "" {
+		v.SetConfigFile(path)
+		v.SetConfigType("yaml")
+		if err := v.ReadInConfig(); err != nil && (explicit || !errors.Is(err, fs.ErrNotExist)) {
+			return profile{}, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+
+	if name == "" {
+		name = os.Getenv("POSCTL_PROFILE")
+	}
+	if name == "" {
+		name = v.GetString("current")
+	}
+	if name == "" {
+		return profile{URL: defaultURL, APIKey: os.Getenv("POSCTL_API_KEY")}, nil
+	}
+
+	// Viper folds keys to lower case, so profile names are matched
+	// regardless of case.
+	key := "profiles." + strings.ToLower(name)
+	if !v.IsSet(key) {
+		return profile{}, fmt.Errorf("no profile %q in %s", name, path)
+	}
+	var p profile
+	if err := v.UnmarshalKey(key, &p); err != nil {
+		return profile{}, fmt.Errorf("profile %q: %w", name, err)
+	}
+	if p.URL == "" && !p.Direct {
+		p.URL = defaultURL
+	}
+	if key := os.Getenv("POSCTL_API_KEY"); key != "" {
+		p.APIKey = key
+	}
+	return p, nil
+}
+
+// connect returns a client for p and a function that releases what it holds.
+func connect(p profile) (*client.Client, func() error, error) {
+	var c *client.Client
+	closeFn := func() error { return nil }
+	if p.Direct {
+		var err error
+		if c, closeFn, err = directClient(); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		c = client.New(p.URL)
+	}
+	c.Actor = p.Actor
+	c.Tenant = p.Tenant
+	c.APIKey = p.APIKey
+	return c, closeFn, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"pos-api/client"
+)
+
+func importProducts(ctx context.Context, a *app, args []string) error {
+	return importFile(ctx, a, "products", args, a.client.ImportProducts)
+}
+
+func importCategories(ctx context.Context, a *app, args []string) error {
+	return importFile(ctx, a, "categories", args, a.client.ImportCategories)
+}
+
+// importFile imports the file named in args and prints the result. Invalid
+// rows are listed and make it fail, as nothing is imported then.
+func importFile(ctx context.Context, a *app, kind string, args []string, run func(context.Context, io.Reader, client.ImportParams) (client.ImportResult, error)) error {
+	fs := newFlags("import "+kind, "FILE [flags]")
+	format := fs.String("format", "", "csv or xlsx (default: from the file name or content)")
+	dryRun := fs.Bool("dry-run", false, "check the file without importing it")
+	mapping := fs.String("mapping", "", `JSON object mapping import fields to the file's column names, e.g. {"price":"Unit Price"}`)
+	pos, err := parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	p := client.ImportParams{Format: *format, DryRun: *dryRun}
+	if *mapping != "" {
+		if err := json.Unmarshal([]byte(*mapping), &p.Mapping); err != nil {
+			return fmt.Errorf("invalid mapping: %w", err)
+		}
+	}
+	f, err := os.Open(pos[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if p.Format == "" {
+		// The server cannot see the file name.
+		switch ext := fileExt(pos[0]); ext {
+		case "csv", "xlsx":
+			p.Format = ext
+		}
+	}
+
+	res, err := run(ctx, f, p)
+	if err != nil {
+		return err
+	}
+	if err := a.out.print(res, func(tw *tabwriter.Writer) {
+		verb := "imported"
+		if res.DryRun {
+			verb = "checked"
+		}
+		fmt.Fprintf(tw, "%s %d rows: %d created, %d updated, %d failed\n", verb, res.Total, res.Created, res.Updated, res.Failed)
+		if len(res.Errors) > 0 {
+			fmt.Fprintln(tw, "ROW\tCOLUMN\tERROR")
+			for _, e := range res.Errors {
+				fmt.Fprintf(tw, "%d\t%s\t%s\n", e.Row, e.Column, e.Message)
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	if res.Failed > 0 && !res.DryRun {
+		return fmt.Errorf("%d invalid rows; nothing was imported", res.Failed)
+	}
+	return nil
+}
+
+func exportProducts(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("export products", "[flags]")
+	format, file := exportFlags(fs)
+	query := fs.String("q", "", "only products whose name or SKU contains this")
+	category := fs.Int("category", 0, "only products in this category")
+	if _, err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	return writeExport(a, *file, func(w io.Writer) error {
+		return a.client.ExportProducts(ctx, client.ProductListParams{Query: *query, CategoryID: *category}, *format, w)
+	})
+}
+
+func exportCategories(ctx context.Context, a *app, args []string) error {
+	fs := newFlags("export categories", "[flags]")
+	format, file := exportFlags(fs)
+	if _, err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	return writeExport(a, *file, func(w io.Writer) error {
+		return a.client.ExportCategories(ctx, *format, w)
+	})
+}
+
+func exportFlags(fs *flag.FlagSet) (format, file *string) {
+	format = fs.String("format", "csv", "csv, jsonl or xlsx")
+	file = fs.String("file", "", "file to write (default stdout)")
+	return format, file
+}
+
+// writeExport runs export into the file at path, or stdout when path is
+// empty. A failed export leaves no file behind.
+func writeExport(a *app, path string, export func(w io.Writer) error) error {
+	if path == "" {
+		return export(a.out.w)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = export(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+func fileExt(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	return strings.TrimPrefix(ext, ".")
+}
@@ -2,18 +2,26 @@ package database
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
-func InitDB(connectionString string) (*sql.DB, error) {
-	// Open database
-	db, err := sql.Open("pgx", connectionString)
+// InitDB connects to the database. Unless allowRLSBypass is set, it fails
+// when row-level security would not keep tenants apart.
+func InitDB(connectionString string, allowRLSBypass bool) (*sql.DB, error) {
+	config, err := pgx.ParseConfig(connectionString)
 	if err != nil {
 		return nil, err
 	}
 
+	// Open database; each statement runs as the tenant of its context
+	db := sql.OpenDB(tenantConnector{stdlib.GetConnector(*config)})
+
 	// Test connection
 	err = db.Ping()
 	if err != nil {
@@ -24,6 +32,63 @@ func InitDB(connectionString string) (*sql.DB, error) {
 	db.SetMaxOpenConns(25)
 	db.SetMaxIdleConns(5)
 
+	// Tenants are kept apart by the row-level security policies of
+	// migration 015, which superusers and roles with BYPASSRLS skip, so the
+	// API refuses to serve through such a role unless allowRLSBypass is set.
+	if err := checkRLS(db); errors.Is(err, errRLSNotEnforced) && allowRLSBypass {
+		log.Printf("WARNING: %v; tenants are not isolated from each other", err)
+	} else if err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	log.Println("Database connected successfully")
 	return db, nil
 }
+
+// errRLSNotEnforced is returned by checkRLS when the policies would not hold.
+var errRLSNotEnforced = errors.New("row-level security is not enforced")
+
+// checkRLS returns errRLSNotEnforced unless row-level security holds the
+// current role: the role may not bypass it, and every table with a tenant_id
+// but api_keys, which the queries filter themselves, must have the
+// tenant_isolation policy, forced on its owner too.
+func checkRLS(db *sql.DB) error {
+	var bypass bool
+	err := db.QueryRow(`SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`).Scan(&bypass)
+	if err != nil {
+		return err
+	}
+	if bypass {
+		return fmt.Errorf("%w: the database role is a superuser or has BYPASSRLS", errRLSNotEnforced)
+	}
+
+	rows, err := db.Query(`
+		SELECT c.relname
+		FROM pg_class c
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = 'tenant_id' AND NOT a.attisdropped
+		WHERE c.relkind = 'r' AND pg_table_is_visible(c.oid) AND c.relname <> 'api_keys'
+			AND NOT (c.relrowsecurity AND c.relforcerowsecurity
+				AND EXISTS (SELECT 1 FROM pg_policy p WHERE p.polrelid = c.oid AND p.polname = 'tenant_isolation'))
+		ORDER BY c.relname
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var unprotected []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		unprotected = append(unprotected, name)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(unprotected) > 0 {
+		return fmt.Errorf("%w on %s; run the migrations", errRLSNotEnforced, strings.Join(unprotected, ", "))
+	}
+	return nil
+}
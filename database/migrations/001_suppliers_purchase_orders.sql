@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS suppliers (
+    id           SERIAL PRIMARY KEY,
+    name         TEXT NOT NULL,
+    contact_name TEXT NOT NULL DEFAULT '',
+    phone        TEXT NOT NULL DEFAULT '',
+    email        TEXT NOT NULL DEFAULT '',
+    address      TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id           SERIAL PRIMARY KEY,
+    supplier_id  INTEGER NOT NULL REFERENCES suppliers (id),
+    status       TEXT NOT NULL DEFAULT 'draft'
+                 CHECK (status IN ('draft', 'submitted', 'partially_received', 'received', 'cancelled')),
+    notes        TEXT NOT NULL DEFAULT '',
+    submitted_at TIMESTAMPTZ,
+    received_at  TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS purchase_order_lines (
+    id                SERIAL PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    product_id        INTEGER NOT NULL REFERENCES products (id),
+    quantity          INTEGER NOT NULL CHECK (quantity > 0),
+    received_quantity INTEGER NOT NULL DEFAULT 0 CHECK (received_quantity >= 0 AND received_quantity <= quantity),
+    unit_cost         INTEGER NOT NULL CHECK (unit_cost >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS purchase_order_lines_purchase_order_id_idx ON purchase_order_lines (purchase_order_id);
+CREATE INDEX IF NOT EXISTS purchase_order_lines_product_id_idx ON purchase_order_lines (product_id);
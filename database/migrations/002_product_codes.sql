@@ -0,0 +1,10 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS products_sku_key ON products (sku);
+
+-- The primary key doubles as the scanner lookup index.
+CREATE TABLE IF NOT EXISTS product_barcodes (
+    barcode    TEXT PRIMARY KEY CHECK (barcode ~ '^[0-9]{12,13}$'),
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS product_barcodes_product_id_idx ON product_barcodes (product_id);
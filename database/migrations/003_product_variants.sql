@@ -0,0 +1,15 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS options JSONB NOT NULL DEFAULT '[]';
+
+CREATE TABLE IF NOT EXISTS product_variants (
+    id         SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku        TEXT UNIQUE,
+    options    JSONB NOT NULL,
+    price      INTEGER CHECK (price >= 0),
+    quantity   INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS product_variants_product_id_idx ON product_variants (product_id);
+
+ALTER TABLE purchase_order_lines
+    ADD COLUMN IF NOT EXISTS variant_id INTEGER REFERENCES product_variants (id);
@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS locations (
+    id         SERIAL PRIMARY KEY,
+    name       TEXT NOT NULL,
+    address    TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- variant_id is 0 for products without variants so it can be part of the key.
+CREATE TABLE IF NOT EXISTS stock_levels (
+    location_id INTEGER NOT NULL REFERENCES locations (id) ON DELETE CASCADE,
+    product_id  INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    variant_id  INTEGER NOT NULL DEFAULT 0,
+    quantity    INTEGER NOT NULL DEFAULT 0,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (location_id, product_id, variant_id)
+);
+
+CREATE INDEX IF NOT EXISTS stock_levels_product_id_idx ON stock_levels (product_id);
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id               SERIAL PRIMARY KEY,
+    from_location_id INTEGER NOT NULL REFERENCES locations (id),
+    to_location_id   INTEGER NOT NULL REFERENCES locations (id),
+    status           TEXT NOT NULL DEFAULT 'in_transit'
+                     CHECK (status IN ('in_transit', 'received', 'cancelled')),
+    notes            TEXT NOT NULL DEFAULT '',
+    received_at      TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (from_location_id <> to_location_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_lines (
+    id                SERIAL PRIMARY KEY,
+    stock_transfer_id INTEGER NOT NULL REFERENCES stock_transfers (id) ON DELETE CASCADE,
+    product_id        INTEGER NOT NULL REFERENCES products (id),
+    variant_id        INTEGER NOT NULL DEFAULT 0,
+    quantity          INTEGER NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS stock_transfer_lines_stock_transfer_id_idx ON stock_transfer_lines (stock_transfer_id);
+
+ALTER TABLE purchase_orders
+    ADD COLUMN IF NOT EXISTS location_id INTEGER REFERENCES locations (id);
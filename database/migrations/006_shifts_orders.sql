@@ -0,0 +1,66 @@
+CREATE TABLE IF NOT EXISTS shifts (
+    id            SERIAL PRIMARY KEY,
+    register_id   TEXT NOT NULL,
+    user_id       TEXT NOT NULL,
+    status        TEXT NOT NULL DEFAULT 'open'
+                  CHECK (status IN ('open', 'closed')),
+    opening_float INTEGER NOT NULL DEFAULT 0 CHECK (opening_float >= 0),
+    expected_cash INTEGER,
+    counted_cash  INTEGER,
+    variance      INTEGER,
+    opened_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at     TIMESTAMPTZ
+);
+
+-- A register can only have one open shift at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS shifts_open_register_idx ON shifts (register_id) WHERE status = 'open';
+
+CREATE TABLE IF NOT EXISTS cash_movements (
+    id         SERIAL PRIMARY KEY,
+    shift_id   INTEGER NOT NULL REFERENCES shifts (id) ON DELETE CASCADE,
+    kind       TEXT NOT NULL CHECK (kind IN ('cash_in', 'cash_out')),
+    amount     INTEGER NOT NULL CHECK (amount > 0),
+    reason     TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS cash_movements_shift_id_idx ON cash_movements (shift_id);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id          SERIAL PRIMARY KEY,
+    shift_id    INTEGER REFERENCES shifts (id),
+    register_id TEXT NOT NULL DEFAULT '',
+    user_id     TEXT NOT NULL DEFAULT '',
+    location_id INTEGER REFERENCES locations (id),
+    subtotal    INTEGER NOT NULL,
+    tax         INTEGER NOT NULL,
+    total       INTEGER NOT NULL,
+    paid        INTEGER NOT NULL,
+    change      INTEGER NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS orders_shift_id_idx ON orders (shift_id);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id         SERIAL PRIMARY KEY,
+    order_id   INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products (id),
+    variant_id INTEGER NOT NULL DEFAULT 0,
+    name       TEXT NOT NULL,
+    sku        TEXT NOT NULL DEFAULT '',
+    quantity   INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price INTEGER NOT NULL,
+    line_total INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_items_order_id_idx ON order_items (order_id);
+
+CREATE TABLE IF NOT EXISTS order_tenders (
+    id       SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    method   TEXT NOT NULL,
+    amount   INTEGER NOT NULL CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS order_tenders_order_id_idx ON order_tenders (order_id);
@@ -0,0 +1,26 @@
+-- Running costs per product, or per variant when variant_id is set.
+CREATE TABLE IF NOT EXISTS product_costs (
+    product_id   INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    variant_id   INTEGER NOT NULL DEFAULT 0,
+    average_cost INTEGER NOT NULL DEFAULT 0,
+    last_cost    INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, variant_id)
+);
+
+CREATE TABLE IF NOT EXISTS cost_layers (
+    id          SERIAL PRIMARY KEY,
+    product_id  INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    variant_id  INTEGER NOT NULL DEFAULT 0,
+    quantity    INTEGER NOT NULL CHECK (quantity > 0),
+    remaining   INTEGER NOT NULL CHECK (remaining >= 0),
+    unit_cost   INTEGER NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS cost_layers_open_idx ON cost_layers (product_id, variant_id, received_at) WHERE remaining > 0;
+
+-- The cost of each sold line under every valuation method, fixed at sale time.
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS cost_fifo    INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cost_average INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cost_last    INTEGER NOT NULL DEFAULT 0;
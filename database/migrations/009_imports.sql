@@ -0,0 +1,3 @@
+-- Imports match products and categories by name, ignoring case.
+CREATE INDEX IF NOT EXISTS products_lower_name_idx ON products (lower(name));
+CREATE INDEX IF NOT EXISTS categories_lower_name_idx ON categories (lower(name));
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS scheduled_prices (
+    id             SERIAL PRIMARY KEY,
+    product_id     INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    price          INTEGER NOT NULL CHECK (price >= 0),
+    effective_from TIMESTAMPTZ NOT NULL,
+    reason         TEXT NOT NULL DEFAULT '',
+    actor          TEXT NOT NULL DEFAULT '',
+    status         TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'applied', 'cancelled')),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    applied_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_prices_product_id_idx ON scheduled_prices (product_id);
+CREATE INDEX IF NOT EXISTS scheduled_prices_pending_idx ON scheduled_prices (effective_from) WHERE status = 'pending';
+
+-- Every change to products.price; old_price is NULL for the initial price.
+CREATE TABLE IF NOT EXISTS price_history (
+    id                 SERIAL PRIMARY KEY,
+    product_id         INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    old_price          INTEGER,
+    new_price          INTEGER NOT NULL,
+    actor              TEXT NOT NULL DEFAULT '',
+    reason             TEXT NOT NULL DEFAULT '',
+    scheduled_price_id INTEGER REFERENCES scheduled_prices (id) ON DELETE SET NULL,
+    changed_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS price_history_product_id_idx ON price_history (product_id, changed_at DESC);
+
+-- Start the history of existing products from their current price.
+INSERT INTO price_history (product_id, old_price, new_price, reason, changed_at)
+SELECT p.id, NULL, p.price, 'price before history was kept', p.updated_at
+FROM products p
+WHERE NOT EXISTS (SELECT 1 FROM price_history h WHERE h.product_id = p.id);
@@ -0,0 +1,17 @@
+-- Domain events waiting to be published, written in the same transaction as
+-- the change they describe. Delivered events are kept with delivered_at set.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id              BIGSERIAL PRIMARY KEY,
+    type            TEXT NOT NULL,
+    entity_id       INTEGER NOT NULL,
+    data            JSONB NOT NULL,
+    actor           TEXT NOT NULL DEFAULT '',
+    request_id      TEXT NOT NULL DEFAULT '',
+    occurred_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_pending_idx ON outbox_events (next_attempt_at, id) WHERE delivered_at IS NULL;
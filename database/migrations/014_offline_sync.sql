@@ -0,0 +1,63 @@
+-- Every change to a product or category takes the next number from one
+-- sequence and records the transaction that made it. Terminals pull by
+-- transaction: once every transaction below an ID has ended, nothing can
+-- still commit below it, so pulls need not wait for writers. Triggers stamp
+-- the rows, which also covers stock movements, price changes and categories
+-- cleared by ON DELETE SET NULL.
+CREATE SEQUENCE IF NOT EXISTS catalog_change_seq;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS change_seq BIGINT NOT NULL DEFAULT nextval('catalog_change_seq');
+ALTER TABLE products ADD COLUMN IF NOT EXISTS change_xid XID8 NOT NULL DEFAULT pg_current_xact_id();
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS change_seq BIGINT NOT NULL DEFAULT nextval('catalog_change_seq');
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS change_xid XID8 NOT NULL DEFAULT pg_current_xact_id();
+
+CREATE INDEX IF NOT EXISTS products_change_xid_idx ON products (change_xid, change_seq);
+CREATE INDEX IF NOT EXISTS categories_change_xid_idx ON categories (change_xid, change_seq);
+
+-- Deleted products and categories, kept so terminals learn to drop them.
+CREATE TABLE IF NOT EXISTS catalog_tombstones (
+    change_seq  BIGINT PRIMARY KEY,
+    change_xid  XID8 NOT NULL DEFAULT pg_current_xact_id(),
+    entity_type TEXT NOT NULL,
+    entity_id   INTEGER NOT NULL,
+    deleted_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS catalog_tombstones_change_xid_idx ON catalog_tombstones (change_xid, change_seq);
+
+CREATE OR REPLACE FUNCTION stamp_catalog_change() RETURNS trigger AS $$
+BEGIN
+    NEW.change_seq := nextval('catalog_change_seq');
+    NEW.change_xid := pg_current_xact_id();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION record_catalog_tombstone() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO catalog_tombstones (change_seq, entity_type, entity_id)
+    VALUES (nextval('catalog_change_seq'), TG_ARGV[0], OLD.id);
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS products_change_seq ON products;
+CREATE TRIGGER products_change_seq BEFORE UPDATE ON products
+    FOR EACH ROW EXECUTE FUNCTION stamp_catalog_change();
+
+DROP TRIGGER IF EXISTS categories_change_seq ON categories;
+CREATE TRIGGER categories_change_seq BEFORE UPDATE ON categories
+    FOR EACH ROW EXECUTE FUNCTION stamp_catalog_change();
+
+DROP TRIGGER IF EXISTS products_tombstone ON products;
+CREATE TRIGGER products_tombstone AFTER DELETE ON products
+    FOR EACH ROW EXECUTE FUNCTION record_catalog_tombstone('product');
+
+DROP TRIGGER IF EXISTS categories_tombstone ON categories;
+CREATE TRIGGER categories_tombstone AFTER DELETE ON categories
+    FOR EACH ROW EXECUTE FUNCTION record_catalog_tombstone('category');
+
+-- Offline sales carry an ID made up by the terminal, so a sale pushed twice
+-- is only recorded once.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS client_id TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS orders_client_id_idx ON orders (client_id) WHERE client_id IS NOT NULL;
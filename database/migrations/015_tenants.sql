@@ -0,0 +1,85 @@
+-- One deployment serves many merchants. Every row belongs to a tenant, and
+-- row-level security lets a session see and write only the rows of the
+-- tenant named by the app.tenant_id setting, which the API sets from the
+-- request. The API must connect as a role that is neither a superuser nor
+-- BYPASSRLS, as those skip the policies. Foreign keys are checked without
+-- the policies, so the services look up what a row refers to, as its
+-- tenant, before writing it.
+CREATE TABLE IF NOT EXISTS tenants (
+    id               SERIAL PRIMARY KEY,
+    slug             TEXT NOT NULL UNIQUE CHECK (slug ~ '^[a-z0-9][a-z0-9-]*$'),
+    name             TEXT NOT NULL,
+    suspended        BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Settings left NULL or empty fall back to the deployment's configuration.
+    tax_rate         DOUBLE PRECISION CHECK (tax_rate BETWEEN 0 AND 100),
+    timezone         TEXT NOT NULL DEFAULT '',
+    valuation_method TEXT NOT NULL DEFAULT '' CHECK (valuation_method IN ('', 'fifo', 'average', 'last')),
+    store_name       TEXT NOT NULL DEFAULT '',
+    store_address    TEXT NOT NULL DEFAULT '',
+    store_phone      TEXT NOT NULL DEFAULT '',
+    receipt_footer   TEXT NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The existing data becomes the default tenant's.
+INSERT INTO tenants (id, slug, name) VALUES (1, 'default', 'Default') ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('tenants', 'id'), GREATEST((SELECT MAX(id) FROM tenants), 1));
+
+-- The tenant of the session, or NULL when app.tenant_id is unset or empty,
+-- which matches no rows.
+CREATE OR REPLACE FUNCTION current_tenant_id() RETURNS INTEGER AS $$
+    SELECT NULLIF(current_setting('app.tenant_id', true), '')::INTEGER
+$$ LANGUAGE sql STABLE;
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'products', 'categories', 'product_barcodes', 'product_variants',
+        'suppliers', 'purchase_orders', 'purchase_order_lines',
+        'locations', 'stock_levels', 'stock_transfers', 'stock_transfer_lines',
+        'shifts', 'cash_movements', 'orders', 'order_items', 'order_tenders',
+        'product_costs', 'cost_layers', 'scheduled_prices', 'price_history',
+        'audit_log', 'outbox_events', 'webhook_subscriptions', 'webhook_deliveries',
+        'catalog_tombstones'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS tenant_id INTEGER REFERENCES tenants (id)', t);
+        EXECUTE format('UPDATE %I SET tenant_id = 1 WHERE tenant_id IS NULL', t);
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN tenant_id SET DEFAULT current_tenant_id(), ALTER COLUMN tenant_id SET NOT NULL', t);
+        EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I (tenant_id)', t || '_tenant_id_idx', t);
+
+        -- FORCE holds the table's owner to the policy too.
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+        EXECUTE format('CREATE POLICY tenant_isolation ON %I USING (tenant_id = current_tenant_id()) WITH CHECK (tenant_id = current_tenant_id())', t);
+    END LOOP;
+END $$;
+
+-- Codes only have to be unique within a tenant: two shops may well sell the
+-- same EAN-13 under the same SKU.
+DROP INDEX IF EXISTS products_sku_key;
+CREATE UNIQUE INDEX IF NOT EXISTS products_tenant_sku_key ON products (tenant_id, sku);
+
+ALTER TABLE product_barcodes DROP CONSTRAINT IF EXISTS product_barcodes_pkey;
+ALTER TABLE product_barcodes ADD CONSTRAINT product_barcodes_pkey PRIMARY KEY (tenant_id, barcode);
+
+ALTER TABLE product_variants DROP CONSTRAINT IF EXISTS product_variants_sku_key;
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_tenant_sku_key ON product_variants (tenant_id, sku);
+
+DROP INDEX IF EXISTS shifts_open_register_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS shifts_open_register_idx ON shifts (tenant_id, register_id) WHERE status = 'open';
+
+DROP INDEX IF EXISTS orders_client_id_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS orders_client_id_idx ON orders (tenant_id, client_id) WHERE client_id IS NOT NULL;
+
+-- Tombstones belong to the tenant of the row deleted.
+CREATE OR REPLACE FUNCTION record_catalog_tombstone() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO catalog_tombstones (change_seq, entity_type, entity_id, tenant_id)
+    VALUES (nextval('catalog_change_seq'), TG_ARGV[0], OLD.id, OLD.tenant_id);
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
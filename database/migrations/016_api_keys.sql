@@ -0,0 +1,19 @@
+-- Keys are looked up by prefix before the tenant of a request is known, so
+-- this table is left out of row-level security, and the queries made for a
+-- tenant filter on tenant_id themselves.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           SERIAL PRIMARY KEY,
+    tenant_id    INTEGER NOT NULL REFERENCES tenants (id),
+    name         TEXT NOT NULL,
+    prefix       TEXT NOT NULL UNIQUE,
+    -- Hex SHA-256 of the whole key; the key itself is never stored.
+    key_hash     TEXT NOT NULL,
+    -- JSON array of scopes, such as "products:read".
+    scopes       JSONB NOT NULL DEFAULT '[]',
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_tenant_id_idx ON api_keys (tenant_id, id);
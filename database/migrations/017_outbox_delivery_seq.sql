@@ -0,0 +1,10 @@
+-- Streams resume from the order events were published in, not from their IDs:
+-- IDs are taken when a change is written, and a transaction that took a lower
+-- ID may commit, or an event be retried, after one with a higher ID was
+-- published. The relay numbers each event as it publishes it, holding an
+-- advisory lock so relays on different instances take turns.
+CREATE SEQUENCE IF NOT EXISTS outbox_delivery_seq;
+
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS delivery_seq BIGINT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS outbox_events_delivery_seq_idx ON outbox_events (delivery_seq) WHERE delivery_seq IS NOT NULL;
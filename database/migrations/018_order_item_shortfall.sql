@@ -0,0 +1,4 @@
+-- Units of a sale rung up offline that were no longer in stock when it was
+-- pushed. The sale is recorded regardless, and only the units on hand are
+-- taken out of stock.
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS shortfall INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"strconv"
+
+	"pos-api/internal/tenant"
+)
+
+// tenantSetting is the Postgres setting the row-level security policies of
+// migration 015 read the current tenant from.
+const tenantSetting = "app.tenant_id"
+
+// pgxConn is what the pgx driver's connections implement, all of which
+// database/sql looks for; CheckNamedValue, in particular, is what lets pgx
+// take slices and other values database/sql would refuse.
+type pgxConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.NamedValueChecker
+	driver.SessionResetter
+}
+
+// tenantConnector opens connections that set app.tenant_id to the tenant of
+// the context before each statement that needs it, so the policies let a
+// statement see only its tenant's rows. A statement whose context has no
+// tenant runs with the setting cleared and sees none.
+type tenantConnector struct {
+	driver.Connector
+}
+
+func (c tenantConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantConn{pgxConn: conn.(pgxConn)}, nil
+}
+
+type tenantConn struct {
+	pgxConn
+	// tenant is the ID app.tenant_id was last set to, when known is true.
+	tenant int
+	known  bool
+}
+
+// use sets app.tenant_id to the tenant of ctx, unless it already is.
+func (c *tenantConn) use(ctx context.Context) error {
+	id := tenant.From(ctx)
+	if c.known && c.tenant == id {
+		return nil
+	}
+	value := ""
+	if id != 0 {
+		value = strconv.Itoa(id)
+	}
+	c.known = false
+	_, err := c.pgxConn.ExecContext(ctx, `SELECT set_config('`+tenantSetting+`', $1, false)`, []driver.NamedValue{{Ordinal: 1, Value: value}})
+	if err != nil {
+		return err
+	}
+	c.tenant, c.known = id, true
+	return nil
+}
+
+func (c *tenantConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.use(ctx); err != nil {
+		return nil, err
+	}
+	return c.pgxConn.ExecContext(ctx, query, args)
+}
+
+func (c *tenantConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.use(ctx); err != nil {
+		return nil, err
+	}
+	return c.pgxConn.QueryContext(ctx, query, args)
+}
+
+func (c *tenantConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	s, err := c.pgxConn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantStmt{Stmt: s, conn: c}, nil
+}
+
+func (c *tenantConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *tenantConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := c.use(ctx); err != nil {
+		return nil, err
+	}
+	tx, err := c.pgxConn.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantTx{Tx: tx, conn: c}, nil
+}
+
+func (c *tenantConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// tenantTx forgets the tenant setting on rollback, since a setting changed
+// inside the transaction is rolled back with it.
+type tenantTx struct {
+	driver.Tx
+	conn *tenantConn
+}
+
+func (t *tenantTx) Rollback() error {
+	t.conn.known = false
+	return t.Tx.Rollback()
+}
+
+type tenantStmt struct {
+	driver.Stmt
+	conn *tenantConn
+}
+
+func (s *tenantStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.conn.use(ctx); err != nil {
+		return nil, err
+	}
+	return s.Stmt.(driver.StmtExecContext).ExecContext(ctx, args)
+}
+
+func (s *tenantStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.conn.use(ctx); err != nil {
+		return nil, err
+	}
+	return s.Stmt.(driver.StmtQueryContext).QueryContext(ctx, args)
+}
@@ -0,0 +1,59 @@
+// Package actor carries who is making a change, and why, in a context so
+// that the records kept about the change can name them.
+package actor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// Actor is the person or system behind a change, with where the request came
+// from. Name and Reason are free text supplied by the caller; any field may
+// be empty.
+type Actor struct {
+	Name      string
+	Reason    string
+	RequestID string
+	IP        string
+}
+
+type contextKey struct{}
+
+func With(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, contextKey{}, a)
+}
+
+// From returns the actor stored in ctx, or the zero Actor.
+func From(ctx context.Context) Actor {
+	a, _ := ctx.Value(contextKey{}).(Actor)
+	return a
+}
+
+// WithAPIKey returns a acting through the API key with the given prefix.
+// The key is what was checked, so it always names the actor; a name the
+// caller gave is kept after it as who they said they were.
+func (a Actor) WithAPIKey(prefix string) Actor {
+	name := "api-key:" + prefix
+	if a.Name != "" {
+		name += " (as " + a.Name + ")"
+	}
+	a.Name = name
+	return a
+}
+
+// maxRequestIDLength bounds a request ID supplied by the client.
+const maxRequestIDLength = 128
+
+// RequestID returns the request ID a client sent, or a new random one when
+// it sent none or one that is too long.
+func RequestID(given string) string {
+	given = strings.TrimSpace(given)
+	if given != "" && len(given) <= maxRequestIDLength {
+		return given
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,48 @@
+// Package auth carries the API key a request was made with in a context, so
+// that what the request does can be held to the key's scopes.
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"pos-api/internal/domain"
+)
+
+// Authenticator returns the API key key is, failing with ErrUnauthorized
+// when it is not a usable one.
+type Authenticator func(ctx context.Context, key string) (domain.APIKey, error)
+
+type contextKey struct{}
+
+// With returns ctx authenticated with key.
+func With(ctx context.Context, key domain.APIKey) context.Context {
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// From returns the API key of ctx, if it has one.
+func From(ctx context.Context) (domain.APIKey, bool) {
+	key, ok := ctx.Value(contextKey{}).(domain.APIKey)
+	return key, ok
+}
+
+// Require fails with ErrForbidden when ctx has an API key without scope. A
+// context without a key passes: whether one is needed at all is up to the
+// server's middleware.
+func Require(ctx context.Context, scope string) error {
+	key, ok := From(ctx)
+	if !ok || key.Allows(scope) {
+		return nil
+	}
+	return fmt.Errorf("%w: the API key does not have the %s scope", domain.ErrForbidden, scope)
+}
+
+// RequireKey is Require for what only the operator of the deployment may do:
+// a context without a key fails too, with ErrUnauthorized, whether or not
+// the server requires keys otherwise.
+func RequireKey(ctx context.Context, scope string) error {
+	if _, ok := From(ctx); !ok {
+		return fmt.Errorf("%w: an API key with the %s scope is required", domain.ErrUnauthorized, scope)
+	}
+	return Require(ctx, scope)
+}
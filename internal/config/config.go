@@ -2,13 +2,53 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"strings"
+	"time"
+	// Bundled so TIMEZONE works on hosts without a zoneinfo database.
+	_ "time/tzdata"
 
 	"github.com/spf13/viper"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/ratelimit"
 )
 
 type Config struct {
 	DatabaseURL string
+	// AllowRLSBypass lets the API start when row-level security does not
+	// hold its database role, leaving the queries' own tenant filters as the
+	// only barrier between tenants.
+	AllowRLSBypass bool
+	// TaxRate is the sales tax added to orders, in percent.
+	TaxRate float64
+	// Location is the store's time zone, used for receipts and reports.
+	Location *time.Location
+	// ValuationMethod is the default costing for inventory and COGS reports.
+	ValuationMethod domain.ValuationMethod
+	// GRPCPort is where the gRPC API listens, apart from the REST API.
+	GRPCPort int
+	// DefaultTenant is the tenant, by ID or slug, of requests that do not
+	// name one. It is empty when DEFAULT_TENANT is "none", and every request
+	// must then name its tenant.
+	DefaultTenant string
+	// RateLimits are the requests each client may make, per route.
+	RateLimits ratelimit.Rules
+	// AddressRateLimit is the requests each address may make, counted
+	// before API keys are checked.
+	AddressRateLimit ratelimit.Limit
+	// APIKeysRequired turns away requests without an API key, but for
+	// health checks and the docs.
+	APIKeysRequired bool
+	// WSAllowedOrigins are the web origins, besides the API's own, whose
+	// pages may open the event WebSocket.
+	WSAllowedOrigins []string
+
+	// Store details printed on receipts.
+	StoreName     string
+	StoreAddress  string
+	StorePhone    string
+	ReceiptFooter string
 }
 
 func Load() (Config, error) {
@@ -19,13 +59,71 @@ func Load() (Config, error) {
 
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
+	v.SetDefault("TIMEZONE", "Asia/Jakarta")
+	v.SetDefault("VALUATION_METHOD", string(domain.ValuationFIFO))
+	v.SetDefault("GRPC_PORT", 9090)
+	v.SetDefault("DEFAULT_TENANT", "default")
+	v.SetDefault("RATE_LIMIT", "20/s")
+	v.SetDefault("RATE_LIMIT_ADDRESS", "100/s")
 
 	cfg := Config{
-		DatabaseURL: v.GetString("DATABASE_URL"),
+		DatabaseURL:    v.GetString("DATABASE_URL"),
+		AllowRLSBypass: v.GetBool("ALLOW_RLS_BYPASS"),
+		TaxRate:        v.GetFloat64("TAX_RATE"),
+		GRPCPort:       v.GetInt("GRPC_PORT"),
+
+		DefaultTenant:   strings.TrimSpace(v.GetString("DEFAULT_TENANT")),
+		APIKeysRequired: v.GetBool("API_KEYS_REQUIRED"),
+
+		StoreName:     v.GetString("STORE_NAME"),
+		StoreAddress:  v.GetString("STORE_ADDRESS"),
+		StorePhone:    v.GetString("STORE_PHONE"),
+		ReceiptFooter: v.GetString("RECEIPT_FOOTER"),
 	}
 	if cfg.DatabaseURL == "" {
 		return Config{}, errors.New("DATABASE_URL is required")
 	}
+	if cfg.TaxRate < 0 || cfg.TaxRate > 100 {
+		return Config{}, errors.New("TAX_RATE must be between 0 and 100")
+	}
+	if cfg.GRPCPort < 1 || cfg.GRPCPort > 65535 {
+		return Config{}, errors.New("GRPC_PORT must be between 1 and 65535")
+	}
+
+	if strings.EqualFold(cfg.DefaultTenant, "none") {
+		cfg.DefaultTenant = ""
+	}
+
+	limit, err := ratelimit.ParseLimit(v.GetString("RATE_LIMIT"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid RATE_LIMIT: %w", err)
+	}
+	routes, err := ratelimit.ParseRoutes(v.GetString("RATE_LIMIT_ROUTES"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid RATE_LIMIT_ROUTES: %w", err)
+	}
+	cfg.RateLimits = ratelimit.Rules{Default: limit, Routes: routes}
+	if cfg.AddressRateLimit, err = ratelimit.ParseLimit(v.GetString("RATE_LIMIT_ADDRESS")); err != nil {
+		return Config{}, fmt.Errorf("invalid RATE_LIMIT_ADDRESS: %w", err)
+	}
+
+	for _, origin := range strings.Split(v.GetString("WS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			cfg.WSAllowedOrigins = append(cfg.WSAllowedOrigins, origin)
+		}
+	}
+
+	loc, err := time.LoadLocation(v.GetString("TIMEZONE"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid TIMEZONE: %w", err)
+	}
+	cfg.Location = loc
+
+	method, err := domain.ParseValuationMethod(v.GetString("VALUATION_METHOD"))
+	if err != nil {
+		return Config{}, errors.New("VALUATION_METHOD must be fifo, average or last")
+	}
+	cfg.ValuationMethod = method
 
 	return cfg, nil
 }
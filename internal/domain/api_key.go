@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKey lets a program call the API as its tenant, within its scopes. The
+// key itself is only shown when it is created; what is kept is its Prefix,
+// which identifies it at a glance, and a hash of the whole key.
+type APIKey struct {
+	ID         int        `json:"id"`
+	TenantID   int        `json:"tenant_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewAPIKey is a key just created, with the key to give to the program.
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyResources are what scopes grant access to. A scope is a resource
+// and either read or write access, as in products:read.
+var APIKeyResources = []string{
+	"products", "categories", "suppliers", "purchase-orders", "locations",
+	"inventory", "shifts", "orders", "reports", "imports", "audit", "events",
+	"webhooks", "sync", "tenants", "api-keys",
+}
+
+// ParseScope checks that s names a resource and read or write access.
+func ParseScope(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	resource, access, ok := strings.Cut(s, ":")
+	if ok && (access == "read" || access == "write") {
+		for _, r := range APIKeyResources {
+			if r == resource {
+				return s, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: unknown scope %q; want a resource and read or write, such as products:read", ErrInvalid, s)
+}
+
+// Allows reports whether the key may use scope. Write access to a resource
+// includes reading it.
+func (k APIKey) Allows(scope string) bool {
+	resource, access, _ := strings.Cut(scope, ":")
+	for _, s := range k.Scopes {
+		if s == scope || (access == "read" && s == resource+":write") {
+			return true
+		}
+	}
+	return false
+}
+
+// Usable reports why the key can no longer be used, if it cannot.
+func (k APIKey) Usable(now time.Time) error {
+	if k.RevokedAt != nil {
+		return fmt.Errorf("%w: the API key was revoked", ErrUnauthorized)
+	}
+	if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+		return fmt.Errorf("%w: the API key has expired", ErrUnauthorized)
+	}
+	return nil
+}
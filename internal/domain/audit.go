@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+)
+
+// Entity types recorded in the audit log.
+const (
+	AuditProduct  = "product"
+	AuditCategory = "category"
+)
+
+// AuditEntry records one change to an entity. Before is null for creates and
+// After for deletes; Changes lists the fields an update changed.
+type AuditEntry struct {
+	ID         int                    `json:"id"`
+	Actor      string                 `json:"actor"`
+	Action     AuditAction            `json:"action"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   int                    `json:"entity_id"`
+	Before     json.RawMessage        `json:"before"`
+	After      json.RawMessage        `json:"after"`
+	Changes    map[string]AuditChange `json:"changes,omitempty"`
+	RequestID  string                 `json:"request_id"`
+	IP         string                 `json:"ip"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+type AuditChange struct {
+	From json.RawMessage `json:"from"`
+	To   json.RawMessage `json:"to"`
+}
+
+// unaudited lists the fields that change on every update.
+var unaudited = map[string]bool{"updated_at": true, "change_seq": true}
+
+// NewAuditEntry describes a change from before to after, either of which may
+// be nil or a nil pointer. The entity's updated_at and change_seq are left
+// out of the changes, as every update changes them.
+func NewAuditEntry(action AuditAction, entityType string, entityID int, before, after any) (AuditEntry, error) {
+	e := AuditEntry{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     json.RawMessage("null"),
+		After:      json.RawMessage("null"),
+	}
+
+	var err error
+	if before != nil {
+		if e.Before, err = json.Marshal(before); err != nil {
+			return AuditEntry{}, err
+		}
+	}
+	if after != nil {
+		if e.After, err = json.Marshal(after); err != nil {
+			return AuditEntry{}, err
+		}
+	}
+	if string(e.Before) == "null" || string(e.After) == "null" {
+		return e, nil
+	}
+
+	var from, to map[string]json.RawMessage
+	if err := json.Unmarshal(e.Before, &from); err != nil {
+		return AuditEntry{}, err
+	}
+	if err := json.Unmarshal(e.After, &to); err != nil {
+		return AuditEntry{}, err
+	}
+	e.Changes = make(map[string]AuditChange)
+	for key, f := range from {
+		if t := to[key]; !unaudited[key] && !bytes.Equal(f, t) {
+			e.Changes[key] = AuditChange{From: f, To: t}
+		}
+	}
+	for key, t := range to {
+		if _, ok := from[key]; !ok {
+			e.Changes[key] = AuditChange{From: json.RawMessage("null"), To: t}
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "fmt"
+
+// ValidateBarcode accepts EAN-13 and UPC-A codes with a correct check digit.
+func ValidateBarcode(code string) error {
+	if len(code) != 12 && len(code) != 13 {
+		return fmt.Errorf("%w: barcode %q must be 12 (UPC-A) or 13 (EAN-13) digits", ErrInvalid, code)
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("%w: barcode %q must contain digits only", ErrInvalid, code)
+		}
+	}
+
+	// GTIN check digit: weights 3,1,3,... from the digit left of the check digit.
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		d := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	check := (10 - sum%10) % 10
+	if int(code[len(code)-1]-'0') != check {
+		return fmt.Errorf("%w: barcode %q has an invalid check digit", ErrInvalid, code)
+	}
+	return nil
+}
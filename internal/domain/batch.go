@@ -0,0 +1,40 @@
+package domain
+
+type BatchAction string
+
+const (
+	BatchCreate BatchAction = "create"
+	BatchUpdate BatchAction = "update"
+	BatchDelete BatchAction = "delete"
+)
+
+// ProductBatchOp is one operation of a product batch. Product is the full
+// product for create and update, as for POST and PUT; ID names the product
+// to update or delete.
+type ProductBatchOp struct {
+	Action  BatchAction `json:"action"`
+	ID      int         `json:"id,omitempty"`
+	Product Product     `json:"product"`
+}
+
+// BatchItemResult reports the outcome of one operation, by its position in
+// the request.
+type BatchItemResult struct {
+	Index   int         `json:"index"`
+	Action  BatchAction `json:"action"`
+	ID      int         `json:"id,omitempty"`
+	OK      bool        `json:"ok"`
+	Product *Product    `json:"product,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// BatchResult summarises a batch. An atomic batch is applied only when every
+// operation succeeds; otherwise Applied is false and the results show which
+// operations failed.
+type BatchResult struct {
+	Atomic    bool              `json:"atomic"`
+	Applied   bool              `json:"applied"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BatchItemResult `json:"results"`
+}
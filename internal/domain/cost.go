@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ValuationMethod decides how stock on hand and goods sold are costed.
+type ValuationMethod string
+
+const (
+	ValuationFIFO    ValuationMethod = "fifo"
+	ValuationAverage ValuationMethod = "average"
+	ValuationLast    ValuationMethod = "last"
+)
+
+func ParseValuationMethod(s string) (ValuationMethod, error) {
+	switch m := ValuationMethod(strings.ToLower(strings.TrimSpace(s))); m {
+	case ValuationFIFO, ValuationAverage, ValuationLast:
+		return m, nil
+	}
+	return "", fmt.Errorf("%w: valuation method must be fifo, average or last", ErrInvalid)
+}
+
+// CostLayer is one receipt of stock at a known unit cost. Remaining drops as
+// the stock is sold or written off, oldest layer first.
+type CostLayer struct {
+	ID         int       `json:"id"`
+	ProductID  int       `json:"product_id"`
+	VariantID  int       `json:"variant_id,omitempty"`
+	Quantity   int       `json:"quantity"`
+	Remaining  int       `json:"remaining"`
+	UnitCost   int       `json:"unit_cost"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// CostBasis holds an amount under every valuation method, so reports can
+// switch method without re-costing history.
+type CostBasis struct {
+	FIFO    int `json:"fifo"`
+	Average int `json:"average"`
+	Last    int `json:"last"`
+}
+
+func (b CostBasis) Of(m ValuationMethod) int {
+	switch m {
+	case ValuationAverage:
+		return b.Average
+	case ValuationLast:
+		return b.Last
+	}
+	return b.FIFO
+}
+
+func (b CostBasis) Add(o CostBasis) CostBasis {
+	return CostBasis{FIFO: b.FIFO + o.FIFO, Average: b.Average + o.Average, Last: b.Last + o.Last}
+}
+
+// ProductCost is the running cost of a product or one of its variants.
+type ProductCost struct {
+	ProductID   int `json:"product_id"`
+	VariantID   int `json:"variant_id,omitempty"`
+	AverageCost int `json:"average_cost"`
+	LastCost    int `json:"last_cost"`
+}
+
+// Receive takes qty units into stock on top of onHand and returns the unit
+// cost of the new layer. A unit cost of zero means unknown: the stock comes
+// in at the current average and the costs stay as they are.
+func (c *ProductCost) Receive(onHand, qty, unitCost int) int {
+	if unitCost <= 0 {
+		return c.AverageCost
+	}
+	if onHand <= 0 {
+		c.AverageCost = unitCost
+	} else {
+		total := onHand*c.AverageCost + qty*unitCost
+		c.AverageCost = (total + (onHand+qty)/2) / (onHand + qty)
+	}
+	c.LastCost = unitCost
+	return unitCost
+}
+
+// Issue costs qty units leaving stock and takes them out of layers, which
+// must be ordered oldest first. Units not covered by any layer are costed at
+// the average.
+func (c ProductCost) Issue(layers []CostLayer, qty int) CostBasis {
+	fifo := 0
+	left := qty
+	for i := range layers {
+		if left == 0 {
+			break
+		}
+		take := min(layers[i].Remaining, left)
+		layers[i].Remaining -= take
+		fifo += take * layers[i].UnitCost
+		left -= take
+	}
+	fifo += left * c.AverageCost
+
+	return CostBasis{FIFO: fifo, Average: qty * c.AverageCost, Last: qty * c.LastCost}
+}
+
+// Value is what onHand units are worth. Under FIFO the stock left is the
+// most recently received, so layers (oldest first) are walked from the end;
+// units beyond the layers are valued at the average.
+func (c ProductCost) Value(layers []CostLayer, onHand int) CostBasis {
+	if onHand <= 0 {
+		return CostBasis{}
+	}
+
+	fifo := 0
+	left := onHand
+	for i := len(layers) - 1; i >= 0 && left > 0; i-- {
+		take := min(layers[i].Remaining, left)
+		fifo += take * layers[i].UnitCost
+		left -= take
+	}
+	fifo += left * c.AverageCost
+
+	return CostBasis{FIFO: fifo, Average: onHand * c.AverageCost, Last: onHand * c.LastCost}
+}
+
+// ProductValuation is the value of one product, or one variant, on hand.
+type ProductValuation struct {
+	ProductID  int    `json:"product_id"`
+	VariantID  int    `json:"variant_id,omitempty"`
+	Name       string `json:"name"`
+	SKU        string `json:"sku"`
+	CategoryID int    `json:"category_id"`
+	Quantity   int    `json:"quantity"`
+	// Value holds every method; UnitCost and Total in reports use the
+	// selected one.
+	Value    CostBasis `json:"-"`
+	UnitCost int       `json:"unit_cost"`
+	Total    int       `json:"value"`
+}
+
+type CategoryValuation struct {
+	CategoryID int    `json:"category_id"`
+	Name       string `json:"name"`
+	Quantity   int    `json:"quantity"`
+	Value      int    `json:"value"`
+}
+
+type InventoryValuation struct {
+	Method     ValuationMethod     `json:"method"`
+	Quantity   int                 `json:"quantity"`
+	Value      int                 `json:"value"`
+	Categories []CategoryValuation `json:"categories"`
+	Items      []ProductValuation  `json:"items"`
+}
+
+// SalesCost is revenue and the cost of the goods sold for one period or one
+// category.
+type SalesCost struct {
+	Start      time.Time
+	CategoryID int
+	Name       string
+	Revenue    int
+	Cost       CostBasis
+}
+
+// Margin is revenue against cost of goods sold under one valuation method.
+type Margin struct {
+	Start       *time.Time `json:"start,omitempty"`
+	CategoryID  *int       `json:"category_id,omitempty"`
+	Name        string     `json:"name,omitempty"`
+	Revenue     int        `json:"revenue"`
+	COGS        int        `json:"cogs"`
+	GrossMargin int        `json:"gross_margin"`
+	// MarginPercent is gross margin over revenue, in percent.
+	MarginPercent float64 `json:"margin_percent"`
+}
+
+type COGSReport struct {
+	From       time.Time       `json:"from"`
+	To         time.Time       `json:"to"`
+	Timezone   string          `json:"timezone"`
+	GroupBy    SalesGrouping   `json:"group_by"`
+	Method     ValuationMethod `json:"method"`
+	Totals     Margin          `json:"totals"`
+	Periods    []Margin        `json:"periods"`
+	Categories []Margin        `json:"categories"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "errors"
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalid      = errors.New("invalid input")
+	ErrConflict     = errors.New("conflict")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrDuplicate is the ErrConflict of something that must be unique, such
+	// as a SKU, and is already taken. Other conflicts are with the state of
+	// what is changed, such as a closed shift.
+	ErrDuplicate error = duplicateError{}
+)
+
+type duplicateError struct{}
+
+func (duplicateError) Error() string { return ErrConflict.Error() }
+
+func (duplicateError) Is(target error) bool { return target == ErrConflict }
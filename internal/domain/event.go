@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventProductCreated      EventType = "product.created"
+	EventProductUpdated      EventType = "product.updated"
+	EventProductDeleted      EventType = "product.deleted"
+	EventProductPriceChanged EventType = "product.price_changed"
+	EventCategoryCreated     EventType = "category.created"
+	EventCategoryUpdated     EventType = "category.updated"
+	EventCategoryDeleted     EventType = "category.deleted"
+	// EventStockChanged fires whenever the on-hand quantity of a product
+	// changes; moving stock between locations leaves it alone.
+	EventStockChanged EventType = "stock.changed"
+	// EventStockDepleted fires when the quantity of a product, or of a variant,
+	// drops from above zero to zero or below.
+	EventStockDepleted EventType = "stock.depleted"
+)
+
+// EventTypes lists every event type, in the order above.
+var EventTypes = []EventType{
+	EventProductCreated,
+	EventProductUpdated,
+	EventProductDeleted,
+	EventProductPriceChanged,
+	EventCategoryCreated,
+	EventCategoryUpdated,
+	EventCategoryDeleted,
+	EventStockChanged,
+	EventStockDepleted,
+}
+
+// Event is something that happened to a product, category or stock level.
+// Data holds the payload for its type: ProductEvent, CategoryEvent,
+// PriceChangedEvent or StockEvent. EntityID is the product or category ID.
+type Event struct {
+	ID int `json:"id"`
+	// Seq is the position of the event in the order events were published,
+	// which is not the order of their IDs: an event recorded first may be
+	// committed or retried later. Streams resume from it.
+	Seq        int             `json:"seq,omitempty"`
+	Type       EventType       `json:"type"`
+	EntityID   int             `json:"entity_id"`
+	Data       json.RawMessage `json:"data"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+type ProductEvent struct {
+	Product Product `json:"product"`
+}
+
+type CategoryEvent struct {
+	Category Category `json:"category"`
+}
+
+type PriceChangedEvent struct {
+	ProductID int `json:"product_id"`
+	OldPrice  int `json:"old_price"`
+	NewPrice  int `json:"new_price"`
+	// ScheduledPriceID is set when the change came from a scheduled price.
+	ScheduledPriceID int `json:"scheduled_price_id,omitempty"`
+}
+
+type StockEvent struct {
+	ProductID  int `json:"product_id"`
+	VariantID  int `json:"variant_id,omitempty"`
+	LocationID int `json:"location_id,omitempty"`
+	Delta      int `json:"delta"`
+	// Quantity is what is left of the variant, or of the product when there
+	// is no variant; ProductQuantity is what is left of the product.
+	Quantity        int `json:"quantity"`
+	ProductQuantity int `json:"product_quantity"`
+}
+
+// NewEvent wraps data as an event of type t about entityID.
+func NewEvent(t EventType, entityID int, data any) (Event, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{Type: t, EntityID: entityID, Data: b}, nil
+}
+
+// Decode unmarshals the payload of e into v.
+func (e Event) Decode(v any) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// ProductEvents returns the events for a product going from before to
+// after: a create when before is nil, a delete when after is nil, and
+// otherwise an update, followed by price and stock events for what changed.
+func ProductEvents(before, after *Product) ([]Event, error) {
+	var events []Event
+	add := func(t EventType, id int, data any) error {
+		e, err := NewEvent(t, id, data)
+		if err == nil {
+			events = append(events, e)
+		}
+		return err
+	}
+
+	switch {
+	case before == nil:
+		return events, add(EventProductCreated, after.ID, ProductEvent{Product: *after})
+	case after == nil:
+		return events, add(EventProductDeleted, before.ID, ProductEvent{Product: *before})
+	}
+
+	if err := add(EventProductUpdated, after.ID, ProductEvent{Product: *after}); err != nil {
+		return nil, err
+	}
+	if before.Price != after.Price {
+		if err := add(EventProductPriceChanged, after.ID, PriceChangedEvent{
+			ProductID: after.ID,
+			OldPrice:  before.Price,
+			NewPrice:  after.Price,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if before.Quantity != after.Quantity {
+		stock, err := StockEvents(StockChange{ProductID: after.ID, Delta: after.Quantity - before.Quantity}, after.Quantity, after.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, stock...)
+	}
+	return events, nil
+}
+
+// CategoryEvents returns the event for a category going from before to
+// after, either of which may be nil.
+func CategoryEvents(before, after *Category) ([]Event, error) {
+	var e Event
+	var err error
+	switch {
+	case before == nil:
+		e, err = NewEvent(EventCategoryCreated, after.ID, CategoryEvent{Category: *after})
+	case after == nil:
+		e, err = NewEvent(EventCategoryDeleted, before.ID, CategoryEvent{Category: *before})
+	default:
+		e, err = NewEvent(EventCategoryUpdated, after.ID, CategoryEvent{Category: *after})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []Event{e}, nil
+}
+
+// StockEvents returns the events for a stock change that left quantity of
+// the variant (or product) and productQuantity of the product.
+func StockEvents(c StockChange, quantity, productQuantity int) ([]Event, error) {
+	data := StockEvent{
+		ProductID:       c.ProductID,
+		VariantID:       c.VariantID,
+		LocationID:      c.LocationID,
+		Delta:           c.Delta,
+		Quantity:        quantity,
+		ProductQuantity: productQuantity,
+	}
+	changed, err := NewEvent(EventStockChanged, c.ProductID, data)
+	if err != nil {
+		return nil, err
+	}
+	events := []Event{changed}
+	if quantity <= 0 && quantity-c.Delta > 0 {
+		depleted, err := NewEvent(EventStockDepleted, c.ProductID, data)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, depleted)
+	}
+	return events, nil
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// ImportRowError explains why one row of an import file was rejected. Row is
+// the line number in the file, counting the header as row 1.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult summarises an import. A file with any row errors is not
+// applied at all, so Created and Updated then describe what would have
+// happened.
+type ImportResult struct {
+	DryRun  bool             `json:"dry_run"`
+	Applied bool             `json:"applied"`
+	Total   int              `json:"total"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+type ImportKind string
+
+const (
+	ImportProducts   ImportKind = "products"
+	ImportCategories ImportKind = "categories"
+)
+
+type ImportJobStatus string
+
+const (
+	ImportJobQueued    ImportJobStatus = "queued"
+	ImportJobRunning   ImportJobStatus = "running"
+	ImportJobSucceeded ImportJobStatus = "succeeded"
+	ImportJobFailed    ImportJobStatus = "failed"
+)
+
+// ImportJob tracks an import running in the background. Processed counts the
+// rows validated so far; the rows are applied together once all of them
+// have passed. Result is set when the job finishes, and Error when it could
+// not run to completion.
+type ImportJob struct {
+	ID         int             `json:"id"`
+	Kind       ImportKind      `json:"kind"`
+	Status     ImportJobStatus `json:"status"`
+	DryRun     bool            `json:"dry_run"`
+	Total      int             `json:"total"`
+	Processed  int             `json:"processed"`
+	Result     *ImportResult   `json:"result"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	FinishedAt *time.Time      `json:"finished_at"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+type Location struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StockLevel is the on-hand quantity of a product or variant at a location.
+type StockLevel struct {
+	LocationID int       `json:"location_id"`
+	ProductID  int       `json:"product_id"`
+	VariantID  int       `json:"variant_id,omitempty"`
+	Quantity   int       `json:"quantity"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
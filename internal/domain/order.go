@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type TenderMethod string
+
+const (
+	TenderCash     TenderMethod = "cash"
+	TenderCard     TenderMethod = "card"
+	TenderQRIS     TenderMethod = "qris"
+	TenderTransfer TenderMethod = "transfer"
+	TenderOther    TenderMethod = "other"
+)
+
+func (m TenderMethod) Valid() bool {
+	switch m {
+	case TenderCash, TenderCard, TenderQRIS, TenderTransfer, TenderOther:
+		return true
+	}
+	return false
+}
+
+// Order is a completed sale.
+type Order struct {
+	ID int `json:"id"`
+	// ClientID is made up by the terminal that rang up the sale, so a sale
+	// pushed twice after working offline is only recorded once.
+	ClientID   string `json:"client_id,omitempty"`
+	ShiftID    int    `json:"shift_id,omitempty"`
+	RegisterID string `json:"register_id"`
+	UserID     string `json:"user_id"`
+	// LocationID is the outlet the goods left from; zero sells from
+	// unassigned stock.
+	LocationID int         `json:"location_id,omitempty"`
+	Items      []OrderItem `json:"items"`
+	Subtotal   int         `json:"subtotal"`
+	Tax        int         `json:"tax"`
+	Total      int         `json:"total"`
+	Tenders    []Tender    `json:"tenders"`
+	Paid       int         `json:"paid"`
+	Change     int         `json:"change"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type OrderItem struct {
+	ID        int    `json:"id"`
+	ProductID int    `json:"product_id"`
+	VariantID int    `json:"variant_id,omitempty"`
+	Name      string `json:"name"`
+	SKU       string `json:"sku"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int    `json:"unit_price"`
+	LineTotal int    `json:"line_total"`
+	// Shortfall is how many of the units sold were not in stock when a sale
+	// rung up offline reached the server. They were sold all the same but
+	// not taken out of stock, which a count or adjustment has to settle.
+	Shortfall int `json:"shortfall,omitempty"`
+	// Cost is what the goods sold cost, fixed when the order is placed.
+	Cost CostBasis `json:"-"`
+}
+
+type Tender struct {
+	Method TenderMethod `json:"method"`
+	Amount int          `json:"amount"`
+}
+
+// Price fills in the totals from the item unit prices, a tax rate in percent
+// and the tenders, and checks that the payment covers the total. Change can
+// only be given out of cash.
+func (o *Order) Price(taxRate float64) error {
+	o.Subtotal = 0
+	for i := range o.Items {
+		o.Items[i].LineTotal = o.Items[i].UnitPrice * o.Items[i].Quantity
+		o.Subtotal += o.Items[i].LineTotal
+	}
+	o.Tax = int(float64(o.Subtotal)*taxRate/100 + 0.5)
+	o.Total = o.Subtotal + o.Tax
+
+	o.Paid = 0
+	cash := 0
+	for _, t := range o.Tenders {
+		if !t.Method.Valid() {
+			return fmt.Errorf("%w: unknown tender method %q", ErrInvalid, t.Method)
+		}
+		if t.Amount <= 0 {
+			return fmt.Errorf("%w: tender amount must be positive", ErrInvalid)
+		}
+		o.Paid += t.Amount
+		if t.Method == TenderCash {
+			cash += t.Amount
+		}
+	}
+	if o.Paid < o.Total {
+		return fmt.Errorf("%w: paid %d is less than total %d", ErrInvalid, o.Paid, o.Total)
+	}
+	o.Change = o.Paid - o.Total
+	if o.Change > cash {
+		return fmt.Errorf("%w: change of %d exceeds the cash tendered", ErrInvalid, o.Change)
+	}
+	return nil
+}
+
+// StockChanges returns the stock taken out by the sale, one change per item.
+// Units the sale fell short of are not taken out, so an item wholly short
+// gives a change of zero.
+func (o Order) StockChanges() []StockChange {
+	out := make([]StockChange, 0, len(o.Items))
+	for _, it := range o.Items {
+		out = append(out, StockChange{
+			LocationID: o.LocationID,
+			ProductID:  it.ProductID,
+			VariantID:  it.VariantID,
+			Delta:      -(it.Quantity - it.Shortfall),
+		})
+	}
+	return out
+}
+
+// CashTaken is the cash the sale left in the drawer: cash tendered minus
+// change given.
+func (o Order) CashTaken() int {
+	cash := 0
+	for _, t := range o.Tenders {
+		if t.Method == TenderCash {
+			cash += t.Amount
+		}
+	}
+	return cash - o.Change
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// PriceChange records one change to a product's price. OldPrice is nil for
+// the price a product was created with.
+type PriceChange struct {
+	ID        int    `json:"id"`
+	ProductID int    `json:"product_id"`
+	OldPrice  *int   `json:"old_price"`
+	NewPrice  int    `json:"new_price"`
+	Actor     string `json:"actor"`
+	Reason    string `json:"reason"`
+	// ScheduledPriceID is set when the change applied a scheduled price.
+	ScheduledPriceID int       `json:"scheduled_price_id,omitempty"`
+	ChangedAt        time.Time `json:"changed_at"`
+}
+
+type ScheduledPriceStatus string
+
+const (
+	ScheduledPricePending   ScheduledPriceStatus = "pending"
+	ScheduledPriceApplied   ScheduledPriceStatus = "applied"
+	ScheduledPriceCancelled ScheduledPriceStatus = "cancelled"
+)
+
+// ScheduledPrice is a price that takes effect at EffectiveFrom.
+type ScheduledPrice struct {
+	ID            int                  `json:"id"`
+	ProductID     int                  `json:"product_id"`
+	Price         int                  `json:"price"`
+	EffectiveFrom time.Time            `json:"effective_from"`
+	Reason        string               `json:"reason"`
+	Actor         string               `json:"actor"`
+	Status        ScheduledPriceStatus `json:"status"`
+	CreatedAt     time.Time            `json:"created_at"`
+	AppliedAt     *time.Time           `json:"applied_at"`
+}
+
+// ProductPrices is a product's price with its history, newest first, and
+// its pending scheduled prices, soonest first.
+type ProductPrices struct {
+	ProductID int              `json:"product_id"`
+	Price     int              `json:"price"`
+	History   []PriceChange    `json:"history"`
+	Upcoming  []ScheduledPrice `json:"upcoming"`
+}
@@ -3,10 +3,51 @@ package domain
 import "time"
 
 type Product struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Price     int       `json:"price"`
-	Quantity  int       `json:"quantity"`
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	SKU      string   `json:"sku"`
+	Barcodes []string `json:"barcodes"`
+	// CategoryID is zero for uncategorised products.
+	CategoryID int `json:"category_id"`
+	Price      int `json:"price"`
+	// Quantity is the sum of variant quantities when the product has variants.
+	Quantity int `json:"quantity"`
+	// ReorderPoint enables low-stock alerts when positive: the product is low
+	// once Quantity is at or below it. ReorderQuantity is the usual amount to
+	// buy when restocking.
+	ReorderPoint    int              `json:"reorder_point"`
+	ReorderQuantity int              `json:"reorder_quantity"`
+	Options         []ProductOption  `json:"options"`
+	Variants        []ProductVariant `json:"variants"`
+	// ChangeSeq is the catalog change number of the last change, see
+	// CatalogChanges.
+	ChangeSeq int       `json:"change_seq"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+func (p Product) IsLowStock() bool {
+	return p.ReorderPoint > 0 && p.Quantity <= p.ReorderPoint
+}
+
+// Variant returns the variant with the given ID.
+func (p Product) Variant(id int) (ProductVariant, bool) {
+	for _, v := range p.Variants {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return ProductVariant{}, false
+}
+
+// StockChange is a quantity delta for a product or, when VariantID is set,
+// one of its variants. When LocationID is set the stock level at that
+// location changes along with the product total. UnitCost prices incoming
+// stock; zero takes it in at the current average cost.
+type StockChange struct {
+	LocationID int `json:"location_id,omitempty"`
+	ProductID  int `json:"product_id"`
+	VariantID  int `json:"variant_id,omitempty"`
+	Delta      int `json:"delta"`
+	UnitCost   int `json:"unit_cost,omitempty"`
+}
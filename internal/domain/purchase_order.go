@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderDraft             PurchaseOrderStatus = "draft"
+	PurchaseOrderSubmitted         PurchaseOrderStatus = "submitted"
+	PurchaseOrderPartiallyReceived PurchaseOrderStatus = "partially_received"
+	PurchaseOrderReceived          PurchaseOrderStatus = "received"
+	PurchaseOrderCancelled         PurchaseOrderStatus = "cancelled"
+)
+
+// purchaseOrderTransitions lists the statuses each status may move to.
+var purchaseOrderTransitions = map[PurchaseOrderStatus][]PurchaseOrderStatus{
+	PurchaseOrderDraft:             {PurchaseOrderSubmitted, PurchaseOrderCancelled},
+	PurchaseOrderSubmitted:         {PurchaseOrderPartiallyReceived, PurchaseOrderReceived, PurchaseOrderCancelled},
+	PurchaseOrderPartiallyReceived: {PurchaseOrderPartiallyReceived, PurchaseOrderReceived, PurchaseOrderCancelled},
+}
+
+func (s PurchaseOrderStatus) CanTransitionTo(next PurchaseOrderStatus) bool {
+	for _, allowed := range purchaseOrderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+type PurchaseOrder struct {
+	ID         int `json:"id"`
+	SupplierID int `json:"supplier_id"`
+	// LocationID is where received stock is put away; zero leaves it unassigned.
+	LocationID  int                 `json:"location_id,omitempty"`
+	Status      PurchaseOrderStatus `json:"status"`
+	Notes       string              `json:"notes"`
+	Lines       []PurchaseOrderLine `json:"lines"`
+	SubmittedAt *time.Time          `json:"submitted_at"`
+	ReceivedAt  *time.Time          `json:"received_at"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+type PurchaseOrderLine struct {
+	ID               int `json:"id"`
+	ProductID        int `json:"product_id"`
+	VariantID        int `json:"variant_id,omitempty"`
+	Quantity         int `json:"quantity"`
+	ReceivedQuantity int `json:"received_quantity"`
+	UnitCost         int `json:"unit_cost"`
+}
+
+func (l PurchaseOrderLine) Outstanding() int {
+	return l.Quantity - l.ReceivedQuantity
+}
+
+// ReceiveLine is one line of a delivery recorded against a purchase order.
+type ReceiveLine struct {
+	LineID   int `json:"line_id"`
+	Quantity int `json:"quantity"`
+}
+
+// Transition moves the order to next, or returns ErrConflict when the
+// status machine does not allow it.
+func (po *PurchaseOrder) Transition(next PurchaseOrderStatus, at time.Time) error {
+	if !po.Status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: purchase order cannot move from %s to %s", ErrConflict, po.Status, next)
+	}
+	po.Status = next
+	switch next {
+	case PurchaseOrderSubmitted:
+		po.SubmittedAt = &at
+	case PurchaseOrderReceived:
+		po.ReceivedAt = &at
+	}
+	return nil
+}
+
+// Receive applies a delivery to the order lines and advances the status.
+// It returns the resulting stock increases so callers can persist both sides
+// of the receipt together.
+func (po *PurchaseOrder) Receive(lines []ReceiveLine, at time.Time) ([]StockChange, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: at least one line is required", ErrInvalid)
+	}
+
+	index := make(map[int]int, len(po.Lines))
+	for i, l := range po.Lines {
+		index[l.ID] = i
+	}
+
+	received := make([]StockChange, 0, len(lines))
+	for _, rl := range lines {
+		i, ok := index[rl.LineID]
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d does not belong to purchase order %d", ErrInvalid, rl.LineID, po.ID)
+		}
+		if rl.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity for line %d must be positive", ErrInvalid, rl.LineID)
+		}
+		if rl.Quantity > po.Lines[i].Outstanding() {
+			return nil, fmt.Errorf("%w: line %d has only %d outstanding", ErrInvalid, rl.LineID, po.Lines[i].Outstanding())
+		}
+		po.Lines[i].ReceivedQuantity += rl.Quantity
+		received = append(received, StockChange{
+			LocationID: po.LocationID,
+			ProductID:  po.Lines[i].ProductID,
+			VariantID:  po.Lines[i].VariantID,
+			Delta:      rl.Quantity,
+			UnitCost:   po.Lines[i].UnitCost,
+		})
+	}
+
+	next := PurchaseOrderReceived
+	for _, l := range po.Lines {
+		if l.Outstanding() > 0 {
+			next = PurchaseOrderPartiallyReceived
+			break
+		}
+	}
+	if err := po.Transition(next, at); err != nil {
+		return nil, err
+	}
+	return received, nil
+}
+
+// PurchaseOrderLineMargin compares the recorded unit cost of a line with the
+// current selling price of its product.
+type PurchaseOrderLineMargin struct {
+	LineID        int     `json:"line_id"`
+	ProductID     int     `json:"product_id"`
+	VariantID     int     `json:"variant_id,omitempty"`
+	UnitCost      int     `json:"unit_cost"`
+	Price         int     `json:"price"`
+	Margin        int     `json:"margin"`
+	MarginPercent float64 `json:"margin_percent"`
+}
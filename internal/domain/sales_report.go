@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type SalesGrouping string
+
+const (
+	GroupByDay   SalesGrouping = "day"
+	GroupByWeek  SalesGrouping = "week"
+	GroupByMonth SalesGrouping = "month"
+)
+
+func (g SalesGrouping) Valid() bool {
+	switch g {
+	case GroupByDay, GroupByWeek, GroupByMonth:
+		return true
+	}
+	return false
+}
+
+// PeriodStart is the start of the day, week or month containing t in loc.
+// Weeks start on Monday, matching Postgres date_trunc.
+func (g SalesGrouping) PeriodStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch g {
+	case GroupByWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case GroupByMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// NextPeriod is the start of the period after the one starting at start.
+func (g SalesGrouping) NextPeriod(start time.Time) time.Time {
+	switch g {
+	case GroupByWeek:
+		return start.AddDate(0, 0, 7)
+	case GroupByMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// SalesReportParams selects orders created in [From, To). Location is the
+// time zone periods are cut in.
+type SalesReportParams struct {
+	From     time.Time
+	To       time.Time
+	GroupBy  SalesGrouping
+	Location *time.Location
+	Top      int
+}
+
+func (p SalesReportParams) Validate() error {
+	if !p.From.Before(p.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalid)
+	}
+	if !p.GroupBy.Valid() {
+		return fmt.Errorf("%w: group_by must be day, week or month", ErrInvalid)
+	}
+	return nil
+}
+
+// SalesPeriod totals the orders of one day, week or month. Revenue figures
+// are before tax.
+type SalesPeriod struct {
+	Start      time.Time `json:"start"`
+	OrderCount int       `json:"order_count"`
+	ItemsSold  int       `json:"items_sold"`
+	Subtotal   int       `json:"subtotal"`
+	Tax        int       `json:"tax"`
+	Total      int       `json:"total"`
+}
+
+type ProductSales struct {
+	ProductID int    `json:"product_id"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	Revenue   int    `json:"revenue"`
+}
+
+// CategorySales is the revenue of one category; CategoryID zero collects
+// uncategorised products.
+type CategorySales struct {
+	CategoryID int    `json:"category_id"`
+	Name       string `json:"name"`
+	Quantity   int    `json:"quantity"`
+	Revenue    int    `json:"revenue"`
+	// Share is the category's part of total revenue, in percent.
+	Share float64 `json:"share"`
+}
+
+type SalesReport struct {
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	Timezone      string          `json:"timezone"`
+	GroupBy       SalesGrouping   `json:"group_by"`
+	Totals        SalesPeriod     `json:"totals"`
+	Periods       []SalesPeriod   `json:"periods"`
+	TopByRevenue  []ProductSales  `json:"top_by_revenue"`
+	TopByQuantity []ProductSales  `json:"top_by_quantity"`
+	Categories    []CategorySales `json:"categories"`
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type ShiftStatus string
+
+const (
+	ShiftOpen   ShiftStatus = "open"
+	ShiftClosed ShiftStatus = "closed"
+)
+
+// Shift is one cashier's session on a register, from opening float to the
+// final cash count.
+type Shift struct {
+	ID           int         `json:"id"`
+	RegisterID   string      `json:"register_id"`
+	UserID       string      `json:"user_id"`
+	Status       ShiftStatus `json:"status"`
+	OpeningFloat int         `json:"opening_float"`
+	// ExpectedCash, CountedCash and Variance are recorded when the shift closes.
+	ExpectedCash *int       `json:"expected_cash"`
+	CountedCash  *int       `json:"counted_cash"`
+	Variance     *int       `json:"variance"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at"`
+}
+
+// CheckSale returns ErrConflict unless a sale made at t can be recorded on
+// the shift. A zero t is a sale made now, which needs the shift still open;
+// an earlier sale, such as one pushed after working offline, only needs the
+// shift to have been open at t.
+func (s Shift) CheckSale(t time.Time) error {
+	if t.IsZero() {
+		if s.Status != ShiftOpen {
+			return fmt.Errorf("%w: shift %d is closed", ErrConflict, s.ID)
+		}
+		return nil
+	}
+	if t.Before(s.OpenedAt) || s.ClosedAt != nil && t.After(*s.ClosedAt) {
+		return fmt.Errorf("%w: shift %d was not open at %s", ErrConflict, s.ID, t.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+type CashMovementKind string
+
+const (
+	CashIn  CashMovementKind = "cash_in"
+	CashOut CashMovementKind = "cash_out"
+)
+
+// CashMovement is cash put into or taken out of the drawer outside a sale,
+// such as a float top-up or a petty cash payment.
+type CashMovement struct {
+	ID        int              `json:"id"`
+	ShiftID   int              `json:"shift_id"`
+	Kind      CashMovementKind `json:"kind"`
+	Amount    int              `json:"amount"`
+	Reason    string           `json:"reason"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// ShiftSales aggregates the orders rung up during a shift.
+type ShiftSales struct {
+	OrderCount int                  `json:"order_count"`
+	Subtotal   int                  `json:"subtotal"`
+	Tax        int                  `json:"tax"`
+	Total      int                  `json:"total"`
+	Tenders    map[TenderMethod]int `json:"tenders"`
+	Change     int                  `json:"change"`
+}
+
+// CashSales is the net cash the orders left in the drawer.
+func (s ShiftSales) CashSales() int {
+	return s.Tenders[TenderCash] - s.Change
+}
+
+type ShiftReportType string
+
+const (
+	// XReport is a mid-shift snapshot; ZReport closes out the shift.
+	XReport ShiftReportType = "X"
+	ZReport ShiftReportType = "Z"
+)
+
+type ShiftReport struct {
+	Type         ShiftReportType `json:"type"`
+	Shift        Shift           `json:"shift"`
+	Sales        ShiftSales      `json:"sales"`
+	CashSales    int             `json:"cash_sales"`
+	CashIn       int             `json:"cash_in"`
+	CashOut      int             `json:"cash_out"`
+	ExpectedCash int             `json:"expected_cash"`
+	CountedCash  *int            `json:"counted_cash"`
+	// Variance is counted minus expected cash: positive is over, negative short.
+	Variance    *int      `json:"variance"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// ExpectedCash is what the drawer should hold: the opening float plus cash
+// sales and cash in, minus cash out.
+func ExpectedCash(openingFloat int, sales ShiftSales, movements []CashMovement) (expected, cashIn, cashOut int) {
+	for _, m := range movements {
+		switch m.Kind {
+		case CashIn:
+			cashIn += m.Amount
+		case CashOut:
+			cashOut += m.Amount
+		}
+	}
+	return openingFloat + sales.CashSales() + cashIn - cashOut, cashIn, cashOut
+}
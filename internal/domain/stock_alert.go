@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+type StockAlertKind string
+
+const (
+	// StockAlertLow fires when a product drops to or below its reorder point.
+	StockAlertLow StockAlertKind = "low_stock"
+	// StockAlertRestocked fires when a low product climbs back above it.
+	StockAlertRestocked StockAlertKind = "restocked"
+)
+
+type StockAlert struct {
+	Kind         StockAlertKind `json:"kind"`
+	ProductID    int            `json:"product_id"`
+	Name         string         `json:"name"`
+	Quantity     int            `json:"quantity"`
+	ReorderPoint int            `json:"reorder_point"`
+	At           time.Time      `json:"at"`
+}
+
+// ProductPurchasing summarises the purchase history of a product.
+type ProductPurchasing struct {
+	ProductID int `json:"product_id"`
+	// SupplierID and UnitCost come from the most recent purchase order line.
+	SupplierID int `json:"supplier_id"`
+	UnitCost   int `json:"unit_cost"`
+	// OnOrder is the quantity still outstanding on open purchase orders.
+	OnOrder int `json:"on_order"`
+}
+
+type ReorderSuggestionItem struct {
+	ProductID         int    `json:"product_id"`
+	Name              string `json:"name"`
+	Quantity          int    `json:"quantity"`
+	ReorderPoint      int    `json:"reorder_point"`
+	OnOrder           int    `json:"on_order"`
+	SuggestedQuantity int    `json:"suggested_quantity"`
+	UnitCost          int    `json:"unit_cost"`
+	LineCost          int    `json:"line_cost"`
+}
+
+// ReorderSuggestion groups the products to buy from one supplier. SupplierID
+// is zero for products that have never been purchased.
+type ReorderSuggestion struct {
+	SupplierID   int                     `json:"supplier_id"`
+	SupplierName string                  `json:"supplier_name"`
+	Items        []ReorderSuggestionItem `json:"items"`
+	TotalCost    int                     `json:"total_cost"`
+}
+
+// SuggestedReorder is how much of p to buy given what is already on order:
+// at least the reorder quantity and enough to lift stock above the reorder
+// point. It is zero when open orders already cover the shortfall.
+func SuggestedReorder(p Product, onOrder int) int {
+	shortfall := p.ReorderPoint - p.Quantity - onOrder + 1
+	if !p.IsLowStock() || shortfall <= 0 {
+		return 0
+	}
+	if p.ReorderQuantity > shortfall {
+		return p.ReorderQuantity
+	}
+	return shortfall
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type StockTransferStatus string
+
+const (
+	StockTransferInTransit StockTransferStatus = "in_transit"
+	StockTransferReceived  StockTransferStatus = "received"
+	StockTransferCancelled StockTransferStatus = "cancelled"
+)
+
+// StockTransfer moves stock between locations. Stock leaves the source when
+// the transfer is created and reaches the destination when it is received;
+// in between it is in transit and counted by neither location.
+type StockTransfer struct {
+	ID             int                 `json:"id"`
+	FromLocationID int                 `json:"from_location_id"`
+	ToLocationID   int                 `json:"to_location_id"`
+	Status         StockTransferStatus `json:"status"`
+	Notes          string              `json:"notes"`
+	Lines          []StockTransferLine `json:"lines"`
+	ReceivedAt     *time.Time          `json:"received_at"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+type StockTransferLine struct {
+	ProductID int `json:"product_id"`
+	VariantID int `json:"variant_id,omitempty"`
+	Quantity  int `json:"quantity"`
+}
+
+// Ship returns the location stock changes that take the goods out of the
+// source location.
+func (t StockTransfer) Ship() []StockChange {
+	return t.changes(t.FromLocationID, -1)
+}
+
+// Complete closes an in-transit transfer with the given status and returns
+// the location stock changes that put the goods down: at the destination when
+// received, back at the source when cancelled.
+func (t *StockTransfer) Complete(next StockTransferStatus, at time.Time) ([]StockChange, error) {
+	if t.Status != StockTransferInTransit {
+		return nil, fmt.Errorf("%w: transfer is already %s", ErrConflict, t.Status)
+	}
+
+	t.Status = next
+	switch next {
+	case StockTransferReceived:
+		t.ReceivedAt = &at
+		return t.changes(t.ToLocationID, 1), nil
+	case StockTransferCancelled:
+		return t.changes(t.FromLocationID, 1), nil
+	}
+	return nil, fmt.Errorf("%w: unknown transfer status %s", ErrInvalid, next)
+}
+
+func (t StockTransfer) changes(locationID, sign int) []StockChange {
+	out := make([]StockChange, 0, len(t.Lines))
+	for _, l := range t.Lines {
+		out = append(out, StockChange{
+			LocationID: locationID,
+			ProductID:  l.ProductID,
+			VariantID:  l.VariantID,
+			Delta:      sign * l.Quantity,
+		})
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// CatalogChanges is what changed in the catalog after a terminal's last
+// sync. Every change to a product or category, deletions included, takes the
+// next number from one sequence. The Watermark marks how far the pull got;
+// a terminal passes it as the since of the next, and should not compare it
+// with change numbers.
+type CatalogChanges struct {
+	Since      int                `json:"since"`
+	Watermark  int                `json:"watermark"`
+	HasMore    bool               `json:"has_more"`
+	Products   []Product          `json:"products"`
+	Categories []Category         `json:"categories"`
+	Deleted    []CatalogTombstone `json:"deleted"`
+}
+
+// CatalogTombstone records that a product or category was deleted.
+// EntityType is AuditProduct or AuditCategory.
+type CatalogTombstone struct {
+	ChangeSeq  int       `json:"change_seq"`
+	EntityType string    `json:"entity_type"`
+	EntityID   int       `json:"entity_id"`
+	DeletedAt  time.Time `json:"deleted_at"`
+}
+
+type SyncOrderStatus string
+
+const (
+	// SyncApplied means the sale was recorded by this push.
+	SyncApplied SyncOrderStatus = "applied"
+	// SyncDuplicate means the sale was already recorded by an earlier push.
+	SyncDuplicate SyncOrderStatus = "duplicate"
+	// SyncConflict means the sale clashes with the server's state. If it
+	// sold more than was in stock it is recorded anyway, with the missing
+	// units as each item's shortfall, and Order is set. Otherwise it was not
+	// recorded, for example because its shift was not open at the time of
+	// the sale.
+	SyncConflict SyncOrderStatus = "conflict"
+	// SyncRejected means the sale is invalid, for example it names a
+	// product that no longer exists.
+	SyncRejected SyncOrderStatus = "rejected"
+)
+
+// SyncOrderResult is the outcome of one pushed offline sale. Order is set
+// when the sale was recorded, by this push or an earlier one, and Error
+// explains a conflict or rejection.
+type SyncOrderResult struct {
+	ClientID string          `json:"client_id"`
+	Status   SyncOrderStatus `json:"status"`
+	Order    *Order          `json:"order,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
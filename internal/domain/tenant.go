@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tenant is a merchant served by the deployment. Each tenant sees only its
+// own catalog, stock, sales and settings. A suspended tenant's requests are
+// refused, but its data is kept.
+type Tenant struct {
+	ID        int            `json:"id"`
+	Slug      string         `json:"slug"`
+	Name      string         `json:"name"`
+	Suspended bool           `json:"suspended"`
+	Settings  TenantSettings `json:"settings"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// TenantSettings are a tenant's own tax rate, time zone, costing and receipt
+// details. A nil TaxRate and empty fields fall back to the deployment's
+// configuration.
+type TenantSettings struct {
+	TaxRate         *float64        `json:"tax_rate"`
+	Timezone        string          `json:"timezone"`
+	ValuationMethod ValuationMethod `json:"valuation_method"`
+	StoreName       string          `json:"store_name"`
+	StoreAddress    string          `json:"store_address"`
+	StorePhone      string          `json:"store_phone"`
+	ReceiptFooter   string          `json:"receipt_footer"`
+}
+
+// Settings are the settings in effect for a tenant, once its own are laid
+// over the deployment's.
+type Settings struct {
+	// TaxRate is the sales tax added to orders, in percent.
+	TaxRate float64
+	// Location is the time zone of receipts and reports.
+	Location *time.Location
+	// ValuationMethod is the default costing of inventory and COGS reports.
+	ValuationMethod ValuationMethod
+	StoreName       string
+	StoreAddress    string
+	StorePhone      string
+	ReceiptFooter   string
+}
+
+// Over returns defaults with the settings s sets in place of theirs.
+func (s TenantSettings) Over(defaults Settings) (Settings, error) {
+	out := defaults
+	if s.TaxRate != nil {
+		out.TaxRate = *s.TaxRate
+	}
+	if s.Timezone != "" {
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return Settings{}, fmt.Errorf("tenant time zone: %w", err)
+		}
+		out.Location = loc
+	}
+	if s.ValuationMethod != "" {
+		out.ValuationMethod = s.ValuationMethod
+	}
+	if s.StoreName != "" {
+		out.StoreName = s.StoreName
+	}
+	if s.StoreAddress != "" {
+		out.StoreAddress = s.StoreAddress
+	}
+	if s.StorePhone != "" {
+		out.StorePhone = s.StorePhone
+	}
+	if s.ReceiptFooter != "" {
+		out.ReceiptFooter = s.ReceiptFooter
+	}
+	return out, nil
+}
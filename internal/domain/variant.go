@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxVariants caps the number of combinations one product may generate.
+const maxVariants = 100
+
+// ProductOption is one axis a product varies on, such as size or color.
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductVariant struct {
+	ID        int               `json:"id"`
+	ProductID int               `json:"product_id"`
+	SKU       string            `json:"sku"`
+	Options   map[string]string `json:"options"`
+	// Price overrides the parent product price when set.
+	Price    *int `json:"price"`
+	Quantity int  `json:"quantity"`
+}
+
+// EffectivePrice is the variant price override or the parent base price.
+func (v ProductVariant) EffectivePrice(base int) int {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return base
+}
+
+// variantKey identifies a combination of option values in option order.
+func variantKey(options []ProductOption, values map[string]string) string {
+	parts := make([]string, 0, len(options))
+	for _, o := range options {
+		parts = append(parts, o.Name+"="+values[o.Name])
+	}
+	return strings.Join(parts, "|")
+}
+
+// GenerateVariants expands the option axes into one variant per combination.
+// Requested variants supply SKU, price and quantity for a combination, and
+// existing variants keep their IDs when their combination is still present.
+func GenerateVariants(options []ProductOption, requested, existing []ProductVariant) ([]ProductVariant, error) {
+	if len(options) == 0 {
+		if len(requested) > 0 {
+			return nil, fmt.Errorf("%w: variants require at least one option", ErrInvalid)
+		}
+		return []ProductVariant{}, nil
+	}
+
+	combos := []map[string]string{{}}
+	names := make(map[string]bool, len(options))
+	for _, o := range options {
+		if o.Name == "" {
+			return nil, fmt.Errorf("%w: option name is required", ErrInvalid)
+		}
+		if names[o.Name] {
+			return nil, fmt.Errorf("%w: option %q is listed twice", ErrInvalid, o.Name)
+		}
+		names[o.Name] = true
+		if len(o.Values) == 0 {
+			return nil, fmt.Errorf("%w: option %q needs at least one value", ErrInvalid, o.Name)
+		}
+
+		values := make(map[string]bool, len(o.Values))
+		next := make([]map[string]string, 0, len(combos)*len(o.Values))
+		for _, v := range o.Values {
+			if v == "" {
+				return nil, fmt.Errorf("%w: option %q has an empty value", ErrInvalid, o.Name)
+			}
+			if values[v] {
+				return nil, fmt.Errorf("%w: option %q lists %q twice", ErrInvalid, o.Name, v)
+			}
+			values[v] = true
+			for _, c := range combos {
+				combo := make(map[string]string, len(c)+1)
+				for k, val := range c {
+					combo[k] = val
+				}
+				combo[o.Name] = v
+				next = append(next, combo)
+			}
+		}
+		combos = next
+		if len(combos) > maxVariants {
+			return nil, fmt.Errorf("%w: options produce more than %d variants", ErrInvalid, maxVariants)
+		}
+	}
+
+	valid := make(map[string]bool, len(combos))
+	for _, c := range combos {
+		valid[variantKey(options, c)] = true
+	}
+
+	byKey := make(map[string]ProductVariant, len(requested))
+	for _, v := range requested {
+		if len(v.Options) != len(options) {
+			return nil, fmt.Errorf("%w: variant options must name every option", ErrInvalid)
+		}
+		key := variantKey(options, v.Options)
+		if !valid[key] {
+			return nil, fmt.Errorf("%w: variant %s does not match the options", ErrInvalid, key)
+		}
+		if _, dup := byKey[key]; dup {
+			return nil, fmt.Errorf("%w: variant %s is listed twice", ErrInvalid, key)
+		}
+		if v.Quantity < 0 {
+			return nil, fmt.Errorf("%w: variant %s quantity must not be negative", ErrInvalid, key)
+		}
+		byKey[key] = v
+	}
+
+	existingIDs := make(map[string]int, len(existing))
+	for _, v := range existing {
+		existingIDs[variantKey(options, v.Options)] = v.ID
+	}
+
+	out := make([]ProductVariant, 0, len(combos))
+	skus := make(map[string]bool)
+	for _, c := range combos {
+		key := variantKey(options, c)
+		v := byKey[key]
+		v.ID = existingIDs[key]
+		v.Options = c
+		v.SKU = strings.TrimSpace(v.SKU)
+		if v.SKU != "" {
+			if skus[v.SKU] {
+				return nil, fmt.Errorf("%w: variant sku %q is listed twice", ErrInvalid, v.SKU)
+			}
+			skus[v.SKU] = true
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// TotalQuantity sums variant stock.
+func TotalQuantity(variants []ProductVariant) int {
+	total := 0
+	for _, v := range variants {
+		total += v.Quantity
+	}
+	return total
+}
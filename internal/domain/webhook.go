@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookSubscription sends events of the listed types, or of every type when
+// EventTypes is empty, to URL. Secret signs each delivery; it is only shown
+// when the subscription is created.
+type WebhookSubscription struct {
+	ID         int         `json:"id"`
+	URL        string      `json:"url"`
+	EventTypes []EventType `json:"event_types"`
+	Secret     string      `json:"secret,omitempty"`
+	Disabled   bool        `json:"disabled"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// Wants reports whether the subscription is enabled and takes events of type t.
+func (s WebhookSubscription) Wants(t EventType) bool {
+	if s.Disabled {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, et := range s.EventTypes {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookPending   WebhookDeliveryStatus = "pending"
+	WebhookSucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDead marks a delivery that failed every attempt; it is kept in
+	// the log and can be redelivered by hand.
+	WebhookDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery is one event sent to one subscription. Payload is the
+// event as it is posted.
+type WebhookDelivery struct {
+	ID             int                   `json:"id"`
+	SubscriptionID int                   `json:"subscription_id"`
+	EventID        int                   `json:"event_id"`
+	EventType      EventType             `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	LastStatusCode int                   `json:"last_status_code,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	// RedeliveryOf is the delivery this one was redelivered from.
+	RedeliveryOf int        `json:"redelivery_of,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	DeliveredAt  *time.Time `json:"delivered_at"`
+}
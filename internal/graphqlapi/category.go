@@ -0,0 +1,127 @@
+package graphqlapi
+
+import (
+	"context"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"pos-api/internal/domain"
+)
+
+// categoryBatch is a list of categories resolved together. The first
+// category asked for a page of its products loads that page for them all.
+type categoryBatch struct {
+	r          *Resolver
+	categories []domain.Category
+
+	mu    sync.Mutex
+	pages map[page]*productPage
+}
+
+type page struct {
+	limit, offset int
+}
+
+type productPage struct {
+	byCategory map[int][]*productResolver
+	err        error
+}
+
+func (r *Resolver) categoryList(items []domain.Category) []*categoryResolver {
+	b := &categoryBatch{r: r, categories: items, pages: make(map[page]*productPage)}
+	out := make([]*categoryResolver, len(items))
+	for i, c := range items {
+		out[i] = &categoryResolver{c: c, batch: b}
+	}
+	return out
+}
+
+func (b *categoryBatch) products(ctx context.Context, categoryID int, pg page) ([]*productResolver, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	loaded, ok := b.pages[pg]
+	if !ok {
+		loaded = &productPage{}
+		b.pages[pg] = loaded
+
+		ids := make([]int, len(b.categories))
+		for i, c := range b.categories {
+			ids[i] = c.ID
+		}
+		found, err := b.r.products.ListByCategories(ctx, ids, pg.limit, pg.offset)
+		if err != nil {
+			loaded.err = wrapError(err)
+		} else {
+			// The products of all the categories form one list, so their
+			// own categories load together too.
+			var items []domain.Product
+			for _, id := range ids {
+				items = append(items, found[id]...)
+			}
+			loaded.byCategory = make(map[int][]*productResolver, len(found))
+			for _, p := range b.r.productList(items) {
+				loaded.byCategory[p.p.CategoryID] = append(loaded.byCategory[p.p.CategoryID], p)
+			}
+		}
+	}
+	if loaded.err != nil {
+		return nil, loaded.err
+	}
+	if items := loaded.byCategory[categoryID]; items != nil {
+		return items, nil
+	}
+	return []*productResolver{}, nil
+}
+
+type categoryResolver struct {
+	c     domain.Category
+	batch *categoryBatch
+}
+
+func (r *categoryResolver) ID() graphql.ID {
+	return formatID(r.c.ID)
+}
+
+func (r *categoryResolver) Name() string {
+	return r.c.Name
+}
+
+func (r *categoryResolver) Description() string {
+	return r.c.Description
+}
+
+func (r *categoryResolver) Products(ctx context.Context, args struct {
+	Limit  *int32
+	Offset *int32
+}) ([]*productResolver, error) {
+	if err := require(ctx, "products:read"); err != nil {
+		return nil, err
+	}
+	return r.batch.products(ctx, r.c.ID, page{limit: intArg(args.Limit, 50), offset: intArg(args.Offset, 0)})
+}
+
+func (r *categoryResolver) ChangeSeq() int32 {
+	return int32(r.c.ChangeSeq)
+}
+
+func (r *categoryResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.c.CreatedAt}
+}
+
+func (r *categoryResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.c.UpdatedAt}
+}
+
+type categoryInput struct {
+	Name        string
+	Description *string
+}
+
+func (in categoryInput) category() domain.Category {
+	return domain.Category{
+		Name:        in.Name,
+		Description: stringArg(in.Description),
+	}
+}
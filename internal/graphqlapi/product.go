@@ -0,0 +1,291 @@
+package graphqlapi
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"pos-api/internal/domain"
+)
+
+// productBatch is a list of products resolved together. The first product
+// asked for its category loads the categories of them all.
+type productBatch struct {
+	r        *Resolver
+	products []domain.Product
+
+	once       sync.Once
+	categories map[int]*categoryResolver
+	err        error
+}
+
+func (r *Resolver) productList(items []domain.Product) []*productResolver {
+	b := &productBatch{r: r, products: items}
+	out := make([]*productResolver, len(items))
+	for i, p := range items {
+		out[i] = &productResolver{p: p, batch: b}
+	}
+	return out
+}
+
+func (b *productBatch) category(ctx context.Context, id int) (*categoryResolver, error) {
+	b.once.Do(func() {
+		seen := make(map[int]bool)
+		var ids []int
+		for _, p := range b.products {
+			if p.CategoryID != 0 && !seen[p.CategoryID] {
+				seen[p.CategoryID] = true
+				ids = append(ids, p.CategoryID)
+			}
+		}
+
+		found, err := b.r.categories.GetMany(ctx, ids)
+		if err != nil {
+			b.err = wrapError(err)
+			return
+		}
+		items := make([]domain.Category, 0, len(found))
+		for _, c := range found {
+			items = append(items, c)
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+		b.categories = make(map[int]*categoryResolver, len(items))
+		for _, c := range b.r.categoryList(items) {
+			b.categories[c.c.ID] = c
+		}
+	})
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.categories[id], nil
+}
+
+type productResolver struct {
+	p     domain.Product
+	batch *productBatch
+}
+
+func (r *productResolver) ID() graphql.ID {
+	return formatID(r.p.ID)
+}
+
+func (r *productResolver) Name() string {
+	return r.p.Name
+}
+
+func (r *productResolver) SKU() string {
+	return r.p.SKU
+}
+
+func (r *productResolver) Barcodes() []string {
+	if r.p.Barcodes == nil {
+		return []string{}
+	}
+	return r.p.Barcodes
+}
+
+func (r *productResolver) CategoryID() *graphql.ID {
+	if r.p.CategoryID == 0 {
+		return nil
+	}
+	id := formatID(r.p.CategoryID)
+	return &id
+}
+
+func (r *productResolver) Category(ctx context.Context) (*categoryResolver, error) {
+	if r.p.CategoryID == 0 {
+		return nil, nil
+	}
+	if err := require(ctx, "categories:read"); err != nil {
+		return nil, err
+	}
+	return r.batch.category(ctx, r.p.CategoryID)
+}
+
+func (r *productResolver) Price() int32 {
+	return int32(r.p.Price)
+}
+
+func (r *productResolver) Quantity() int32 {
+	return int32(r.p.Quantity)
+}
+
+func (r *productResolver) ReorderPoint() int32 {
+	return int32(r.p.ReorderPoint)
+}
+
+func (r *productResolver) ReorderQuantity() int32 {
+	return int32(r.p.ReorderQuantity)
+}
+
+func (r *productResolver) LowStock() bool {
+	return r.p.IsLowStock()
+}
+
+func (r *productResolver) Options() []*optionResolver {
+	out := make([]*optionResolver, len(r.p.Options))
+	for i, o := range r.p.Options {
+		out[i] = &optionResolver{o: o}
+	}
+	return out
+}
+
+func (r *productResolver) Variants() []*variantResolver {
+	out := make([]*variantResolver, len(r.p.Variants))
+	for i, v := range r.p.Variants {
+		out[i] = &variantResolver{v: v}
+	}
+	return out
+}
+
+func (r *productResolver) ChangeSeq() int32 {
+	return int32(r.p.ChangeSeq)
+}
+
+func (r *productResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.p.CreatedAt}
+}
+
+func (r *productResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.p.UpdatedAt}
+}
+
+type optionResolver struct {
+	o domain.ProductOption
+}
+
+func (r *optionResolver) Name() string {
+	return r.o.Name
+}
+
+func (r *optionResolver) Values() []string {
+	return r.o.Values
+}
+
+type variantResolver struct {
+	v domain.ProductVariant
+}
+
+func (r *variantResolver) ID() graphql.ID {
+	return formatID(r.v.ID)
+}
+
+func (r *variantResolver) SKU() string {
+	return r.v.SKU
+}
+
+// Options lists the variant's option values in name order.
+func (r *variantResolver) Options() []*variantOptionResolver {
+	names := make([]string, 0, len(r.v.Options))
+	for name := range r.v.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]*variantOptionResolver, len(names))
+	for i, name := range names {
+		out[i] = &variantOptionResolver{name: name, value: r.v.Options[name]}
+	}
+	return out
+}
+
+func (r *variantResolver) Price() *int32 {
+	if r.v.Price == nil {
+		return nil
+	}
+	price := int32(*r.v.Price)
+	return &price
+}
+
+func (r *variantResolver) Quantity() int32 {
+	return int32(r.v.Quantity)
+}
+
+type variantOptionResolver struct {
+	name  string
+	value string
+}
+
+func (r *variantOptionResolver) Name() string {
+	return r.name
+}
+
+func (r *variantOptionResolver) Value() string {
+	return r.value
+}
+
+type productInput struct {
+	Name            string
+	SKU             *string
+	Barcodes        *[]string
+	CategoryID      *graphql.ID
+	Price           int32
+	Quantity        *int32
+	ReorderPoint    *int32
+	ReorderQuantity *int32
+	Options         *[]productOptionInput
+	Variants        *[]productVariantInput
+}
+
+type productOptionInput struct {
+	Name   string
+	Values []string
+}
+
+type productVariantInput struct {
+	SKU      *string
+	Options  []variantOptionInput
+	Price    *int32
+	Quantity *int32
+}
+
+type variantOptionInput struct {
+	Name  string
+	Value string
+}
+
+func (in productInput) product() (domain.Product, error) {
+	categoryID, err := parseOptionalID(in.CategoryID)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	p := domain.Product{
+		Name:            in.Name,
+		SKU:             stringArg(in.SKU),
+		CategoryID:      categoryID,
+		Price:           int(in.Price),
+		Quantity:        intArg(in.Quantity, 0),
+		ReorderPoint:    intArg(in.ReorderPoint, 0),
+		ReorderQuantity: intArg(in.ReorderQuantity, 0),
+	}
+	if in.Barcodes != nil {
+		p.Barcodes = *in.Barcodes
+	}
+	if in.Options != nil {
+		for _, o := range *in.Options {
+			p.Options = append(p.Options, domain.ProductOption{Name: o.Name, Values: o.Values})
+		}
+	}
+	if in.Variants != nil {
+		for _, v := range *in.Variants {
+			dv := domain.ProductVariant{
+				SKU:      stringArg(v.SKU),
+				Options:  make(map[string]string, len(v.Options)),
+				Quantity: intArg(v.Quantity, 0),
+			}
+			for _, o := range v.Options {
+				dv.Options[o.Name] = o.Value
+			}
+			if v.Price != nil {
+				price := int(*v.Price)
+				dv.Price = &price
+			}
+			p.Variants = append(p.Variants, dv)
+		}
+	}
+	return p, nil
+}
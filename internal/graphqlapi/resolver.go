@@ -0,0 +1,195 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/service"
+)
+
+// Resolver resolves the Query and Mutation fields.
+type Resolver struct {
+	products   *service.ProductService
+	categories *service.CategoryService
+}
+
+func (r *Resolver) Product(ctx context.Context, args struct{ ID graphql.ID }) (*productResolver, error) {
+	if err := require(ctx, "products:read"); err != nil {
+		return nil, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := r.products.Get(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.productList([]domain.Product{p})[0], nil
+}
+
+func (r *Resolver) ProductByBarcode(ctx context.Context, args struct{ Barcode string }) (*productResolver, error) {
+	if err := require(ctx, "products:read"); err != nil {
+		return nil, err
+	}
+	p, err := r.products.Lookup(ctx, args.Barcode)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.productList([]domain.Product{p})[0], nil
+}
+
+func (r *Resolver) Products(ctx context.Context, args struct {
+	Query      *string
+	CategoryID *graphql.ID
+	Limit      *int32
+	Offset     *int32
+}) ([]*productResolver, error) {
+	if err := require(ctx, "products:read"); err != nil {
+		return nil, err
+	}
+	categoryID, err := parseOptionalID(args.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := r.products.List(ctx, stringArg(args.Query), categoryID, intArg(args.Limit, 50), intArg(args.Offset, 0))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.productList(items), nil
+}
+
+func (r *Resolver) Category(ctx context.Context, args struct{ ID graphql.ID }) (*categoryResolver, error) {
+	if err := require(ctx, "categories:read"); err != nil {
+		return nil, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	c, err := r.categories.Get(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.categoryList([]domain.Category{c})[0], nil
+}
+
+func (r *Resolver) Categories(ctx context.Context, args struct {
+	Limit  *int32
+	Offset *int32
+}) ([]*categoryResolver, error) {
+	if err := require(ctx, "categories:read"); err != nil {
+		return nil, err
+	}
+	items, err := r.categories.List(ctx, intArg(args.Limit, 50), intArg(args.Offset, 0))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.categoryList(items), nil
+}
+
+func (r *Resolver) CreateProduct(ctx context.Context, args struct{ Input productInput }) (*productResolver, error) {
+	if err := require(ctx, "products:write"); err != nil {
+		return nil, err
+	}
+	in, err := args.Input.product()
+	if err != nil {
+		return nil, err
+	}
+	p, err := r.products.Create(ctx, in)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.productList([]domain.Product{p})[0], nil
+}
+
+func (r *Resolver) UpdateProduct(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input productInput
+}) (*productResolver, error) {
+	if err := require(ctx, "products:write"); err != nil {
+		return nil, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	in, err := args.Input.product()
+	if err != nil {
+		return nil, err
+	}
+	p, err := r.products.Update(ctx, id, in)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.productList([]domain.Product{p})[0], nil
+}
+
+func (r *Resolver) DeleteProduct(ctx context.Context, args struct{ ID graphql.ID }) (graphql.ID, error) {
+	if err := require(ctx, "products:write"); err != nil {
+		return "", err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return "", err
+	}
+	if err := r.products.Delete(ctx, id); err != nil {
+		return "", wrapError(err)
+	}
+	return args.ID, nil
+}
+
+func (r *Resolver) CreateCategory(ctx context.Context, args struct{ Input categoryInput }) (*categoryResolver, error) {
+	if err := require(ctx, "categories:write"); err != nil {
+		return nil, err
+	}
+	c, err := r.categories.Create(ctx, args.Input.category())
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.categoryList([]domain.Category{c})[0], nil
+}
+
+func (r *Resolver) UpdateCategory(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input categoryInput
+}) (*categoryResolver, error) {
+	if err := require(ctx, "categories:write"); err != nil {
+		return nil, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	c, err := r.categories.Update(ctx, id, args.Input.category())
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.categoryList([]domain.Category{c})[0], nil
+}
+
+func (r *Resolver) DeleteCategory(ctx context.Context, args struct{ ID graphql.ID }) (graphql.ID, error) {
+	if err := require(ctx, "categories:write"); err != nil {
+		return "", err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return "", err
+	}
+	if err := r.categories.Delete(ctx, id); err != nil {
+		return "", wrapError(err)
+	}
+	return args.ID, nil
+}
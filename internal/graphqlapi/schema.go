@@ -0,0 +1,116 @@
+// Package graphqlapi serves the catalog over GraphQL, alongside the REST API
+// and through the same services. The schema is in schema.graphql.
+//
+// Nested fields are loaded for a whole list at once: the categories of every
+// product in a list take one query, as do the products of every category in
+// a list, rather than one query per item.
+package graphqlapi
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"pos-api/internal/auth"
+	"pos-api/internal/domain"
+	"pos-api/internal/service"
+)
+
+//go:embed schema.graphql
+var schemaSource string
+
+// maxDepth bounds how deeply a query may nest, as products and categories
+// nest inside each other without end.
+const maxDepth = 8
+
+// NewSchema returns the executable schema for the product and category
+// services.
+func NewSchema(products *service.ProductService, categories *service.CategoryService) *graphql.Schema {
+	return graphql.MustParseSchema(schemaSource, &Resolver{products: products, categories: categories}, graphql.MaxDepth(maxDepth))
+}
+
+// Error codes, in the extensions of each error, matching the REST status
+// codes.
+const (
+	codeBadUserInput = "BAD_USER_INPUT"
+	codeNotFound     = "NOT_FOUND"
+	codeConflict     = "CONFLICT"
+	codeForbidden    = "FORBIDDEN"
+	codeInternal     = "INTERNAL"
+)
+
+type resolverError struct {
+	err  error
+	code string
+}
+
+func (e resolverError) Error() string {
+	return e.err.Error()
+}
+
+func (e resolverError) Extensions() map[string]any {
+	return map[string]any{"code": e.code}
+}
+
+// wrapError tags a service error with the code for its kind.
+func wrapError(err error) error {
+	code := codeInternal
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		code = codeNotFound
+	case errors.Is(err, domain.ErrInvalid):
+		code = codeBadUserInput
+	case errors.Is(err, domain.ErrConflict):
+		code = codeConflict
+	case errors.Is(err, domain.ErrForbidden):
+		code = codeForbidden
+	}
+	return resolverError{err: err, code: code}
+}
+
+// require fails when the request's API key does not have scope.
+func require(ctx context.Context, scope string) error {
+	if err := auth.Require(ctx, scope); err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+func parseID(id graphql.ID) (int, error) {
+	n, err := strconv.Atoi(string(id))
+	if err != nil || n <= 0 {
+		return 0, wrapError(fmt.Errorf("%w: invalid id %q", domain.ErrInvalid, id))
+	}
+	return n, nil
+}
+
+// parseOptionalID reads an ID that may be left out, as zero.
+func parseOptionalID(id *graphql.ID) (int, error) {
+	if id == nil {
+		return 0, nil
+	}
+	return parseID(*id)
+}
+
+func formatID(id int) graphql.ID {
+	return graphql.ID(strconv.Itoa(id))
+}
+
+// intArg reads an optional Int argument, or def when it is null.
+func intArg(v *int32, def int) int {
+	if v == nil {
+		return def
+	}
+	return int(*v)
+}
+
+func stringArg(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
@@ -0,0 +1,67 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"pos-api/internal/grpcapi/posv1"
+	"pos-api/internal/service"
+)
+
+type CategoryServer struct {
+	posv1.UnimplementedCategoryServiceServer
+	svc *service.CategoryService
+}
+
+func NewCategoryServer(s *service.CategoryService) *CategoryServer {
+	return &CategoryServer{svc: s}
+}
+
+func (s *CategoryServer) GetCategory(ctx context.Context, req *posv1.GetCategoryRequest) (*posv1.Category, error) {
+	c, err := s.svc.Get(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, err
+	}
+	return categoryToProto(c), nil
+}
+
+func (s *CategoryServer) ListCategories(ctx context.Context, req *posv1.ListCategoriesRequest) (*posv1.ListCategoriesResponse, error) {
+	limit := req.GetLimit()
+	if limit == 0 {
+		limit = 50
+	}
+	items, err := s.svc.List(ctx, int(limit), int(req.GetOffset()))
+	if err != nil {
+		return nil, err
+	}
+
+	out := &posv1.ListCategoriesResponse{Limit: limit, Offset: req.GetOffset()}
+	for _, c := range items {
+		out.Items = append(out.Items, categoryToProto(c))
+	}
+	return out, nil
+}
+
+func (s *CategoryServer) CreateCategory(ctx context.Context, req *posv1.CreateCategoryRequest) (*posv1.Category, error) {
+	c, err := s.svc.Create(ctx, categoryFromProto(req.GetCategory()))
+	if err != nil {
+		return nil, err
+	}
+	return categoryToProto(c), nil
+}
+
+func (s *CategoryServer) UpdateCategory(ctx context.Context, req *posv1.UpdateCategoryRequest) (*posv1.Category, error) {
+	c, err := s.svc.Update(ctx, int(req.GetId()), categoryFromProto(req.GetCategory()))
+	if err != nil {
+		return nil, err
+	}
+	return categoryToProto(c), nil
+}
+
+func (s *CategoryServer) DeleteCategory(ctx context.Context, req *posv1.DeleteCategoryRequest) (*emptypb.Empty, error) {
+	if err := s.svc.Delete(ctx, int(req.GetId())); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,94 @@
+package grpcapi
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/grpcapi/posv1"
+)
+
+func productToProto(p domain.Product) *posv1.Product {
+	out := &posv1.Product{
+		Id:              int64(p.ID),
+		Name:            p.Name,
+		Sku:             p.SKU,
+		Barcodes:        p.Barcodes,
+		CategoryId:      int64(p.CategoryID),
+		Price:           int64(p.Price),
+		Quantity:        int64(p.Quantity),
+		ReorderPoint:    int64(p.ReorderPoint),
+		ReorderQuantity: int64(p.ReorderQuantity),
+		ChangeSeq:       int64(p.ChangeSeq),
+		CreatedAt:       timestamppb.New(p.CreatedAt),
+		UpdatedAt:       timestamppb.New(p.UpdatedAt),
+	}
+	for _, o := range p.Options {
+		out.Options = append(out.Options, &posv1.ProductOption{Name: o.Name, Values: o.Values})
+	}
+	for _, v := range p.Variants {
+		pv := &posv1.ProductVariant{
+			Id:        int64(v.ID),
+			ProductId: int64(v.ProductID),
+			Sku:       v.SKU,
+			Options:   v.Options,
+			Quantity:  int64(v.Quantity),
+		}
+		if v.Price != nil {
+			price := int64(*v.Price)
+			pv.Price = &price
+		}
+		out.Variants = append(out.Variants, pv)
+	}
+	return out
+}
+
+// productFromProto reads the fields a client may set; the ID, change
+// number and timestamps are ignored.
+func productFromProto(p *posv1.Product) domain.Product {
+	out := domain.Product{
+		Name:            p.GetName(),
+		SKU:             p.GetSku(),
+		Barcodes:        p.GetBarcodes(),
+		CategoryID:      int(p.GetCategoryId()),
+		Price:           int(p.GetPrice()),
+		Quantity:        int(p.GetQuantity()),
+		ReorderPoint:    int(p.GetReorderPoint()),
+		ReorderQuantity: int(p.GetReorderQuantity()),
+	}
+	for _, o := range p.GetOptions() {
+		out.Options = append(out.Options, domain.ProductOption{Name: o.GetName(), Values: o.GetValues()})
+	}
+	for _, v := range p.GetVariants() {
+		dv := domain.ProductVariant{
+			ID:       int(v.GetId()),
+			SKU:      v.GetSku(),
+			Options:  v.GetOptions(),
+			Quantity: int(v.GetQuantity()),
+		}
+		if v.Price != nil {
+			price := int(v.GetPrice())
+			dv.Price = &price
+		}
+		out.Variants = append(out.Variants, dv)
+	}
+	return out
+}
+
+func categoryToProto(c domain.Category) *posv1.Category {
+	return &posv1.Category{
+		Id:          int64(c.ID),
+		Name:        c.Name,
+		Description: c.Description,
+		ChangeSeq:   int64(c.ChangeSeq),
+		CreatedAt:   timestamppb.New(c.CreatedAt),
+		UpdatedAt:   timestamppb.New(c.UpdatedAt),
+	}
+}
+
+// categoryFromProto reads the fields a client may set.
+func categoryFromProto(c *posv1.Category) domain.Category {
+	return domain.Category{
+		Name:        c.GetName(),
+		Description: c.GetDescription(),
+	}
+}
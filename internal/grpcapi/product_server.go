@@ -0,0 +1,75 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"pos-api/internal/grpcapi/posv1"
+	"pos-api/internal/service"
+)
+
+type ProductServer struct {
+	posv1.UnimplementedProductServiceServer
+	svc *service.ProductService
+}
+
+func NewProductServer(s *service.ProductService) *ProductServer {
+	return &ProductServer{svc: s}
+}
+
+func (s *ProductServer) GetProduct(ctx context.Context, req *posv1.GetProductRequest) (*posv1.Product, error) {
+	p, err := s.svc.Get(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, err
+	}
+	return productToProto(p), nil
+}
+
+func (s *ProductServer) LookupProduct(ctx context.Context, req *posv1.LookupProductRequest) (*posv1.Product, error) {
+	p, err := s.svc.Lookup(ctx, req.GetBarcode())
+	if err != nil {
+		return nil, err
+	}
+	return productToProto(p), nil
+}
+
+func (s *ProductServer) ListProducts(ctx context.Context, req *posv1.ListProductsRequest) (*posv1.ListProductsResponse, error) {
+	limit := req.GetLimit()
+	if limit == 0 {
+		limit = 50
+	}
+	items, err := s.svc.List(ctx, req.GetQuery(), int(req.GetCategoryId()), int(limit), int(req.GetOffset()))
+	if err != nil {
+		return nil, err
+	}
+
+	out := &posv1.ListProductsResponse{Limit: limit, Offset: req.GetOffset()}
+	for _, p := range items {
+		out.Items = append(out.Items, productToProto(p))
+	}
+	return out, nil
+}
+
+func (s *ProductServer) CreateProduct(ctx context.Context, req *posv1.CreateProductRequest) (*posv1.Product, error) {
+	p, err := s.svc.Create(ctx, productFromProto(req.GetProduct()))
+	if err != nil {
+		return nil, err
+	}
+	return productToProto(p), nil
+}
+
+func (s *ProductServer) UpdateProduct(ctx context.Context, req *posv1.UpdateProductRequest) (*posv1.Product, error) {
+	p, err := s.svc.Update(ctx, int(req.GetId()), productFromProto(req.GetProduct()))
+	if err != nil {
+		return nil, err
+	}
+	return productToProto(p), nil
+}
+
+func (s *ProductServer) DeleteProduct(ctx context.Context, req *posv1.DeleteProductRequest) (*emptypb.Empty, error) {
+	if err := s.svc.Delete(ctx, int(req.GetId())); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,188 @@
+// Package grpcapi serves the catalog over gRPC, alongside the REST API and
+// through the same services. The messages and services are defined in
+// proto/pos/v1 and generated into posv1.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"pos-api/internal/actor"
+	"pos-api/internal/auth"
+	"pos-api/internal/domain"
+	"pos-api/internal/grpcapi/posv1"
+	"pos-api/internal/service"
+	"pos-api/internal/tenant"
+)
+
+// Metadata keys naming who makes a change and why, matching the REST
+// headers. They are taken on trust.
+const (
+	actorKey     = "x-actor"
+	reasonKey    = "x-change-reason"
+	requestIDKey = "x-request-id"
+	tenantKey    = "x-tenant-id"
+	authKey      = "authorization"
+)
+
+// serviceResources maps each service to the resource its API key scopes
+// name.
+var serviceResources = map[string]string{
+	"pos.v1.ProductService":  "products",
+	"pos.v1.CategoryService": "categories",
+}
+
+// NewServer returns a gRPC server for the product and category services,
+// with reflection registered for tools such as grpcurl. Calls work for the
+// tenant their x-tenant-id metadata names, through resolve, or for fallback
+// when they name none. An API key in the authorization metadata is checked
+// with authenticate, and is needed when required is set.
+func NewServer(products *service.ProductService, categories *service.CategoryService, resolve tenant.Resolver, fallback string, authenticate auth.Authenticator, required bool) *grpc.Server {
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(withActor, withStatus, withAPIKey(authenticate, required), withTenant(resolve, fallback)))
+	posv1.RegisterProductServiceServer(s, NewProductServer(products))
+	posv1.RegisterCategoryServiceServer(s, NewCategoryServer(categories))
+	reflection.Register(s)
+	return s
+}
+
+// withActor puts the actor, reason and request ID metadata of each call into
+// its context, along with the client address, as httputil.WithActor does for
+// REST. The request ID is sent back in the response header.
+func withActor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return strings.TrimSpace(v[0])
+		}
+		return ""
+	}
+
+	a := actor.Actor{
+		Name:      first(actorKey),
+		Reason:    first(reasonKey),
+		RequestID: actor.RequestID(first(requestIDKey)),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		a.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(a.IP); err == nil {
+			a.IP = host
+		}
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, a.RequestID))
+	return handler(actor.With(ctx, a), req)
+}
+
+// withAPIKey authenticates calls with a Bearer API key in their
+// authorization metadata and holds them to its scopes, as
+// httputil.WithAPIKey does for REST: Get, List and Lookup calls take the
+// read scope of their service's resource, and the others its write scope.
+func withAPIKey(authenticate auth.Authenticator, required bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var header string
+		if v := md.Get(authKey); len(v) > 0 {
+			header = strings.TrimSpace(v[0])
+		}
+		if header == "" {
+			if required {
+				return nil, status.Error(codes.Unauthenticated, "an API key is required")
+			}
+			return handler(ctx, req)
+		}
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "the authorization metadata must be Bearer followed by an API key")
+		}
+
+		key, err := authenticate(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		svc, method, _ := strings.Cut(strings.TrimPrefix(info.FullMethod, "/"), "/")
+		access := "write"
+		if strings.HasPrefix(method, "Get") || strings.HasPrefix(method, "List") || strings.HasPrefix(method, "Lookup") {
+			access = "read"
+		}
+		if resource, ok := serviceResources[svc]; !ok || !key.Allows(resource+":"+access) {
+			return nil, status.Errorf(codes.PermissionDenied, "the API key may not call %s", info.FullMethod)
+		}
+
+		ctx = auth.With(ctx, key)
+		ctx = tenant.With(ctx, key.TenantID)
+		ctx = actor.With(ctx, actor.From(ctx).WithAPIKey(key.Prefix))
+		return handler(ctx, req)
+	}
+}
+
+// withTenant puts the tenant of each call into its context, as
+// httputil.WithTenant does for REST: a call with an API key works for the
+// key's tenant, and may only name that one. A call naming no tenant, when
+// there is no fallback, fails with InvalidArgument.
+func withTenant(resolve tenant.Resolver, fallback string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		fixed := tenant.From(ctx)
+		md, _ := metadata.FromIncomingContext(ctx)
+		ref := strings.TrimSpace(fallback)
+		if fixed != 0 {
+			ref = strconv.Itoa(fixed)
+		}
+		if v := md.Get(tenantKey); len(v) > 0 && strings.TrimSpace(v[0]) != "" {
+			ref = strings.TrimSpace(v[0])
+		}
+		if ref == "" {
+			return nil, status.Error(codes.InvalidArgument, "the "+tenantKey+" metadata is required")
+		}
+
+		id, err := resolve(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		if fixed != 0 && id != fixed {
+			return nil, status.Error(codes.PermissionDenied, "the API key belongs to another tenant")
+		}
+		return handler(tenant.With(ctx, id), req)
+	}
+}
+
+// withStatus turns domain errors into the codes matching the REST status
+// codes: InvalidArgument for 400, Unauthenticated for 401, PermissionDenied
+// for 403 and NotFound for 404. A 409 is AlreadyExists when something unique
+// is taken and FailedPrecondition when the state of what is changed forbids
+// it, such as a closed shift or too little stock. Anything else is
+// Internal, as it is a 500 over REST.
+func withStatus(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return nil, err
+	}
+
+	code := codes.Internal
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, domain.ErrInvalid):
+		code = codes.InvalidArgument
+	case errors.Is(err, domain.ErrDuplicate):
+		code = codes.AlreadyExists
+	case errors.Is(err, domain.ErrConflict):
+		code = codes.FailedPrecondition
+	case errors.Is(err, domain.ErrForbidden):
+		code = codes.PermissionDenied
+	case errors.Is(err, domain.ErrUnauthorized):
+		code = codes.Unauthenticated
+	}
+	return nil, status.Error(code, err.Error())
+}
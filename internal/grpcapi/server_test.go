@@ -0,0 +1,38 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"pos-api/internal/domain"
+)
+
+func TestWithStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{fmt.Errorf("%w: product 1", domain.ErrNotFound), codes.NotFound},
+		{fmt.Errorf("%w: name is required", domain.ErrInvalid), codes.InvalidArgument},
+		{fmt.Errorf("%w: sku %q is already in use", domain.ErrDuplicate, "TEA"), codes.AlreadyExists},
+		{fmt.Errorf("%w: shift 1 is closed", domain.ErrConflict), codes.FailedPrecondition},
+		{domain.ErrForbidden, codes.PermissionDenied},
+		{domain.ErrUnauthorized, codes.Unauthenticated},
+		{errors.New("connection refused"), codes.Internal},
+		{status.Error(codes.Unavailable, "draining"), codes.Unavailable},
+	}
+	for _, tt := range tests {
+		_, err := withStatus(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+			return nil, tt.err
+		})
+		if got := status.Code(err); got != tt.want {
+			t.Errorf("%v: code %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/http/httputil"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/service"
+)
+
+type APIKeyHandler struct {
+	svc *service.APIKeyService
+}
+
+func NewAPIKeyHandler(s *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{svc: s}
+}
+
+func (h *APIKeyHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	limit := httputil.QueryInt(r, "limit", 50)
+	offset := httputil.QueryInt(r, "offset", 0)
+
+	items, err := h.svc.List(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, map[string]any{
+		"items":  items,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func (h *APIKeyHandler) GetAPIKeyByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/api-keys/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	k, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, k)
+}
+
+// CreateAPIKey issues a key. The response is the only place the key itself
+// is ever shown.
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var in domain.APIKey
+	if err := httputil.DecodeJSON(w, r, &in); err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+
+	created, err := h.svc.Create(r.Context(), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, created)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	k, err := h.svc.Revoke(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, k)
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"pos-api/internal/http/httputil"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/service"
+)
+
+type AuditHandler struct {
+	svc *service.AuditService
+}
+
+func NewAuditHandler(s *service.AuditService) *AuditHandler {
+	return &AuditHandler{svc: s}
+}
+
+func (h *AuditHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := httputil.QueryInt(r, "limit", 50)
+	offset := httputil.QueryInt(r, "offset", 0)
+
+	items, err := h.svc.List(r.Context(), q.Get("entity"), httputil.QueryInt(r, "entity_id", 0), q.Get("actor"), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, map[string]any{
+		"items":  items,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
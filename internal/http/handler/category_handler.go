@@ -25,7 +25,7 @@ func (h *CategoryHandler) GetCategories(w http.ResponseWriter, r *http.Request)
 
 	items, err := h.svc.List(r.Context(), limit, offset)
 	if err != nil {
-		responder.Error(w, http.StatusInternalServerError, err.Error())
+		writeError(w, err)
 		return
 	}
 
@@ -36,6 +36,21 @@ func (h *CategoryHandler) GetCategories(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+var categoryExport = exportTable[domain.Category]{
+	name:   "categories",
+	header: []string{"id", "name", "description", "created_at", "updated_at"},
+	row: func(c domain.Category) []any {
+		return []any{c.ID, c.Name, c.Description, c.CreatedAt, c.UpdatedAt}
+	},
+}
+
+// ExportCategories streams every category.
+func (h *CategoryHandler) ExportCategories(w http.ResponseWriter, r *http.Request) {
+	exportRecords(w, r, categoryExport, func(fn func(domain.Category) error) error {
+		return h.svc.Export(r.Context(), fn)
+	})
+}
+
 func (h *CategoryHandler) GetCategoryByID(w http.ResponseWriter, r *http.Request) {
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/categories/")
 	id, err := strconv.Atoi(idStr)
@@ -46,7 +61,7 @@ func (h *CategoryHandler) GetCategoryByID(w http.ResponseWriter, r *http.Request
 
 	p, err := h.svc.Get(r.Context(), id)
 	if err != nil {
-		responder.Error(w, http.StatusInternalServerError, err.Error())
+		writeError(w, err)
 		return
 	}
 
@@ -62,7 +77,7 @@ func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 
 	created, err := h.svc.Create(r.Context(), in)
 	if err != nil {
-		responder.Error(w, http.StatusInternalServerError, err.Error())
+		writeError(w, err)
 		return
 	}
 
@@ -85,7 +100,7 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 
 	updated, err := h.svc.Update(r.Context(), id, in)
 	if err != nil {
-		responder.Error(w, http.StatusInternalServerError, err.Error())
+		writeError(w, err)
 		return
 	}
 	responder.Success(w, updated)
@@ -100,7 +115,7 @@ func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := h.svc.Delete(r.Context(), id); err != nil {
-		responder.Error(w, http.StatusInternalServerError, err.Error())
+		writeError(w, err)
 		return
 	}
 	responder.Success(w, map[string]any{"deleted": true})
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/http/responder"
+)
+
+// writeError maps domain errors to HTTP status codes.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, domain.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, domain.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, domain.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	}
+	responder.Error(w, status, err.Error())
+}
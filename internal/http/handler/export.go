@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"pos-api/internal/http/responder"
+	"pos-api/internal/spreadsheet"
+)
+
+// exportBuffer is how much of an export is held back before the response is
+// committed; an error within it still gets a proper error response.
+const exportBuffer = 32 << 10
+
+// exportTable describes how records of type T become spreadsheet rows.
+type exportTable[T any] struct {
+	name   string
+	header []string
+	row    func(T) []any
+}
+
+// exportRecords streams the records produced by each in the format named by
+// the format query parameter: csv (the default), jsonl or xlsx. CSV and XLSX
+// use the table's columns; JSONL writes each record as its JSON form.
+func exportRecords[T any](w http.ResponseWriter, r *http.Request, t exportTable[T], each func(fn func(T) error) error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	contentType := map[string]string{
+		"csv":   spreadsheet.ContentTypes[spreadsheet.CSV],
+		"jsonl": "application/jsonl",
+		"xlsx":  spreadsheet.ContentTypes[spreadsheet.XLSX],
+	}[format]
+	if contentType == "" {
+		responder.Error(w, http.StatusBadRequest, "format must be csv, jsonl or xlsx")
+		return
+	}
+
+	out := &committedWriter{w: w}
+	buf := bufio.NewWriterSize(out, exportBuffer)
+
+	var write func(T) error
+	var sheet spreadsheet.Writer
+	if format == "jsonl" {
+		enc := json.NewEncoder(buf)
+		write = func(v T) error { return enc.Encode(v) }
+	} else {
+		sheet = spreadsheet.NewWriter(buf, spreadsheet.Format(format))
+		write = func(v T) error { return sheet.WriteRow(t.row(v)) }
+		if err := sheet.WriteRow(stringCells(t.header)); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.%s"`, t.name, time.Now().UTC().Format("20060102"), format))
+
+	err := each(write)
+	if err == nil && sheet != nil {
+		err = sheet.Close()
+	}
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		if !out.committed {
+			w.Header().Del("Content-Disposition")
+			writeError(w, err)
+			return
+		}
+		// The status line has gone out; all we can do is cut the file short.
+		log.Printf("export %s: %v", t.name, err)
+	}
+}
+
+// committedWriter records whether anything has been written to the response.
+type committedWriter struct {
+	w         http.ResponseWriter
+	committed bool
+}
+
+func (c *committedWriter) Write(p []byte) (int, error) {
+	c.committed = true
+	return c.w.Write(p)
+}
+
+func stringCells(values []string) []any {
+	cells := make([]any, len(values))
+	for i, v := range values {
+		cells[i] = v
+	}
+	return cells
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/service"
+	"pos-api/internal/spreadsheet"
+)
+
+// importMaxBytes limits the size of an uploaded import file.
+const importMaxBytes = 32 << 20
+
+type ImportHandler struct {
+	svc *service.ImportService
+}
+
+func NewImportHandler(s *service.ImportService) *ImportHandler {
+	return &ImportHandler{svc: s}
+}
+
+func (h *ImportHandler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	h.importFile(w, r, domain.ImportProducts)
+}
+
+func (h *ImportHandler) ImportCategories(w http.ResponseWriter, r *http.Request) {
+	h.importFile(w, r, domain.ImportCategories)
+}
+
+func (h *ImportHandler) GetImportJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	job, err := h.svc.Job(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, job)
+}
+
+// importFile reads the file from a multipart "file" field or from the raw
+// request body. Small files are imported straight away; large ones, or any
+// file when async is set, start a background job.
+func (h *ImportHandler) importFile(w http.ResponseWriter, r *http.Request, kind domain.ImportKind) {
+	r.Body = http.MaxBytesReader(w, r.Body, importMaxBytes)
+
+	var data []byte
+	var filename, contentType string
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+		if err := r.ParseMultipartForm(8 << 20); err != nil {
+			responder.Error(w, http.StatusBadRequest, "invalid upload: "+err.Error())
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			responder.Error(w, http.StatusBadRequest, "file is required")
+			return
+		}
+		defer file.Close()
+		filename, contentType = header.Filename, header.Header.Get("Content-Type")
+		data, err = io.ReadAll(file)
+		if err != nil {
+			responder.Error(w, http.StatusBadRequest, "invalid upload: "+err.Error())
+			return
+		}
+	} else {
+		var err error
+		contentType = r.Header.Get("Content-Type")
+		data, err = io.ReadAll(r.Body)
+		if err != nil {
+			responder.Error(w, http.StatusBadRequest, "invalid upload: "+err.Error())
+			return
+		}
+	}
+
+	param := func(key string) string {
+		if r.MultipartForm != nil {
+			return r.FormValue(key)
+		}
+		return r.URL.Query().Get(key)
+	}
+	dryRun, err := parseFlag(param("dry_run"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid dry_run")
+		return
+	}
+	async, err := parseFlag(param("async"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid async")
+		return
+	}
+	var mapping map[string]string
+	if v := param("mapping"); v != "" {
+		if err := json.Unmarshal([]byte(v), &mapping); err != nil {
+			responder.Error(w, http.StatusBadRequest, "invalid mapping: "+err.Error())
+			return
+		}
+	}
+
+	format, err := spreadsheet.DetectFormat(param("format"), filename, contentType, data)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	rows, err := spreadsheet.Read(data, format)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if async || len(rows)-1 > service.ImportAsyncRows {
+		job, err := h.svc.Start(r.Context(), kind, rows, mapping, dryRun)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Location", fmt.Sprintf("/api/imports/%d", job.ID))
+		responder.JSON(w, http.StatusAccepted, responder.SuccessResponse{Success: true, Data: job})
+		return
+	}
+
+	res, err := h.svc.Run(r.Context(), kind, rows, mapping, dryRun)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !res.Applied && !res.DryRun {
+		responder.JSON(w, http.StatusUnprocessableEntity, responder.SuccessResponse{Success: false, Data: res})
+		return
+	}
+	responder.Success(w, res)
+}
+
+// parseFlag reads an optional boolean parameter.
+func parseFlag(v string) (bool, error) {
+	if v == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/http/httputil"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/service"
+)
+
+type InventoryHandler struct {
+	svc *service.InventoryService
+}
+
+func NewInventoryHandler(s *service.InventoryService) *InventoryHandler {
+	return &InventoryHandler{svc: s}
+}
+
+func (h *InventoryHandler) GetStock(w http.ResponseWriter, r *http.Request) {
+	locationID := httputil.QueryInt(r, "location_id", 0)
+	productID := httputil.QueryInt(r, "product_id", 0)
+	limit := httputil.QueryInt(r, "limit", 50)
+	offset := httputil.QueryInt(r, "offset", 0)
+
+	items, err := h.svc.ListStock(r.Context(), locationID, productID, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, map[string]any{
+		"items":  items,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func (h *InventoryHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	var in domain.StockChange
+	if err := httputil.DecodeJSON(w, r, &in); err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+	in.LocationID = httputil.QueryInt(r, "location_id", in.LocationID)
+
+	p, err := h.svc.Adjust(r.Context(), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, p)
+}
+
+func (h *InventoryHandler) GetProductStock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	items, err := h.svc.ProductStock(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, map[string]any{"items": items})
+}
+
+func (h *InventoryHandler) GetProductCostLayers(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	items, err := h.svc.CostLayers(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, map[string]any{"items": items})
+}
+
+func (h *InventoryHandler) GetTransfers(w http.ResponseWriter, r *http.Request) {
+	limit := httputil.QueryInt(r, "limit", 50)
+	offset := httputil.QueryInt(r, "offset", 0)
+
+	items, err := h.svc.ListTransfers(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, map[string]any{
+		"items":  items,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func (h *InventoryHandler) GetTransferByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/inventory/transfers/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	t, err := h.svc.GetTransfer(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, t)
+}
+
+func (h *InventoryHandler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	var in domain.StockTransfer
+	if err := httputil.DecodeJSON(w, r, &in); err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+
+	created, err := h.svc.CreateTransfer(r.Context(), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, created)
+}
+
+func (h *InventoryHandler) ReceiveTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	t, err := h.svc.ReceiveTransfer(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, t)
+}
+
+func (h *InventoryHandler) CancelTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	t, err := h.svc.CancelTransfer(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, t)
+}
+
+func (h *InventoryHandler) GetLowStock(w http.ResponseWriter, r *http.Request) {
+	limit := httputil.QueryInt(r, "limit", 50)
+	offset := httputil.QueryInt(r, "offset", 0)
+
+	items, err := h.svc.LowStock(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, map[string]any{
+		"items":  items,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func (h *InventoryHandler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	responder.Success(w, map[string]any{"items": h.svc.Alerts(r.Context())})
+}
+
+func (h *InventoryHandler) GetReorderSuggestions(w http.ResponseWriter, r *http.Request) {
+	items, err := h.svc.ReorderSuggestions(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, map[string]any{"items": items})
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/http/httputil"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/service"
+)
+
+type LocationHandler struct {
+	svc *service.LocationService
+}
+
+func NewLocationHandler(s *service.LocationService) *LocationHandler {
+	return &LocationHandler{svc: s}
+}
+
+func (h *LocationHandler) GetLocations(w http.ResponseWriter, r *http.Request) {
+	limit := httputil.QueryInt(r, "limit", 50)
+	offset := httputil.QueryInt(r, "offset", 0)
+
+	items, err := h.svc.List(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, map[string]any{
+		"items":  items,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func (h *LocationHandler) GetLocationByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/locations/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	l, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, l)
+}
+
+func (h *LocationHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	var in domain.Location
+	if err := httputil.DecodeJSON(w, r, &in); err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+
+	created, err := h.svc.Create(r.Context(), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, created)
+}
+
+func (h *LocationHandler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/locations/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var in domain.Location
+	if err := httputil.DecodeJSON(w, r, &in); err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+
+	updated, err := h.svc.Update(r.Context(), id, in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, updated)
+}
+
+func (h *LocationHandler) DeleteLocation(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/locations/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, map[string]any{"deleted": true})
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/http/httputil"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/receipt"
+	"pos-api/internal/service"
+)
+
+type OrderHandler struct {
+	svc *service.OrderService
+}
+
+func NewOrderHandler(s *service.OrderService) *OrderHandler {
+	return &OrderHandler{svc: s}
+}
+
+func (h *OrderHandler) GetOrders(w http.ResponseWriter, r *http.Request) {
+	limit := httputil.QueryInt(r, "limit", 50)
+	offset := httputil.QueryInt(r, "offset", 0)
+
+	items, err := h.svc.List(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, map[string]any{
+		"items":  items,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func (h *OrderHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/orders/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	o, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, o)
+}
+
+func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	var in domain.Order
+	if err := httputil.DecodeJSON(w, r, &in); err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+
+	created, err := h.svc.Create(r.Context(), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, created)
+}
+
+func (h *OrderHandler) GetOrderReceipt(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	format, err := receipt.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	paper, err := receipt.ParsePaper(r.URL.Query().Get("paper"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	o, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// The tenant's store details make the header and footer.
+	set, err := h.svc.Settings(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	store := receipt.Store{
+		Name:     set.StoreName,
+		Address:  set.StoreAddress,
+		Phone:    set.StorePhone,
+		Footer:   set.ReceiptFooter,
+		Location: set.Location,
+	}
+
+	// Render into a buffer so a failure can still be reported as JSON.
+	var buf bytes.Buffer
+	if err := receipt.Render(&buf, store, o, format, paper); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	if format == receipt.FormatESCPOS {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="receipt-%d.bin"`, o.ID))
+	}
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/http/httputil"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/service"
+)
+
+type PriceHandler struct {
+	svc *service.PriceService
+}
+
+func NewPriceHandler(s *service.PriceService) *PriceHandler {
+	return &PriceHandler{svc: s}
+}
+
+func (h *PriceHandler) GetProductPrices(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	prices, err := h.svc.Prices(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, prices)
+}
+
+func (h *PriceHandler) SchedulePrice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var in struct {
+		Price         int       `json:"price"`
+		EffectiveFrom time.Time `json:"effective_from"`
+		Reason        string    `json:"reason"`
+	}
+	if err := httputil.DecodeJSON(w, r, &in); err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+
+	created, err := h.svc.Schedule(r.Context(), id, domain.ScheduledPrice{
+		Price:         in.Price,
+		EffectiveFrom: in.EffectiveFrom,
+		Reason:        in.Reason,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, created)
+}
+
+func (h *PriceHandler) CancelScheduledPrice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	priceID, err := strconv.Atoi(r.PathValue("priceID"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid scheduled price id")
+		return
+	}
+
+	cancelled, err := h.svc.Cancel(r.Context(), id, priceID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, cancelled)
+}
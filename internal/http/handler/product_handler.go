@@ -23,9 +23,11 @@ func (h *ProductHandler) GetProducts(w http.ResponseWriter, r *http.Request) {
 	limit := httputil.QueryInt(r, "limit", 50)
 	offset := httputil.QueryInt(r, "offset", 0)
 
-	items, err := h.svc.List(r.Context(), limit, offset)
+	categoryID := httputil.QueryInt(r, "category_id", 0)
+
+	items, err := h.svc.List(r.Context(), r.URL.Query().Get("q"), categoryID, limit, offset)
 	if err != nil {
-		responder.Error(w, http.StatusInternalServerError, err.Error())
+		writeError(w, err)
 		return
 	}
 
@@ -36,6 +38,29 @@ func (h *ProductHandler) GetProducts(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// productExport lays products out with the columns the import understands.
+var productExport = exportTable[domain.Product]{
+	name:   "products",
+	header: []string{"id", "name", "sku", "barcodes", "category_id", "price", "quantity", "reorder_point", "reorder_quantity", "created_at", "updated_at"},
+	row: func(p domain.Product) []any {
+		var category any
+		if p.CategoryID != 0 {
+			category = p.CategoryID
+		}
+		return []any{p.ID, p.Name, p.SKU, strings.Join(p.Barcodes, "|"), category, p.Price, p.Quantity, p.ReorderPoint, p.ReorderQuantity, p.CreatedAt, p.UpdatedAt}
+	},
+}
+
+// ExportProducts streams every product matching the list filters.
+func (h *ProductHandler) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	categoryID := httputil.QueryInt(r, "category_id", 0)
+
+	exportRecords(w, r, productExport, func(fn func(domain.Product) error) error {
+		return h.svc.Export(r.Context(), query, categoryID, fn)
+	})
+}
+
 func (h *ProductHandler) GetProductByID(w http.ResponseWriter, r *http.Request) {
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/products/")
 	id, err := strconv.Atoi(idStr)
@@ -46,7 +71,17 @@ func (h *ProductHandler) GetProductByID(w http.ResponseWriter, r *http.Request)
 
 	p, err := h.svc.Get(r.Context(), id)
 	if err != nil {
-		responder.Error(w, http.StatusInternalServerError, err.Error())
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, p)
+}
+
+func (h *ProductHandler) LookupProduct(w http.ResponseWriter, r *http.Request) {
+	p, err := h.svc.Lookup(r.Context(), r.URL.Query().Get("barcode"))
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -62,13 +97,41 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 
 	created, err := h.svc.Create(r.Context(), in)
 	if err != nil {
-		responder.Error(w, http.StatusInternalServerError, err.Error())
+		writeError(w, err)
 		return
 	}
 
 	responder.Success(w, created)
 }
 
+// BatchProducts applies a list of create, update and delete operations.
+// mode is "atomic" (the default) or "best_effort".
+func (h *ProductHandler) BatchProducts(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Mode       string                  `json:"mode"`
+		Operations []domain.ProductBatchOp `json:"operations"`
+	}
+	if err := httputil.DecodeJSON(w, r, &in); err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+	if in.Mode != "" && in.Mode != "atomic" && in.Mode != "best_effort" {
+		responder.Error(w, http.StatusBadRequest, "mode must be atomic or best_effort")
+		return
+	}
+
+	res, err := h.svc.Batch(r.Context(), in.Operations, in.Mode != "best_effort")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !res.Applied {
+		responder.JSON(w, http.StatusUnprocessableEntity, responder.SuccessResponse{Success: false, Data: res})
+		return
+	}
+	responder.Success(w, res)
+}
+
 func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/products/")
 	id, err := strconv.Atoi(idStr)
@@ -85,7 +148,7 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 
 	updated, err := h.svc.Update(r.Context(), id, in)
 	if err != nil {
-		responder.Error(w, http.StatusInternalServerError, err.Error())
+		writeError(w, err)
 		return
 	}
 	responder.Success(w, updated)
@@ -100,7 +163,7 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.Delete(r.Context(), id); err != nil {
-		responder.Error(w, http.StatusInternalServerError, err.Error())
+		writeError(w, err)
 		return
 	}
 	responder.Success(w, map[string]any{"deleted": true})
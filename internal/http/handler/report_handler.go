@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"pos-api/internal/http/httputil"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/service"
+)
+
+type ReportHandler struct {
+	svc *service.ReportService
+}
+
+func NewReportHandler(s *service.ReportService) *ReportHandler {
+	return &ReportHandler{svc: s}
+}
+
+func (h *ReportHandler) GetSalesReport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	top := httputil.QueryInt(r, "top", 10)
+
+	report, err := h.svc.Sales(r.Context(), q.Get("from"), q.Get("to"), q.Get("group_by"), q.Get("tz"), top)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, report)
+}
+
+func (h *ReportHandler) GetInventoryValuation(w http.ResponseWriter, r *http.Request) {
+	categoryID := httputil.QueryInt(r, "category_id", 0)
+
+	report, err := h.svc.Valuation(r.Context(), r.URL.Query().Get("method"), categoryID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, report)
+}
+
+func (h *ReportHandler) GetCOGSReport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	report, err := h.svc.COGS(r.Context(), q.Get("from"), q.Get("to"), q.Get("group_by"), q.Get("tz"), q.Get("method"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, report)
+}
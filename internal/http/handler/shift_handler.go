@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/http/httputil"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/service"
+)
+
+type ShiftHandler struct {
+	svc *service.ShiftService
+}
+
+func NewShiftHandler(s *service.ShiftService) *ShiftHandler {
+	return &ShiftHandler{svc: s}
+}
+
+func (h *ShiftHandler) GetShifts(w http.ResponseWriter, r *http.Request) {
+	limit := httputil.QueryInt(r, "limit", 50)
+	offset := httputil.QueryInt(r, "offset", 0)
+
+	items, err := h.svc.List(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responder.Success(w, map[string]any{
+		"items":  items,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func (h *ShiftHandler) GetShiftByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/shifts/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	s, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, s)
+}
+
+func (h *ShiftHandler) OpenShift(w http.ResponseWriter, r *http.Request) {
+	var in domain.Shift
+	if err := httputil.DecodeJSON(w, r, &in); err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+
+	created, err := h.svc.Open(r.Context(), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, created)
+}
+
+func (h *ShiftHandler) AddCashMovement(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var in domain.CashMovement
+	if err := httputil.DecodeJSON(w, r, &in); err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+	in.ShiftID = id
+
+	m, err := h.svc.AddCashMovement(r.Context(), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, m)
+}
+
+type closeShiftRequest struct {
+	CountedCash *int `json:"counted_cash"`
+}
+
+func (h *ShiftHandler) CloseShift(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var in closeShiftRequest
+	if err := httputil.DecodeJSON(w, r, &in); err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+	if in.CountedCash == nil {
+		responder.Error(w, http.StatusBadRequest, "counted_cash is required")
+		return
+	}
+
+	report, err := h.svc.Close(r.Context(), id, *in.CountedCash)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, report)
+}
+
+func (h *ShiftHandler) GetXReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	report, err := h.svc.XReport(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, report)
+}
+
+func (h *ShiftHandler) GetZReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	report, err := h.svc.ZReport(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, report)
+}
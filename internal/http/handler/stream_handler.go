@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/service"
+)
+
+// streamHeartbeat is how often an idle stream is kept alive, so proxies do
+// not close it.
+const streamHeartbeat = 15 * time.Second
+
+const (
+	// wsWriteTimeout bounds each message and ping sent to a WebSocket
+	// client, including the wait for its pong.
+	wsWriteTimeout = 10 * time.Second
+	// wsReadLimit bounds the messages read, and discarded, from a
+	// WebSocket client.
+	wsReadLimit = 64 << 10
+)
+
+type StreamHandler struct {
+	svc *service.EventStream
+	// accept lets pages from the API's own origin open the WebSocket, and
+	// from the other allowed origins.
+	accept *websocket.AcceptOptions
+}
+
+// NewStreamHandler lets browser pages open the WebSocket from the API's own
+// origin or one of allowedOrigins, written as scheme://host[:port], or from
+// anywhere when they include "*". Requests without an Origin header do not
+// come from a page and are accepted.
+func NewStreamHandler(s *service.EventStream, allowedOrigins []string) *StreamHandler {
+	accept := &websocket.AcceptOptions{InsecureSkipVerify: slices.Contains(allowedOrigins, "*")}
+	for _, origin := range allowedOrigins {
+		accept.OriginPatterns = append(accept.OriginPatterns, strings.TrimSuffix(origin, "/"))
+	}
+	return &StreamHandler{svc: s, accept: accept}
+}
+
+// subscribe reads the topics and the Seq of the event to resume after, from
+// the Last-Event-ID header or the last_event_id query parameter.
+func (h *StreamHandler) subscribe(w http.ResponseWriter, r *http.Request) (*service.StreamSubscription, bool) {
+	topics, err := service.ParseTopics(r.URL.Query().Get("topics"))
+	if err != nil {
+		writeError(w, err)
+		return nil, false
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	after := 0
+	if lastID != "" {
+		if after, err = strconv.Atoi(lastID); err != nil {
+			responder.Error(w, http.StatusBadRequest, "invalid last event id")
+			return nil, false
+		}
+	}
+
+	sub, err := h.svc.Subscribe(r.Context(), topics, after)
+	if err != nil {
+		writeError(w, err)
+		return nil, false
+	}
+	return sub, true
+}
+
+// Stream sends events as Server-Sent Events: the event's Seq as id, the type
+// as event and the event itself, as JSON, as data.
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	sub, ok := h.subscribe(w, r)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(e domain.Event) error {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Type, data)
+		return err
+	}
+
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if sub.Reset {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, e := range sub.Replay {
+		if err := send(e); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		log.Printf("stream: %v", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.Dropped():
+			// The client reconnects by itself and resumes from the last ID.
+			return
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": ping\n\n")
+		case e := <-sub.Events():
+			err = send(e)
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// StreamWebSocket sends each event as a JSON text message; clients resume
+// from the seq of the last one. A client that missed too much to replay gets
+// {"type":"reset"} first.
+func (h *StreamHandler) StreamWebSocket(w http.ResponseWriter, r *http.Request) {
+	sub, ok := h.subscribe(w, r)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	// Accept has written the error response if it fails.
+	conn, err := websocket.Accept(w, r, h.accept)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(wsReadLimit)
+
+	// Reading, which answers the client's pings and takes its pongs, goes on
+	// until the connection closes. Messages from the client are discarded.
+	ctx := r.Context()
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			_, msg, err := conn.Reader(ctx)
+			if err != nil {
+				return
+			}
+			if _, err := io.Copy(io.Discard, msg); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(msg []byte) error {
+		ctx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+		defer cancel()
+		return conn.Write(ctx, websocket.MessageText, msg)
+	}
+	ping := func() error {
+		ctx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+		defer cancel()
+		return conn.Ping(ctx)
+	}
+	send := func(e domain.Event) error {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		return write(data)
+	}
+
+	if sub.Reset {
+		if err := write([]byte(`{"type":"reset"}`)); err != nil {
+			conn.Close(websocket.StatusGoingAway, "")
+			return
+		}
+	}
+	for _, e := range sub.Replay {
+		if err := send(e); err != nil {
+			conn.Close(websocket.StatusGoingAway, "")
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-closed:
+			return
+		case <-sub.Dropped():
+			conn.Close(websocket.StatusGoingAway, "client too slow, resume with last_event_id")
+			return
+		case <-heartbeat.C:
+			err = ping()
+		case e := <-sub.Events():
+			err = send(e)
+		}
+		if err != nil {
+			conn.Close(websocket.StatusGoingAway, "")
+			return
+		}
+	}
+}
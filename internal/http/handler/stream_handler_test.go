@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository_memory"
+	"pos-api/internal/service"
+)
+
+func newStreamServer(t *testing.T, origins []string) (*service.EventStream, string) {
+	t.Helper()
+	stream := service.NewEventStream(repository_memory.NewOutboxRepo())
+	srv := httptest.NewServer(http.HandlerFunc(NewStreamHandler(stream, origins).StreamWebSocket))
+	t.Cleanup(srv.Close)
+	return stream, "ws" + srv.URL[len("http"):]
+}
+
+func TestStreamWebSocketSendsEvents(t *testing.T) {
+	stream, url := newStreamServer(t, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+
+	// Messages from the client are ignored.
+	if err := conn.Write(ctx, websocket.MessageText, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.HandleEvent(ctx, domain.Event{ID: 7, Seq: 3, Type: domain.EventProductCreated, EntityID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	typ, msg, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e domain.Event
+	if err := json.Unmarshal(msg, &e); err != nil {
+		t.Fatal(err)
+	}
+	if typ != websocket.MessageText || e.ID != 7 || e.Seq != 3 {
+		t.Errorf("got %v message %s, want event 7 with seq 3 as text", typ, msg)
+	}
+	if err := conn.Close(websocket.StatusNormalClosure, ""); err != nil {
+		t.Errorf("closing: %v", err)
+	}
+}
+
+func TestStreamWebSocketOrigins(t *testing.T) {
+	tests := []struct {
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{nil, "", true},
+		{nil, "https://shop.example.com", false},
+		{[]string{"https://shop.example.com/"}, "https://shop.example.com", true},
+		{[]string{"https://shop.example.com"}, "http://shop.example.com", false},
+		{[]string{"https://shop.example.com"}, "https://evil.example.com", false},
+		{[]string{"*"}, "https://evil.example.com", true},
+	}
+	for _, tt := range tests {
+		_, url := newStreamServer(t, tt.allowed)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		opts := &websocket.DialOptions{HTTPHeader: http.Header{}}
+		if tt.origin != "" {
+			opts.HTTPHeader.Set("Origin", tt.origin)
+		}
+
+		conn, resp, err := websocket.Dial(ctx, url, opts)
+		if got := err == nil; got != tt.want {
+			t.Errorf("origin %q with %q allowed: connected = %v, want %v", tt.origin, tt.allowed, got, tt.want)
+		}
+		if err == nil {
+			conn.CloseNow()
+		} else if resp != nil && resp.StatusCode != http.StatusForbidden {
+			t.Errorf("origin %q with %q allowed: status %d, want 403", tt.origin, tt.allowed, resp.StatusCode)
+		}
+		cancel()
+	}
+}
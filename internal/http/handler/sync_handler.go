@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/http/httputil"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/service"
+)
+
+type SyncHandler struct {
+	svc *service.SyncService
+}
+
+func NewSyncHandler(s *service.SyncService) *SyncHandler {
+	return &SyncHandler{svc: s}
+}
+
+func (h *SyncHandler) GetChanges(w http.ResponseWriter, r *http.Request) {
+	since := 0
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		if since, err = strconv.Atoi(v); err != nil {
+			responder.Error(w, http.StatusBadRequest, "invalid since")
+			return
+		}
+	}
+
+	changes, err := h.svc.Changes(r.Context(), since, httputil.QueryInt(r, "limit", 500))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, changes)
+}
+
+// PushOrders answers 200 even when some sales were not applied; the status
+// of each is in its result.
+func (h *SyncHandler) PushOrders(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Orders []domain.Order `json:"orders"`
+	}
+	if err := httputil.DecodeJSON(w, r, &in); err != nil {
+		responder.Error(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return
+	}
+
+	results, err := h.svc.PushOrders(r.Context(), in.Orders)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	responder.Success(w, map[string]any{
+		"results": results,
+	})
+}
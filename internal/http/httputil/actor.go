@@ -0,0 +1,44 @@
+package httputil
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"pos-api/internal/actor"
+)
+
+// Headers naming who makes a change and why. They are taken on trust.
+const (
+	ActorHeader     = "X-Actor"
+	ReasonHeader    = "X-Change-Reason"
+	RequestIDHeader = "X-Request-ID"
+)
+
+// WithActor puts the actor and reason headers of each request into its
+// context, along with the client address and a request ID. The request ID
+// is the client's X-Request-ID when it sends one, or a new random one; it is
+// echoed in the response.
+func WithActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := actor.RequestID(r.Header.Get(RequestIDHeader))
+		w.Header().Set(RequestIDHeader, requestID)
+
+		a := actor.Actor{
+			Name:      strings.TrimSpace(r.Header.Get(ActorHeader)),
+			Reason:    strings.TrimSpace(r.Header.Get(ReasonHeader)),
+			RequestID: requestID,
+			IP:        remoteIP(r),
+		}
+		next.ServeHTTP(w, r.WithContext(actor.With(r.Context(), a)))
+	})
+}
+
+// remoteIP is the address r came from, without the port.
+func remoteIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return ip
+}
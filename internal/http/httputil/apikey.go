@@ -0,0 +1,106 @@
+package httputil
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"pos-api/internal/actor"
+	"pos-api/internal/auth"
+	"pos-api/internal/domain"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/tenant"
+)
+
+// apiKeyResources maps the first segment of an /api path to the resource
+// its scopes name, where the two differ.
+var apiKeyResources = map[string]string{
+	"stream": "events",
+	"ws":     "events",
+}
+
+// WithAPIKey authenticates the requests that carry an API key in an
+// Authorization: Bearer header, through authenticate, and holds them to
+// the key's scopes: reading a resource takes its read scope, and any other
+// method its write scope. The request then works for the key's tenant. When
+// required is set, a request without a key gets a 401, unless its path
+// starts with one of optional. GraphQL checks scopes field by field, so
+// /graphql takes no scope here.
+func WithAPIKey(next http.Handler, authenticate auth.Authenticator, required bool, optional ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := strings.TrimSpace(r.Header.Get("Authorization"))
+		if header == "" {
+			if required && !hasAnyPrefix(r.URL.Path, optional) {
+				unauthorized(w, "an API key is required")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			unauthorized(w, "the Authorization header must be Bearer followed by an API key")
+			return
+		}
+
+		key, err := authenticate(r.Context(), token)
+		if errors.Is(err, domain.ErrUnauthorized) {
+			unauthorized(w, err.Error())
+			return
+		}
+		if err != nil {
+			responder.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if scope, ok := apiKeyScope(r.Method, r.URL.Path); !ok {
+			responder.Error(w, http.StatusForbidden, "no API key scope covers "+r.URL.Path)
+			return
+		} else if scope != "" && !key.Allows(scope) {
+			responder.Error(w, http.StatusForbidden, "the API key does not have the "+scope+" scope")
+			return
+		}
+
+		ctx := auth.With(r.Context(), key)
+		ctx = tenant.With(ctx, key.TenantID)
+		ctx = actor.With(ctx, actor.From(ctx).WithAPIKey(key.Prefix))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// apiKeyScope returns the scope a request needs, which is empty for the
+// paths outside /api and for /graphql. It reports false for an /api path
+// that no scope covers.
+func apiKeyScope(method, path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, "/api/")
+	if !ok {
+		return "", true
+	}
+	resource, _, _ := strings.Cut(rest, "/")
+	if r, ok := apiKeyResources[resource]; ok {
+		resource = r
+	}
+	access := "write"
+	if method == http.MethodGet || method == http.MethodHead {
+		access = "read"
+	}
+	scope, err := domain.ParseScope(resource + ":" + access)
+	if err != nil {
+		return "", false
+	}
+	return scope, true
+}
+
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="pos-api"`)
+	responder.Error(w, http.StatusUnauthorized, msg)
+}
+
+func hasAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
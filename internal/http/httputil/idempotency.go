@@ -0,0 +1,170 @@
+package httputil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pos-api/internal/http/responder"
+	"pos-api/internal/tenant"
+)
+
+// IdempotencyHeader names a key the client makes up for a POST, so that
+// sending the same POST again, for example after a timeout, does not apply it
+// twice.
+const IdempotencyHeader = "Idempotency-Key"
+
+// ReplayedHeader is set on responses replayed for a repeated key.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotentBody bounds the body of a POST with an idempotency key, which
+// is read in full to be compared with the first; it is the largest any
+// route takes.
+const maxIdempotentBody = 32 << 20
+
+// unkeptHeaders describe one response rather than the outcome of the
+// request, so a replay gets its own.
+var unkeptHeaders = []string{
+	RequestIDHeader,
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
+	"RateLimit-Policy",
+}
+
+type idempotentResponse struct {
+	done chan struct{}
+	// bodyHash is the SHA-256 of the request body, which a repeat must
+	// match.
+	bodyHash [sha256.Size]byte
+	status   int
+	header   http.Header
+	body     []byte
+	expires  time.Time
+}
+
+// expiringKey is a kept response in the queue of those to forget.
+type expiringKey struct {
+	key string
+	e   *idempotentResponse
+}
+
+// WithIdempotency answers a POST whose Idempotency-Key was seen within ttl
+// with the response to the first, without running it again. A key still in
+// progress gets a 409 with a Retry-After, and one sent again with a
+// different body a 422. Keys are per tenant, client, method and path, where
+// the client is the API key the request was authenticated with, so
+// WithAPIKey must run first, or else its address. Responses of 500 and
+// above are not kept, so a failed request can be retried with the same key.
+// They are held in memory, so each instance of the API has its own.
+func WithIdempotency(next http.Handler, ttl time.Duration) http.Handler {
+	var mu sync.Mutex
+	seen := make(map[string]*idempotentResponse)
+	// Every kept response lives for ttl, so they expire in the order they
+	// were kept and only the front of the queue needs checking.
+	var expiring []expiringKey
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get(IdempotencyHeader))
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > 255 {
+			responder.Error(w, http.StatusBadRequest, "idempotency key is longer than 255 characters")
+			return
+		}
+		key = strconv.Itoa(tenant.From(r.Context())) + " " + rateLimitClient(r) + " " + r.Method + " " + r.URL.Path + " " + key
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBody+1))
+		if err != nil {
+			responder.Error(w, http.StatusBadRequest, "could not read the request body")
+			return
+		}
+		if len(body) > maxIdempotentBody {
+			responder.Error(w, http.StatusRequestEntityTooLarge, "request body is too large")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		bodyHash := sha256.Sum256(body)
+
+		now := time.Now()
+		mu.Lock()
+		for len(expiring) > 0 && now.After(expiring[0].e.expires) {
+			if seen[expiring[0].key] == expiring[0].e {
+				delete(seen, expiring[0].key)
+			}
+			expiring[0] = expiringKey{}
+			expiring = expiring[1:]
+		}
+		if e, ok := seen[key]; ok {
+			mu.Unlock()
+			if e.bodyHash != bodyHash {
+				responder.Error(w, http.StatusUnprocessableEntity, "the idempotency key was used with a different request body")
+				return
+			}
+			select {
+			case <-e.done:
+				for k, v := range e.header {
+					w.Header()[k] = v
+				}
+				w.Header().Set(ReplayedHeader, "true")
+				w.WriteHeader(e.status)
+				_, _ = w.Write(e.body)
+			default:
+				w.Header().Set("Retry-After", "1")
+				responder.Error(w, http.StatusConflict, "a request with this idempotency key is in progress")
+			}
+			return
+		}
+		e := &idempotentResponse{done: make(chan struct{}), bodyHash: bodyHash}
+		seen[key] = e
+		mu.Unlock()
+
+		rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			p := recover()
+			mu.Lock()
+			defer mu.Unlock()
+			if p != nil || rec.status >= http.StatusInternalServerError {
+				delete(seen, key)
+			} else {
+				e.status = rec.status
+				e.header = w.Header().Clone()
+				for _, h := range unkeptHeaders {
+					e.header.Del(h)
+				}
+				e.body = rec.body.Bytes()
+				e.expires = time.Now().Add(ttl)
+				expiring = append(expiring, expiringKey{key, e})
+			}
+			close(e.done)
+			if p != nil {
+				panic(p)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// recordingWriter keeps a copy of the response it writes.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
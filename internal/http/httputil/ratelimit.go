@@ -0,0 +1,93 @@
+package httputil
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pos-api/internal/auth"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/ratelimit"
+)
+
+// WithRateLimit limits the requests of each client to the limit rules give
+// their route, taking tokens from store. A client is the API key the request
+// was authenticated with, so WithAPIKey must run first, or else its address.
+// Every limited response carries RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy; a request over the limit gets a 429
+// with Retry-After.
+// When the store fails, requests are let through rather than refused.
+func WithRateLimit(next http.Handler, store ratelimit.Store, rules ratelimit.Rules) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, limit := rules.For(r.Method, r.URL.Path)
+		if limit.Unlimited() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		res, err := store.Take(r.Context(), name+" "+rateLimitClient(r), limit)
+		if err != nil {
+			log.Printf("rate limit: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, seconds(limit.Period)))
+		if !res.Allowed {
+			retry := max(seconds(res.RetryAfter), 1)
+			h.Set("Retry-After", strconv.Itoa(retry))
+			responder.Error(w, http.StatusTooManyRequests, fmt.Sprintf("rate limit of %s exceeded; retry in %ds", limit, retry))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WithAddressRateLimit limits the requests from each address to limit,
+// taking tokens from store, whatever key they carry. It goes in front of
+// WithAPIKey, so requests are counted before their key is looked up and
+// guessing keys is held to the limit too. Only a request over the limit
+// hears of it, with a 429 and Retry-After; WithRateLimit reports the rest.
+// When the store fails, requests are let through rather than refused.
+func WithAddressRateLimit(next http.Handler, store ratelimit.Store, limit ratelimit.Limit) http.Handler {
+	if limit.Unlimited() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, err := store.Take(r.Context(), "address ip:"+remoteIP(r), limit)
+		if err != nil {
+			log.Printf("rate limit: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !res.Allowed {
+			retry := max(seconds(res.RetryAfter), 1)
+			w.Header().Set("Retry-After", strconv.Itoa(retry))
+			responder.Error(w, http.StatusTooManyRequests, fmt.Sprintf("rate limit of %s per address exceeded; retry in %ds", limit, retry))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitClient names the client of r. Headers the client sends are not
+// trusted: an unauthenticated request is known only by the address it came
+// from.
+func rateLimitClient(r *http.Request) string {
+	if key, ok := auth.From(r.Context()); ok {
+		return "key:" + strconv.Itoa(key.TenantID) + "/" + strconv.Itoa(key.ID)
+	}
+	return "ip:" + remoteIP(r)
+}
+
+// seconds rounds d up to whole seconds, as the headers count them.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,67 @@
+package httputil
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/http/responder"
+	"pos-api/internal/tenant"
+)
+
+// TenantHeader names the tenant a request works for, by ID or slug.
+const TenantHeader = "X-Tenant-ID"
+
+// WithTenant puts the tenant named by the X-Tenant-ID header, or fallback
+// when there is none, into the context of each request. A request that
+// already has a tenant, from its API key, keeps it and may only name that
+// one. A request naming no tenant gets a 400, unless its path starts with
+// one of optional; those run without a tenant.
+func WithTenant(next http.Handler, resolve tenant.Resolver, fallback string, optional ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixed := tenant.From(r.Context())
+		ref := strings.TrimSpace(r.Header.Get(TenantHeader))
+		if ref == "" && fixed != 0 {
+			ref = strconv.Itoa(fixed)
+		}
+		if ref == "" {
+			ref = strings.TrimSpace(fallback)
+		}
+		if ref == "" {
+			if hasAnyPrefix(r.URL.Path, optional) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			responder.Error(w, http.StatusBadRequest, "the "+TenantHeader+" header is required")
+			return
+		}
+
+		// Resolving the tenant of an API key too checks it is not suspended.
+		id, err := resolve(r.Context(), ref)
+		if err != nil {
+			writeTenantError(w, err)
+			return
+		}
+		if fixed != 0 && id != fixed {
+			responder.Error(w, http.StatusForbidden, "the API key belongs to another tenant")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(tenant.With(r.Context(), id)))
+	})
+}
+
+// writeTenantError answers a request whose tenant could not be resolved.
+func writeTenantError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, domain.ErrForbidden):
+		status = http.StatusForbidden
+	}
+	responder.Error(w, status, err.Error())
+}
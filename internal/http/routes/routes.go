@@ -0,0 +1,190 @@
+// Package routes maps the API's paths to their handlers, so the server and
+// posctl's direct mode serve the same routes.
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"pos-api/internal/http/handler"
+)
+
+// Handlers are the handlers to route to. Routes are registered only for the
+// handlers set, so a caller serving part of the API leaves the rest nil.
+type Handlers struct {
+	Tenant        *handler.TenantHandler
+	APIKey        *handler.APIKeyHandler
+	Category      *handler.CategoryHandler
+	Product       *handler.ProductHandler
+	Price         *handler.PriceHandler
+	Supplier      *handler.SupplierHandler
+	Location      *handler.LocationHandler
+	Inventory     *handler.InventoryHandler
+	PurchaseOrder *handler.PurchaseOrderHandler
+	Shift         *handler.ShiftHandler
+	Order         *handler.OrderHandler
+	Sync          *handler.SyncHandler
+	Report        *handler.ReportHandler
+	Import        *handler.ImportHandler
+	Audit         *handler.AuditHandler
+	Stream        *handler.StreamHandler
+	Webhook       *handler.WebhookHandler
+	GraphQL       *handler.GraphQLHandler
+	Docs          *handler.DocsHandler
+}
+
+// Register adds the routes of every handler set in h, and /health, to mux.
+func Register(mux *http.ServeMux, h Handlers) {
+	if h.Tenant != nil {
+		mux.HandleFunc("GET /api/tenants", h.Tenant.GetTenants)
+		mux.HandleFunc("GET /api/tenants/", h.Tenant.GetTenantByID)
+		mux.HandleFunc("POST /api/tenants", h.Tenant.CreateTenant)
+		mux.HandleFunc("PUT /api/tenants/", h.Tenant.UpdateTenant)
+	}
+
+	if h.APIKey != nil {
+		mux.HandleFunc("GET /api/api-keys", h.APIKey.GetAPIKeys)
+		mux.HandleFunc("GET /api/api-keys/", h.APIKey.GetAPIKeyByID)
+		mux.HandleFunc("POST /api/api-keys", h.APIKey.CreateAPIKey)
+		mux.HandleFunc("POST /api/api-keys/{id}/revoke", h.APIKey.RevokeAPIKey)
+	}
+
+	if h.Category != nil {
+		mux.HandleFunc("GET /api/categories", h.Category.GetCategories)
+		mux.HandleFunc("GET /api/categories/", h.Category.GetCategoryByID)
+		mux.HandleFunc("GET /api/categories/export", h.Category.ExportCategories)
+		mux.HandleFunc("POST /api/categories", h.Category.CreateCategory)
+		mux.HandleFunc("PUT /api/categories/", h.Category.UpdateCategory)
+		mux.HandleFunc("DELETE /api/categories/", h.Category.DeleteCategory)
+	}
+
+	if h.Product != nil {
+		mux.HandleFunc("GET /api/products", h.Product.GetProducts)
+		mux.HandleFunc("GET /api/products/", h.Product.GetProductByID)
+		mux.HandleFunc("GET /api/products/lookup", h.Product.LookupProduct)
+		mux.HandleFunc("GET /api/products/export", h.Product.ExportProducts)
+		mux.HandleFunc("POST /api/products", h.Product.CreateProduct)
+		mux.HandleFunc("POST /api/products/batch", h.Product.BatchProducts)
+		mux.HandleFunc("PUT /api/products/", h.Product.UpdateProduct)
+		mux.HandleFunc("DELETE /api/products/", h.Product.DeleteProduct)
+	}
+
+	if h.Price != nil {
+		mux.HandleFunc("GET /api/products/{id}/prices", h.Price.GetProductPrices)
+		mux.HandleFunc("POST /api/products/{id}/prices", h.Price.SchedulePrice)
+		mux.HandleFunc("DELETE /api/products/{id}/prices/{priceID}", h.Price.CancelScheduledPrice)
+	}
+
+	if h.Supplier != nil {
+		mux.HandleFunc("GET /api/suppliers", h.Supplier.GetSuppliers)
+		mux.HandleFunc("GET /api/suppliers/", h.Supplier.GetSupplierByID)
+		mux.HandleFunc("POST /api/suppliers", h.Supplier.CreateSupplier)
+		mux.HandleFunc("PUT /api/suppliers/", h.Supplier.UpdateSupplier)
+		mux.HandleFunc("DELETE /api/suppliers/", h.Supplier.DeleteSupplier)
+	}
+
+	if h.Location != nil {
+		mux.HandleFunc("GET /api/locations", h.Location.GetLocations)
+		mux.HandleFunc("GET /api/locations/", h.Location.GetLocationByID)
+		mux.HandleFunc("POST /api/locations", h.Location.CreateLocation)
+		mux.HandleFunc("PUT /api/locations/", h.Location.UpdateLocation)
+		mux.HandleFunc("DELETE /api/locations/", h.Location.DeleteLocation)
+	}
+
+	if h.Inventory != nil {
+		mux.HandleFunc("GET /api/inventory/stock", h.Inventory.GetStock)
+		mux.HandleFunc("POST /api/inventory/stock/adjust", h.Inventory.AdjustStock)
+		mux.HandleFunc("GET /api/products/{id}/stock", h.Inventory.GetProductStock)
+		mux.HandleFunc("GET /api/products/{id}/cost-layers", h.Inventory.GetProductCostLayers)
+		mux.HandleFunc("GET /api/inventory/transfers", h.Inventory.GetTransfers)
+		mux.HandleFunc("GET /api/inventory/transfers/", h.Inventory.GetTransferByID)
+		mux.HandleFunc("POST /api/inventory/transfers", h.Inventory.CreateTransfer)
+		mux.HandleFunc("POST /api/inventory/transfers/{id}/receive", h.Inventory.ReceiveTransfer)
+		mux.HandleFunc("POST /api/inventory/transfers/{id}/cancel", h.Inventory.CancelTransfer)
+		mux.HandleFunc("GET /api/inventory/low-stock", h.Inventory.GetLowStock)
+		mux.HandleFunc("GET /api/inventory/alerts", h.Inventory.GetAlerts)
+		mux.HandleFunc("GET /api/inventory/reorder-suggestions", h.Inventory.GetReorderSuggestions)
+	}
+
+	if h.PurchaseOrder != nil {
+		mux.HandleFunc("GET /api/purchase-orders", h.PurchaseOrder.GetPurchaseOrders)
+		mux.HandleFunc("GET /api/purchase-orders/", h.PurchaseOrder.GetPurchaseOrderByID)
+		mux.HandleFunc("POST /api/purchase-orders", h.PurchaseOrder.CreatePurchaseOrder)
+		mux.HandleFunc("PUT /api/purchase-orders/", h.PurchaseOrder.UpdatePurchaseOrder)
+		mux.HandleFunc("POST /api/purchase-orders/{id}/submit", h.PurchaseOrder.SubmitPurchaseOrder)
+		mux.HandleFunc("POST /api/purchase-orders/{id}/cancel", h.PurchaseOrder.CancelPurchaseOrder)
+		mux.HandleFunc("POST /api/purchase-orders/{id}/receive", h.PurchaseOrder.ReceivePurchaseOrder)
+		mux.HandleFunc("GET /api/purchase-orders/{id}/margins", h.PurchaseOrder.GetPurchaseOrderMargins)
+	}
+
+	if h.Shift != nil {
+		mux.HandleFunc("GET /api/shifts", h.Shift.GetShifts)
+		mux.HandleFunc("GET /api/shifts/", h.Shift.GetShiftByID)
+		mux.HandleFunc("POST /api/shifts", h.Shift.OpenShift)
+		mux.HandleFunc("POST /api/shifts/{id}/cash-movements", h.Shift.AddCashMovement)
+		mux.HandleFunc("POST /api/shifts/{id}/close", h.Shift.CloseShift)
+		mux.HandleFunc("GET /api/shifts/{id}/x-report", h.Shift.GetXReport)
+		mux.HandleFunc("GET /api/shifts/{id}/z-report", h.Shift.GetZReport)
+	}
+
+	if h.Order != nil {
+		mux.HandleFunc("GET /api/orders", h.Order.GetOrders)
+		mux.HandleFunc("GET /api/orders/", h.Order.GetOrderByID)
+		mux.HandleFunc("POST /api/orders", h.Order.CreateOrder)
+		mux.HandleFunc("GET /api/orders/{id}/receipt", h.Order.GetOrderReceipt)
+	}
+
+	if h.Sync != nil {
+		mux.HandleFunc("GET /api/sync/changes", h.Sync.GetChanges)
+		mux.HandleFunc("POST /api/sync/orders", h.Sync.PushOrders)
+	}
+
+	if h.Report != nil {
+		mux.HandleFunc("GET /api/reports/sales", h.Report.GetSalesReport)
+		mux.HandleFunc("GET /api/reports/inventory-valuation", h.Report.GetInventoryValuation)
+		mux.HandleFunc("GET /api/reports/cogs", h.Report.GetCOGSReport)
+	}
+
+	if h.Import != nil {
+		mux.HandleFunc("POST /api/products/import", h.Import.ImportProducts)
+		mux.HandleFunc("POST /api/categories/import", h.Import.ImportCategories)
+		mux.HandleFunc("GET /api/imports/{id}", h.Import.GetImportJob)
+	}
+
+	if h.Audit != nil {
+		mux.HandleFunc("GET /api/audit", h.Audit.GetAuditLog)
+	}
+
+	if h.Stream != nil {
+		mux.HandleFunc("GET /api/stream", h.Stream.Stream)
+		mux.HandleFunc("GET /api/ws", h.Stream.StreamWebSocket)
+	}
+
+	if h.Webhook != nil {
+		mux.HandleFunc("GET /api/webhooks", h.Webhook.GetWebhooks)
+		mux.HandleFunc("GET /api/webhooks/", h.Webhook.GetWebhookByID)
+		mux.HandleFunc("POST /api/webhooks", h.Webhook.CreateWebhook)
+		mux.HandleFunc("PUT /api/webhooks/", h.Webhook.UpdateWebhook)
+		mux.HandleFunc("DELETE /api/webhooks/", h.Webhook.DeleteWebhook)
+		mux.HandleFunc("GET /api/webhooks/{id}/deliveries", h.Webhook.GetWebhookDeliveries)
+		mux.HandleFunc("POST /api/webhooks/{id}/deliveries/{deliveryID}/redeliver", h.Webhook.RedeliverWebhook)
+	}
+
+	if h.GraphQL != nil {
+		mux.HandleFunc("POST /graphql", h.GraphQL.ServeGraphQL)
+	}
+
+	if h.Docs != nil {
+		mux.HandleFunc("GET /openapi.json", h.Docs.ServeSpec)
+		mux.HandleFunc("GET /docs", h.Docs.ServeDocs)
+		mux.HandleFunc("GET /docs/", h.Docs.RedirectDocs)
+	}
+
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "OK",
+			"message": "API Running",
+		})
+	})
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often MemoryStore drops the buckets that have filled
+// up again, which are the same as no bucket.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps buckets in the process, so each instance of the API
+// limits the requests it serves by itself.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+	period time.Duration
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if limit.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+	now := time.Now()
+	capacity := float64(limit.Requests)
+	perToken := float64(limit.Period) / capacity
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, at: now}
+		s.buckets[key] = b
+	}
+	b.period = limit.Period
+	b.tokens += float64(now.Sub(b.at)) / perToken
+	if b.tokens > capacity {
+		b.tokens = capacity
+	}
+	b.at = now
+
+	res := Result{Allowed: b.tokens >= 1}
+	if res.Allowed {
+		b.tokens--
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) * perToken)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration((capacity - b.tokens) * perToken)
+	return res, nil
+}
+
+// sweep drops full buckets, at most once per sweepInterval.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.at) >= b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
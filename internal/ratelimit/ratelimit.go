@@ -0,0 +1,180 @@
+// Package ratelimit meters requests with token buckets: a bucket holds up
+// to a limit's number of requests and refills at that number per period, so
+// a client may burst up to the limit and then keeps to the average rate.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is the number of requests allowed per period. The zero Limit allows
+// any number.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Unlimited reports whether l lets every request through.
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "off"
+	}
+	switch l.Period {
+	case time.Second:
+		return fmt.Sprintf("%d/s", l.Requests)
+	case time.Minute:
+		return fmt.Sprintf("%d/m", l.Requests)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", l.Requests)
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// ParseLimit reads a limit written as requests/period, such as 20/s, 600/m,
+// 5000/h or 100/10s, or "off" for no limit.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "off") {
+		return Limit{}, nil
+	}
+	n, per, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q is not requests/period, such as 20/s", s)
+	}
+	requests, err := strconv.Atoi(strings.TrimSpace(n))
+	if err != nil || requests <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q must allow a positive number of requests", s)
+	}
+
+	var period time.Duration
+	switch per = strings.TrimSpace(per); per {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	default:
+		period, err = time.ParseDuration(per)
+		if err != nil || period <= 0 {
+			return Limit{}, fmt.Errorf("rate limit %q has an invalid period %q", s, per)
+		}
+	}
+	return Limit{Requests: requests, Period: period}, nil
+}
+
+// Route gives the requests whose method and path match it a limit, and a
+// bucket, of their own.
+type Route struct {
+	// Method is the HTTP method matched, or empty for any.
+	Method string
+	// Prefix is matched against the start of the path.
+	Prefix string
+	Limit  Limit
+}
+
+func (r Route) String() string {
+	if r.Method == "" {
+		return r.Prefix
+	}
+	return r.Method + " " + r.Prefix
+}
+
+// ParseRoutes reads per-route limits as a comma-separated list of
+// "[METHOD] /path/prefix=limit", such as
+// "GET /api/products=5/s, /api/reports=30/m, /health=off".
+func ParseRoutes(s string) ([]Route, error) {
+	var routes []Route
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		pattern, limit, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("route limit %q is not route=limit", part)
+		}
+
+		var r Route
+		fields := strings.Fields(pattern)
+		switch len(fields) {
+		case 1:
+			r.Prefix = fields[0]
+		case 2:
+			r.Method, r.Prefix = strings.ToUpper(fields[0]), fields[1]
+		default:
+			return nil, fmt.Errorf("route %q is not [METHOD] /path", strings.TrimSpace(pattern))
+		}
+		if !strings.HasPrefix(r.Prefix, "/") {
+			return nil, fmt.Errorf("route %q must start with /", strings.TrimSpace(pattern))
+		}
+		var err error
+		if r.Limit, err = ParseLimit(limit); err != nil {
+			return nil, err
+		}
+		routes = append(routes, r)
+	}
+	return routes, nil
+}
+
+// Rules are the limits in force: one per route, and Default for the
+// requests that match none. Requests under Default share one bucket per
+// client; each route has its own.
+type Rules struct {
+	Default Limit
+	Routes  []Route
+}
+
+// For returns the bucket name and limit of a request. The route with the
+// longest matching prefix wins, and one naming the method beats one that
+// does not.
+func (rs Rules) For(method, path string) (string, Limit) {
+	best := -1
+	for i, r := range rs.Routes {
+		if (r.Method != "" && r.Method != method) || !strings.HasPrefix(path, r.Prefix) {
+			continue
+		}
+		if best < 0 || moreSpecific(r, rs.Routes[best]) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return "default", rs.Default
+	}
+	return rs.Routes[best].String(), rs.Routes[best].Limit
+}
+
+func moreSpecific(a, b Route) bool {
+	if len(a.Prefix) != len(b.Prefix) {
+		return len(a.Prefix) > len(b.Prefix)
+	}
+	return a.Method != "" && b.Method == ""
+}
+
+// Result is what taking a token from a bucket found.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left.
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until a token is free, when none was.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. MemoryStore keeps them in the process; a store
+// shared by every instance of the API, such as one in Redis, makes the
+// limits hold across all of them.
+type Store interface {
+	// Take takes a token from the bucket named key, which holds limit,
+	// if there is one.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
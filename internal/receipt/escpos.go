@@ -0,0 +1,67 @@
+package receipt
+
+import (
+	"bufio"
+	"io"
+)
+
+// ESC/POS command sequences understood by common 58 mm and 80 mm printers.
+var (
+	escInit        = []byte{0x1b, '@'}
+	escAlignLeft   = []byte{0x1b, 'a', 0}
+	escAlignCenter = []byte{0x1b, 'a', 1}
+	escBoldOn      = []byte{0x1b, 'E', 1}
+	escBoldOff     = []byte{0x1b, 'E', 0}
+	escFeed        = []byte{0x1b, 'd', 4}
+	escPartialCut  = []byte{0x1d, 'V', 1}
+)
+
+// renderESCPOS writes the raw byte stream to send to a thermal printer.
+// The printer does the centring; text is limited to ASCII because the
+// printer's code page is unknown.
+func renderESCPOS(w io.Writer, lines []line) error {
+	bw := bufio.NewWriter(w)
+	bw.Write(escInit)
+
+	cur := alignLeft
+	bold := false
+	for _, l := range lines {
+		if l.align != cur {
+			if l.align == alignCenter {
+				bw.Write(escAlignCenter)
+			} else {
+				bw.Write(escAlignLeft)
+			}
+			cur = l.align
+		}
+		if l.bold != bold {
+			if l.bold {
+				bw.Write(escBoldOn)
+			} else {
+				bw.Write(escBoldOff)
+			}
+			bold = l.bold
+		}
+		bw.WriteString(asciiOnly(l.text))
+		bw.WriteByte('\n')
+	}
+
+	if bold {
+		bw.Write(escBoldOff)
+	}
+	bw.Write(escAlignLeft)
+	bw.Write(escFeed)
+	bw.Write(escPartialCut)
+	return bw.Flush()
+}
+
+func asciiOnly(s string) string {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			r = '?'
+		}
+		b = append(b, byte(r))
+	}
+	return string(b)
+}
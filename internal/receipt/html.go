@@ -0,0 +1,72 @@
+package receipt
+
+import (
+	"html/template"
+	"io"
+
+	"pos-api/internal/domain"
+)
+
+var htmlTemplate = template.Must(template.New("receipt").Funcs(template.FuncMap{
+	"money":  Money,
+	"tender": tenderLabel,
+}).Parse(`<!doctype html>
+<html>
+  <head>
+    <meta charset="utf-8"/>
+    <title>Receipt #{{.Order.ID}}</title>
+    <style>
+      body { font-family: monospace; max-width: 380px; margin: 0 auto; padding: 16px; }
+      header, footer { text-align: center; }
+      table { width: 100%; border-collapse: collapse; }
+      td.amount { text-align: right; white-space: nowrap; }
+      tr.total td { font-weight: bold; border-top: 1px dashed #000; }
+      hr { border: 0; border-top: 1px dashed #000; }
+    </style>
+  </head>
+  <body>
+    <header>
+      {{- if .Store.Name}}
+      <h1>{{.Store.Name}}</h1>
+      {{- end}}
+      {{- if .Store.Address}}
+      <p>{{.Store.Address}}</p>
+      {{- end}}
+      {{- if .Store.Phone}}
+      <p>{{.Store.Phone}}</p>
+      {{- end}}
+    </header>
+    <hr/>
+    <p>Order #{{.Order.ID}}<br/>{{.Time}}
+      {{- if .Order.RegisterID}}<br/>Register {{.Order.RegisterID}}{{end}}
+      {{- if .Order.UserID}}<br/>Cashier {{.Order.UserID}}{{end}}</p>
+    <table>
+      {{- range .Order.Items}}
+      <tr><td colspan="2">{{.Name}}</td></tr>
+      <tr><td>{{.Quantity}} x {{money .UnitPrice}}</td><td class="amount">{{money .LineTotal}}</td></tr>
+      {{- end}}
+      <tr class="total"><td>Subtotal</td><td class="amount">{{money .Order.Subtotal}}</td></tr>
+      {{- if .Order.Tax}}
+      <tr><td>Tax</td><td class="amount">{{money .Order.Tax}}</td></tr>
+      {{- end}}
+      <tr class="total"><td>TOTAL</td><td class="amount">{{money .Order.Total}}</td></tr>
+      {{- range .Order.Tenders}}
+      <tr><td>{{tender .Method}}</td><td class="amount">{{money .Amount}}</td></tr>
+      {{- end}}
+      <tr><td>Change</td><td class="amount">{{money .Order.Change}}</td></tr>
+    </table>
+    {{- if .Store.Footer}}
+    <hr/>
+    <footer><p>{{.Store.Footer}}</p></footer>
+    {{- end}}
+  </body>
+</html>
+`))
+
+func renderHTML(w io.Writer, s Store, o domain.Order) error {
+	return htmlTemplate.Execute(w, struct {
+		Store Store
+		Order domain.Order
+		Time  string
+	}{s, o, orderTime(s, o)})
+}
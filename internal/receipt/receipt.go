@@ -0,0 +1,242 @@
+// Package receipt renders completed orders as printable and emailable
+// receipts: fixed-width plain text, HTML and raw ESC/POS for thermal printers.
+package receipt
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"pos-api/internal/domain"
+)
+
+// Store is the header and footer printed on every receipt.
+type Store struct {
+	Name    string
+	Address string
+	Phone   string
+	Footer  string
+	// Location is the time zone receipt times are printed in; nil is UTC.
+	Location *time.Location
+}
+
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatHTML   Format = "html"
+	FormatESCPOS Format = "escpos"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatHTML, FormatESCPOS:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w: unknown receipt format %q", domain.ErrInvalid, s)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatESCPOS:
+		return "application/octet-stream"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// Paper is the thermal roll width in millimetres.
+type Paper int
+
+const (
+	Paper58 Paper = 58
+	Paper80 Paper = 80
+)
+
+func ParsePaper(s string) (Paper, error) {
+	switch strings.TrimSuffix(strings.TrimSpace(s), "mm") {
+	case "", "80":
+		return Paper80, nil
+	case "58":
+		return Paper58, nil
+	}
+	return 0, fmt.Errorf("%w: paper must be 58 or 80", domain.ErrInvalid)
+}
+
+// Columns is the number of characters per line in the printer's default font.
+func (p Paper) Columns() int {
+	if p == Paper58 {
+		return 32
+	}
+	return 48
+}
+
+// Render writes the receipt for o in the given format. Paper only affects
+// the text and ESC/POS layouts.
+func Render(w io.Writer, s Store, o domain.Order, f Format, p Paper) error {
+	switch f {
+	case FormatText:
+		return renderText(w, layout(s, o, p.Columns()), p.Columns())
+	case FormatHTML:
+		return renderHTML(w, s, o)
+	case FormatESCPOS:
+		return renderESCPOS(w, layout(s, o, p.Columns()))
+	}
+	return fmt.Errorf("%w: unknown receipt format %q", domain.ErrInvalid, f)
+}
+
+type align int
+
+const (
+	alignLeft align = iota
+	alignCenter
+)
+
+// line is one printed row. The text and ESC/POS renderers share the layout
+// and differ only in how they express alignment and emphasis.
+type line struct {
+	text  string
+	align align
+	bold  bool
+}
+
+func layout(s Store, o domain.Order, width int) []line {
+	var out []line
+	center := func(text string, bold bool) {
+		for _, t := range wrap(text, width) {
+			out = append(out, line{text: t, align: alignCenter, bold: bold})
+		}
+	}
+	rule := func() { out = append(out, line{text: strings.Repeat("-", width)}) }
+	pair := func(left, right string, bold bool) {
+		out = append(out, line{text: columns(left, right, width), bold: bold})
+	}
+
+	center(s.Name, true)
+	center(s.Address, false)
+	center(s.Phone, false)
+	rule()
+
+	pair(fmt.Sprintf("Order #%d", o.ID), orderTime(s, o), false)
+	if o.RegisterID != "" || o.UserID != "" {
+		pair("Register "+o.RegisterID, "Cashier "+o.UserID, false)
+	}
+	rule()
+
+	for _, it := range o.Items {
+		for _, t := range wrap(it.Name, width) {
+			out = append(out, line{text: t})
+		}
+		pair(fmt.Sprintf("  %d x %s", it.Quantity, Money(it.UnitPrice)), Money(it.LineTotal), false)
+	}
+	rule()
+
+	pair("Subtotal", Money(o.Subtotal), false)
+	if o.Tax != 0 {
+		pair("Tax", Money(o.Tax), false)
+	}
+	pair("TOTAL", Money(o.Total), true)
+	rule()
+
+	for _, t := range o.Tenders {
+		pair(tenderLabel(t.Method), Money(t.Amount), false)
+	}
+	pair("Change", Money(o.Change), false)
+
+	if s.Footer != "" {
+		rule()
+		center(s.Footer, false)
+	}
+	return out
+}
+
+func orderTime(s Store, o domain.Order) string {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return o.CreatedAt.In(loc).Format("2006-01-02 15:04")
+}
+
+func tenderLabel(m domain.TenderMethod) string {
+	switch m {
+	case domain.TenderQRIS:
+		return "QRIS"
+	case "":
+		return "Other"
+	}
+	return strings.ToUpper(string(m[:1])) + string(m[1:])
+}
+
+// Money formats an amount in whole currency units with dot thousands
+// separators, as printed on Indonesian receipts: 1250000 is "1.250.000".
+func Money(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.Itoa(amount)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
+
+// columns puts left and right on one line of the given width, cutting the
+// left side short if both do not fit.
+func columns(left, right string, width int) string {
+	room := width - len([]rune(right)) - 1
+	if room < 0 {
+		room = 0
+	}
+	l := []rune(left)
+	if len(l) > room {
+		l = l[:room]
+	}
+	return string(l) + strings.Repeat(" ", width-len(l)-len([]rune(right))) + right
+}
+
+// wrap breaks text into lines of at most width runes, at spaces where it can.
+func wrap(text string, width int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	var out []string
+	cur := []rune{}
+	for _, word := range strings.Fields(text) {
+		w := []rune(word)
+		for len(w) > width {
+			if len(cur) > 0 {
+				out = append(out, string(cur))
+				cur = cur[:0]
+			}
+			out = append(out, string(w[:width]))
+			w = w[width:]
+		}
+		switch {
+		case len(cur) == 0:
+			cur = append(cur, w...)
+		case len(cur)+1+len(w) <= width:
+			cur = append(append(cur, ' '), w...)
+		default:
+			out = append(out, string(cur))
+			cur = append([]rune{}, w...)
+		}
+	}
+	if len(cur) > 0 {
+		out = append(out, string(cur))
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package receipt
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pos-api/internal/domain"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+func testOrder() domain.Order {
+	return domain.Order{
+		ID:         1042,
+		RegisterID: "R1",
+		UserID:     "siti",
+		Items: []domain.OrderItem{
+			{Name: "Kopi Susu Gula Aren", Quantity: 2, UnitPrice: 18000, LineTotal: 36000},
+			{Name: "Roti Bakar Coklat Keju Spesial Dengan Topping Extra", Quantity: 1, UnitPrice: 25000, LineTotal: 25000},
+			{Name: "Air Mineral", Quantity: 3, UnitPrice: 5000, LineTotal: 15000},
+			{Name: "Crème Brûlée", Quantity: 1, UnitPrice: 0, LineTotal: 0},
+		},
+		Subtotal: 76000,
+		Tax:      8360,
+		Total:    84360,
+		Tenders: []domain.Tender{
+			{Method: domain.TenderQRIS, Amount: 50000},
+			{Method: domain.TenderCash, Amount: 40000},
+		},
+		Paid:      90000,
+		Change:    5640,
+		CreatedAt: time.Date(2024, 3, 9, 6, 30, 0, 0, time.UTC),
+	}
+}
+
+func testStore() Store {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	return Store{
+		Name:     "Warung Sejahtera",
+		Address:  "Jl. Merdeka No. 17, Bandung",
+		Phone:    "022-555-0142",
+		Footer:   "Terima kasih atas kunjungan Anda <3",
+		Location: jakarta,
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		format Format
+		paper  Paper
+	}{
+		{"text_58mm.golden", FormatText, Paper58},
+		{"text_80mm.golden", FormatText, Paper80},
+		{"html.golden", FormatHTML, Paper80},
+		{"escpos_58mm.golden", FormatESCPOS, Paper58},
+		{"escpos_80mm.golden", FormatESCPOS, Paper80},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Render(&buf, testStore(), testOrder(), tt.format, tt.paper); err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("output differs from %s (run go test -update if the change is intended)\ngot:\n%q\nwant:\n%q", path, buf.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestMoney(t *testing.T) {
+	tests := map[int]string{
+		0:        "0",
+		999:      "999",
+		1000:     "1.000",
+		1250000:  "1.250.000",
+		-84360:   "-84.360",
+		10000000: "10.000.000",
+	}
+	for in, want := range tests {
+		if got := Money(in); got != want {
+			t.Errorf("Money(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
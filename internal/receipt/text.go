@@ -0,0 +1,22 @@
+package receipt
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+func renderText(w io.Writer, lines []line, width int) error {
+	bw := bufio.NewWriter(w)
+	for _, l := range lines {
+		text := l.text
+		if l.align == alignCenter {
+			if pad := (width - len([]rune(text))) / 2; pad > 0 {
+				text = strings.Repeat(" ", pad) + text
+			}
+		}
+		bw.WriteString(strings.TrimRight(text, " "))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
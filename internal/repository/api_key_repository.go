@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+	"time"
+)
+
+// APIKeyRepository keeps API keys. GetByID, List and Revoke see only the
+// keys of the tenant of the context; GetByPrefix and Touch, which
+// authenticate a request before its tenant is known, see every tenant's.
+type APIKeyRepository interface {
+	// Create stores k for k.TenantID.
+	Create(ctx context.Context, k domain.APIKey) (domain.APIKey, error)
+	GetByID(ctx context.Context, id int) (domain.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (domain.APIKey, error)
+	List(ctx context.Context, p ListParams) ([]domain.APIKey, error)
+	// Revoke marks the key revoked at at, unless it already is.
+	Revoke(ctx context.Context, id int, at time.Time) (domain.APIKey, error)
+	// Touch records that the key was used at at.
+	Touch(ctx context.Context, id int, at time.Time) error
+}
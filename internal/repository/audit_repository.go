@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+)
+
+// AuditRepository reads the audit log. Product and category repositories
+// write it themselves, alongside each change.
+type AuditRepository interface {
+	// List returns matching entries, newest first.
+	List(ctx context.Context, p AuditListParams) ([]domain.AuditEntry, error)
+}
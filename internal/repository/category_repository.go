@@ -8,7 +8,18 @@ import (
 type CategoryRepository interface {
 	Create(ctx context.Context, c domain.Category) (domain.Category, error)
 	GetByID(ctx context.Context, id int) (domain.Category, error)
+	// GetByIDs returns the categories with the given IDs, in ID order,
+	// leaving out those that do not exist.
+	GetByIDs(ctx context.Context, ids []int) ([]domain.Category, error)
+	// FindByName lists the categories with the given name, ignoring case.
+	FindByName(ctx context.Context, name string) ([]domain.Category, error)
 	List(ctx context.Context, p ListParams) ([]domain.Category, error)
+	// Export calls fn for every category in ID order without loading them
+	// all at once.
+	Export(ctx context.Context, fn func(domain.Category) error) error
 	Update(ctx context.Context, id int, c domain.Category) (domain.Category, error)
 	Delete(ctx context.Context, id int) error
+	// SaveAll creates the categories without an ID and updates the rest, all
+	// or nothing.
+	SaveAll(ctx context.Context, items []domain.Category) ([]domain.Category, error)
 }
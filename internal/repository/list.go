@@ -4,3 +4,38 @@ type ListParams struct {
 	Limit  int
 	Offset int
 }
+
+// StockListParams filters stock levels; zero IDs match everything.
+type StockListParams struct {
+	LocationID int
+	ProductID  int
+	Limit      int
+	Offset     int
+}
+
+// ProductListParams filters products. Query matches part of the name or SKU,
+// ignoring case; a zero CategoryID matches every category.
+type ProductListParams struct {
+	Query      string
+	CategoryID int
+	Limit      int
+	Offset     int
+}
+
+// AuditListParams filters audit entries; empty fields match everything.
+type AuditListParams struct {
+	EntityType string
+	EntityID   int
+	Actor      string
+	Limit      int
+	Offset     int
+}
+
+// WebhookDeliveryListParams filters the deliveries of a subscription; an
+// empty Status matches every status.
+type WebhookDeliveryListParams struct {
+	SubscriptionID int
+	Status         string
+	Limit          int
+	Offset         int
+}
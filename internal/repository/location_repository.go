@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+)
+
+type LocationRepository interface {
+	Create(ctx context.Context, l domain.Location) (domain.Location, error)
+	GetByID(ctx context.Context, id int) (domain.Location, error)
+	List(ctx context.Context, p ListParams) ([]domain.Location, error)
+	Update(ctx context.Context, id int, l domain.Location) (domain.Location, error)
+	Delete(ctx context.Context, id int) error
+}
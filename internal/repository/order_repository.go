@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+)
+
+type OrderRepository interface {
+	// Create records the order and takes its items out of stock atomically.
+	// A zero CreatedAt is stamped with the current time and the shift, if
+	// any, must be open; otherwise CreatedAt is kept and the shift need only
+	// have been open then.
+	Create(ctx context.Context, o domain.Order) (domain.Order, error)
+	GetByID(ctx context.Context, id int) (domain.Order, error)
+	// GetByClientID finds the order with the ID the terminal gave it.
+	GetByClientID(ctx context.Context, clientID string) (domain.Order, error)
+	List(ctx context.Context, p ListParams) ([]domain.Order, error)
+	// SalesByShift totals the orders rung up during a shift.
+	SalesByShift(ctx context.Context, shiftID int) (domain.ShiftSales, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+)
+
+// OutboxRepository holds domain events until they are delivered. Product and
+// category repositories add events themselves, alongside each change.
+type OutboxRepository interface {
+	// Relay passes up to limit events that are due, oldest first, to fn, each
+	// with the next Seq. Events fn accepts are marked delivered; the rest are
+	// kept, with the error, and retried after a backoff. Relays run one at a
+	// time, so events are delivered in Seq order. It returns how many events
+	// were passed to fn.
+	Relay(ctx context.Context, limit int, fn func(domain.Event) error) (int, error)
+	// Delivered returns up to limit delivered events with Seq above afterSeq,
+	// in Seq order.
+	Delivered(ctx context.Context, afterSeq, limit int) ([]domain.Event, error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+	"time"
+)
+
+// PriceRepository keeps scheduled prices and the price history. Product
+// repositories record history themselves whenever a price changes.
+type PriceRepository interface {
+	// History lists the price changes of a product, newest first.
+	History(ctx context.Context, productID int) ([]domain.PriceChange, error)
+	Schedule(ctx context.Context, sp domain.ScheduledPrice) (domain.ScheduledPrice, error)
+	// Upcoming lists the pending scheduled prices of a product, soonest first.
+	Upcoming(ctx context.Context, productID int) ([]domain.ScheduledPrice, error)
+	// Cancel cancels a pending scheduled price of a product.
+	Cancel(ctx context.Context, productID, id int) (domain.ScheduledPrice, error)
+	// ApplyDue applies pending prices that take effect at or before now, in
+	// order, recording each in the history.
+	ApplyDue(ctx context.Context, now time.Time) ([]domain.ScheduledPrice, error)
+	// NextDue reports when the next pending price takes effect.
+	NextDue(ctx context.Context) (time.Time, bool, error)
+}
@@ -5,10 +5,51 @@ import (
 	"pos-api/internal/domain"
 )
 
+// ProductReader looks products up by ID, SKU or barcode.
+type ProductReader interface {
+	GetByID(ctx context.Context, id int) (domain.Product, error)
+	GetBySKU(ctx context.Context, sku string) (domain.Product, error)
+	GetByBarcode(ctx context.Context, barcode string) (domain.Product, error)
+}
+
+// BatchCheck validates op against the products as they stood when the batch
+// began, read through products, and returns the operation to apply.
+type BatchCheck func(ctx context.Context, products ProductReader, op domain.ProductBatchOp) (domain.ProductBatchOp, error)
+
 type ProductRepository interface {
+	ProductReader
 	Create(ctx context.Context, p domain.Product) (domain.Product, error)
-	GetByID(ctx context.Context, id int) (domain.Product, error)
-	List(ctx context.Context, p ListParams) ([]domain.Product, error)
+	// FindByName lists the products with the given name, ignoring case.
+	FindByName(ctx context.Context, name string) ([]domain.Product, error)
+	List(ctx context.Context, p ProductListParams) ([]domain.Product, error)
+	// ListByCategories pages through the products of each of the categories
+	// separately, newest first, and returns them by category ID.
+	ListByCategories(ctx context.Context, categoryIDs []int, p ListParams) (map[int][]domain.Product, error)
+	// Export calls fn for every product matching the filters of p, in ID
+	// order, without loading them all at once. Limit and Offset are ignored.
+	Export(ctx context.Context, p ProductListParams, fn func(domain.Product) error) error
+	// ListLowStock lists products at or below their reorder point, most
+	// urgent first.
+	ListLowStock(ctx context.Context, p ListParams) ([]domain.Product, error)
 	Update(ctx context.Context, id int, p domain.Product) (domain.Product, error)
 	Delete(ctx context.Context, id int) error
+	// SaveAll creates the products without an ID and updates the rest, all
+	// or nothing.
+	SaveAll(ctx context.Context, items []domain.Product) ([]domain.Product, error)
+	// ApplyBatch locks the products ops change, passes every operation
+	// through check and applies the ones that pass together, returning for
+	// each the saved product (zero for deletes) or its error. In atomic mode
+	// nothing is applied unless every operation passes; a failure while
+	// applying fails the whole batch.
+	ApplyBatch(ctx context.Context, ops []domain.ProductBatchOp, atomic bool, check BatchCheck) ([]domain.Product, []error, error)
+
+	// AdjustStock applies stock changes atomically, keeping location levels
+	// and product totals in step.
+	AdjustStock(ctx context.Context, changes []domain.StockChange) error
+	// StockByLocation lists the per-location levels of one product.
+	StockByLocation(ctx context.Context, productID int) ([]domain.StockLevel, error)
+	ListStock(ctx context.Context, p StockListParams) ([]domain.StockLevel, error)
+	// CostLayers lists the receipts of a product that still have stock,
+	// oldest first.
+	CostLayers(ctx context.Context, productID int) ([]domain.CostLayer, error)
 }
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+)
+
+type PurchaseOrderRepository interface {
+	Create(ctx context.Context, po domain.PurchaseOrder) (domain.PurchaseOrder, error)
+	GetByID(ctx context.Context, id int) (domain.PurchaseOrder, error)
+	List(ctx context.Context, p ListParams) ([]domain.PurchaseOrder, error)
+	// Update replaces the supplier, notes and lines of a draft order.
+	Update(ctx context.Context, id int, po domain.PurchaseOrder) (domain.PurchaseOrder, error)
+	// Transition moves the order to a new status without receiving stock.
+	Transition(ctx context.Context, id int, next domain.PurchaseOrderStatus) (domain.PurchaseOrder, error)
+	// Receive records a delivery and increases product stock atomically.
+	Receive(ctx context.Context, id int, lines []domain.ReceiveLine) (domain.PurchaseOrder, error)
+	// Purchasing summarises the purchase history of the given products.
+	// Products never ordered are absent from the result.
+	Purchasing(ctx context.Context, productIDs []int) (map[int]domain.ProductPurchasing, error)
+}
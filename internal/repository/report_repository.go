@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+)
+
+type ReportRepository interface {
+	// SalesByPeriod returns one entry per period that has orders, oldest first.
+	SalesByPeriod(ctx context.Context, p domain.SalesReportParams) ([]domain.SalesPeriod, error)
+	// TopProducts returns up to p.Top products ranked by revenue, or by
+	// quantity sold when byQuantity is set.
+	TopProducts(ctx context.Context, p domain.SalesReportParams, byQuantity bool) ([]domain.ProductSales, error)
+	// SalesByCategory returns the revenue of every category with sales,
+	// highest first.
+	SalesByCategory(ctx context.Context, p domain.SalesReportParams) ([]domain.CategorySales, error)
+
+	// InventoryValuation values the stock on hand of every product, or of
+	// every variant for products that have them.
+	InventoryValuation(ctx context.Context) ([]domain.ProductValuation, error)
+	// CostOfSalesByPeriod returns revenue and cost of goods sold per period
+	// with sales, oldest first.
+	CostOfSalesByPeriod(ctx context.Context, p domain.SalesReportParams) ([]domain.SalesCost, error)
+	// CostOfSalesByCategory returns revenue and cost of goods sold per
+	// category with sales.
+	CostOfSalesByCategory(ctx context.Context, p domain.SalesReportParams) ([]domain.SalesCost, error)
+}
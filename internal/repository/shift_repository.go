@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+)
+
+type ShiftRepository interface {
+	// Open starts a shift; a register can only have one open shift at a time.
+	Open(ctx context.Context, s domain.Shift) (domain.Shift, error)
+	GetByID(ctx context.Context, id int) (domain.Shift, error)
+	List(ctx context.Context, p ListParams) ([]domain.Shift, error)
+	AddCashMovement(ctx context.Context, m domain.CashMovement) (domain.CashMovement, error)
+	CashMovements(ctx context.Context, shiftID int) ([]domain.CashMovement, error)
+	// Close records the counted cash against the expected cash and closes
+	// the shift. No more cash movements can be added to it, nor orders but
+	// those made before it closed and pushed after working offline.
+	Close(ctx context.Context, id int, countedCash int) (domain.Shift, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+)
+
+type StockTransferRepository interface {
+	// Create records the transfer and takes its stock out of the source
+	// location atomically.
+	Create(ctx context.Context, t domain.StockTransfer) (domain.StockTransfer, error)
+	GetByID(ctx context.Context, id int) (domain.StockTransfer, error)
+	List(ctx context.Context, p ListParams) ([]domain.StockTransfer, error)
+	// Complete receives or cancels an in-transit transfer and puts its stock
+	// down at the destination or back at the source.
+	Complete(ctx context.Context, id int, next domain.StockTransferStatus) (domain.StockTransfer, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+)
+
+type SupplierRepository interface {
+	Create(ctx context.Context, s domain.Supplier) (domain.Supplier, error)
+	GetByID(ctx context.Context, id int) (domain.Supplier, error)
+	List(ctx context.Context, p ListParams) ([]domain.Supplier, error)
+	Update(ctx context.Context, id int, s domain.Supplier) (domain.Supplier, error)
+	Delete(ctx context.Context, id int) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+)
+
+// SyncRepository serves catalog changes to terminals that work offline.
+type SyncRepository interface {
+	// Changes returns the products and categories changed or deleted after
+	// the since watermark, oldest change first. When more than limit
+	// changes are waiting, HasMore is set and Watermark is where the rest
+	// start; a pull may run past limit to finish the changes made together.
+	Changes(ctx context.Context, since, limit int) (domain.CatalogChanges, error)
+}
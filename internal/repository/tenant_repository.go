@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+)
+
+// TenantRepository keeps the tenants themselves. Unlike the other
+// repositories it is not scoped to the tenant of the context.
+type TenantRepository interface {
+	Create(ctx context.Context, t domain.Tenant) (domain.Tenant, error)
+	GetByID(ctx context.Context, id int) (domain.Tenant, error)
+	GetBySlug(ctx context.Context, slug string) (domain.Tenant, error)
+	List(ctx context.Context, p ListParams) ([]domain.Tenant, error)
+	// ListActiveIDs returns the IDs of the tenants that are not suspended,
+	// for background work done for each of them.
+	ListActiveIDs(ctx context.Context) ([]int, error)
+	Update(ctx context.Context, id int, t domain.Tenant) (domain.Tenant, error)
+}
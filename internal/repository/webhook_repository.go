@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"pos-api/internal/domain"
+	"time"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, s domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	GetByID(ctx context.Context, id int) (domain.WebhookSubscription, error)
+	List(ctx context.Context, p ListParams) ([]domain.WebhookSubscription, error)
+	// Update replaces the subscription, keeping its secret when s has none.
+	Update(ctx context.Context, id int, s domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	Delete(ctx context.Context, id int) error
+
+	// Enqueue adds a pending delivery of e for every subscription that wants
+	// it, due now, and returns how many it added. A subscription that already
+	// has a delivery of e gets no other.
+	Enqueue(ctx context.Context, e domain.Event) (int, error)
+	// ClaimDue returns up to limit pending deliveries due at now, oldest
+	// first, and postpones them by lease so that no other dispatcher takes
+	// them while they are being sent.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error)
+	// SaveAttempt stores the status, attempts, next attempt, last response and
+	// delivery time of d.
+	SaveAttempt(ctx context.Context, d domain.WebhookDelivery) error
+	// Deliveries lists the deliveries of a subscription, newest first.
+	Deliveries(ctx context.Context, p WebhookDeliveryListParams) ([]domain.WebhookDelivery, error)
+	// Redeliver adds a pending copy of a delivery of the subscription, due now.
+	Redeliver(ctx context.Context, subscriptionID, id int) (domain.WebhookDelivery, error)
+}
@@ -0,0 +1,148 @@
+package repository_memory
+
+import (
+	"context"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"pos-api/internal/tenant"
+	"sort"
+	"sync"
+	"time"
+)
+
+// APIKeyRepo keeps every tenant's API keys in one map, as keys are looked up
+// by prefix before the tenant of a request is known.
+type APIKeyRepo struct {
+	mu     sync.RWMutex
+	nextID int
+	keys   map[int]domain.APIKey
+}
+
+func NewAPIKeyRepo() *APIKeyRepo {
+	return &APIKeyRepo{nextID: 1, keys: make(map[int]domain.APIKey)}
+}
+
+func (r *APIKeyRepo) Create(ctx context.Context, k domain.APIKey) (domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.keys {
+		if existing.Prefix == k.Prefix {
+			return domain.APIKey{}, domain.ErrDuplicate
+		}
+	}
+	k.ID = r.nextID
+	r.nextID++
+	k.Scopes = append([]string{}, k.Scopes...)
+	k.ExpiresAt = cloneTime(k.ExpiresAt)
+	k.LastUsedAt = nil
+	k.RevokedAt = nil
+	k.CreatedAt = time.Now().UTC()
+
+	r.keys[k.ID] = k
+	return cloneAPIKey(k), nil
+}
+
+func (r *APIKeyRepo) GetByID(ctx context.Context, id int) (domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	k, ok := r.keys[id]
+	if !ok || k.TenantID != tenant.From(ctx) {
+		return domain.APIKey{}, domain.ErrNotFound
+	}
+	return cloneAPIKey(k), nil
+}
+
+func (r *APIKeyRepo) GetByPrefix(ctx context.Context, prefix string) (domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.Prefix == prefix {
+			return cloneAPIKey(k), nil
+		}
+	}
+	return domain.APIKey{}, domain.ErrNotFound
+}
+
+func (r *APIKeyRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	own := tenant.From(ctx)
+	ids := make([]int, 0, len(r.keys))
+	for id, k := range r.keys {
+		if k.TenantID == own {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(ids) {
+		return []domain.APIKey{}, nil
+	}
+
+	end := offset + limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	out := make([]domain.APIKey, 0, end-offset)
+	for _, id := range ids[offset:end] {
+		out = append(out, cloneAPIKey(r.keys[id]))
+	}
+	return out, nil
+}
+
+func (r *APIKeyRepo) Revoke(ctx context.Context, id int, at time.Time) (domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[id]
+	if !ok || k.TenantID != tenant.From(ctx) {
+		return domain.APIKey{}, domain.ErrNotFound
+	}
+	if k.RevokedAt == nil {
+		k.RevokedAt = &at
+		r.keys[id] = k
+	}
+	return cloneAPIKey(k), nil
+}
+
+func (r *APIKeyRepo) Touch(ctx context.Context, id int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	k.LastUsedAt = &at
+	r.keys[id] = k
+	return nil
+}
+
+func cloneAPIKey(k domain.APIKey) domain.APIKey {
+	k.Scopes = append([]string{}, k.Scopes...)
+	k.ExpiresAt = cloneTime(k.ExpiresAt)
+	k.LastUsedAt = cloneTime(k.LastUsedAt)
+	k.RevokedAt = cloneTime(k.RevokedAt)
+	return k
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := *t
+	return &v
+}
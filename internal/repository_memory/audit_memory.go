@@ -0,0 +1,105 @@
+package repository_memory
+
+import (
+	"context"
+	"pos-api/internal/actor"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"sync"
+	"time"
+)
+
+// AuditRepo holds the audit log written by the product and category
+// repositories. They take its lock while holding their own, never the other
+// way round.
+type AuditRepo struct {
+	mu      sync.RWMutex
+	nextID  int
+	entries []domain.AuditEntry
+
+	tenants *partitions[AuditRepo]
+}
+
+func newAuditRepo() *AuditRepo {
+	return &AuditRepo{nextID: 1}
+}
+
+func NewAuditRepo() *AuditRepo {
+	r := newAuditRepo()
+	r.tenants = partitioned(func(context.Context) *AuditRepo {
+		return newAuditRepo()
+	})
+	return r
+}
+
+func (r *AuditRepo) of(ctx context.Context) *AuditRepo {
+	return r.tenants.of(ctx, r)
+}
+
+// record adds a change to the log, attributed to the actor of ctx.
+func (r *AuditRepo) record(ctx context.Context, action domain.AuditAction, entityType string, entityID int, before, after any) error {
+	e, err := domain.NewAuditEntry(action, entityType, entityID, before, after)
+	if err != nil {
+		return err
+	}
+	by := actor.From(ctx)
+	e.Actor = by.Name
+	e.RequestID = by.RequestID
+	e.IP = by.IP
+	e.CreatedAt = time.Now().UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e.ID = r.nextID
+	r.nextID++
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+// mark returns the length of the log, for truncate to return to.
+func (r *AuditRepo) mark() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.entries)
+}
+
+// truncate drops the entries recorded since mark returned n.
+func (r *AuditRepo) truncate(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = r.entries[:n]
+}
+
+func (r *AuditRepo) List(ctx context.Context, lp repository.AuditListParams) ([]domain.AuditEntry, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	out := make([]domain.AuditEntry, 0)
+	for i := len(r.entries) - 1; i >= 0 && len(out) < limit; i-- {
+		e := r.entries[i]
+		if lp.EntityType != "" && e.EntityType != lp.EntityType ||
+			lp.EntityID != 0 && e.EntityID != lp.EntityID ||
+			lp.Actor != "" && e.Actor != lp.Actor {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
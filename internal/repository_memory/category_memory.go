@@ -2,7 +2,6 @@ package repository_memory
 
 import (
 	"context"
-	"errors"
 	"pos-api/internal/domain"
 	"pos-api/internal/repository"
 	"sort"
@@ -15,15 +14,35 @@ type CategoryRepo struct {
 	mu         sync.RWMutex
 	nextID     int
 	categories map[int]domain.Category
+	audit      *AuditRepo
+	outbox     *OutboxRepo
+	changes    *ChangeLog
+
+	tenants *partitions[CategoryRepo]
 }
 
-func NewCategoryRepo() *CategoryRepo {
+func newCategoryRepo(audit *AuditRepo, outbox *OutboxRepo, changes *ChangeLog) *CategoryRepo {
 	return &CategoryRepo{
 		nextID:     1,
 		categories: make(map[int]domain.Category),
+		audit:      audit,
+		outbox:     outbox,
+		changes:    changes,
 	}
 }
 
+func NewCategoryRepo(audit *AuditRepo, outbox *OutboxRepo, changes *ChangeLog) *CategoryRepo {
+	r := newCategoryRepo(audit, outbox, changes)
+	r.tenants = partitioned(func(ctx context.Context) *CategoryRepo {
+		return newCategoryRepo(audit.of(ctx), outbox.of(ctx), changes.of(ctx))
+	})
+	return r
+}
+
+func (r *CategoryRepo) of(ctx context.Context) *CategoryRepo {
+	return r.tenants.of(ctx, r)
+}
+
 func (r *CategoryRepo) Seed(items []domain.Category) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -32,6 +51,7 @@ func (r *CategoryRepo) Seed(items []domain.Category) {
 
 	maxID := 0
 	for _, p := range items {
+		p.ChangeSeq = r.changes.next()
 		r.categories[p.ID] = p
 		if p.ID > maxID {
 			maxID = p.ID
@@ -40,34 +60,77 @@ func (r *CategoryRepo) Seed(items []domain.Category) {
 	r.nextID = maxID + 1
 }
 func (r *CategoryRepo) Create(ctx context.Context, p domain.Category) (domain.Category, error) {
+	r = r.of(ctx)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	return r.create(ctx, p)
+}
+
+func (r *CategoryRepo) create(ctx context.Context, p domain.Category) (domain.Category, error) {
 	now := time.Now().UTC()
 
 	p.ID = r.nextID
 	r.nextID++
 
 	p.Name = strings.TrimSpace(p.Name)
+	p.ChangeSeq = r.changes.next()
 	p.CreatedAt = now
 	p.UpdatedAt = now
 
 	r.categories[p.ID] = p
+	if err := r.recordChange(ctx, domain.AuditCreate, nil, &p); err != nil {
+		return domain.Category{}, err
+	}
 	return p, nil
 }
 
 func (r *CategoryRepo) GetByID(ctx context.Context, id int) (domain.Category, error) {
+	r = r.of(ctx)
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	p, ok := r.categories[id]
 	if !ok {
-		return domain.Category{}, errors.New("not found")
+		return domain.Category{}, domain.ErrNotFound
 	}
 	return p, nil
 }
 
+// FindByName lists the categories with the given name, ignoring case.
+func (r *CategoryRepo) GetByIDs(ctx context.Context, ids []int) ([]domain.Category, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]domain.Category, 0, len(ids))
+	for _, id := range ids {
+		if c, ok := r.categories[id]; ok {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (r *CategoryRepo) FindByName(ctx context.Context, name string) ([]domain.Category, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name = strings.TrimSpace(name)
+	out := make([]domain.Category, 0)
+	for _, c := range r.categories {
+		if strings.EqualFold(c.Name, name) {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
 func (r *CategoryRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Category, error) {
+	r = r.of(ctx)
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -102,30 +165,113 @@ func (r *CategoryRepo) List(ctx context.Context, lp repository.ListParams) ([]do
 	return out, nil
 }
 
+// Export copies the categories under the lock and calls fn once it is
+// released.
+func (r *CategoryRepo) Export(ctx context.Context, fn func(domain.Category) error) error {
+	r = r.of(ctx)
+	r.mu.RLock()
+	items := make([]domain.Category, 0, len(r.categories))
+	for _, c := range r.categories {
+		items = append(items, c)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	for _, c := range items {
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *CategoryRepo) Update(ctx context.Context, id int, patch domain.Category) (domain.Category, error) {
+	r = r.of(ctx)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	return r.update(ctx, id, patch)
+}
+
+func (r *CategoryRepo) update(ctx context.Context, id int, patch domain.Category) (domain.Category, error) {
 	existing, ok := r.categories[id]
 	if !ok {
-		return domain.Category{}, errors.New("not found")
+		return domain.Category{}, domain.ErrNotFound
 	}
+	before := existing
 
 	existing.Name = strings.TrimSpace(patch.Name)
 	existing.Description = strings.TrimSpace(patch.Description)
+	existing.ChangeSeq = r.changes.next()
 	existing.UpdatedAt = time.Now().UTC()
 
 	r.categories[id] = existing
+	if err := r.recordChange(ctx, domain.AuditUpdate, &before, &existing); err != nil {
+		return domain.Category{}, err
+	}
 	return existing, nil
 }
 
+// SaveAll creates the categories without an ID and updates the rest. It
+// saves nothing if any of the updated categories is missing.
+func (r *CategoryRepo) SaveAll(ctx context.Context, items []domain.Category) ([]domain.Category, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range items {
+		if _, ok := r.categories[c.ID]; c.ID != 0 && !ok {
+			return nil, domain.ErrNotFound
+		}
+	}
+
+	out := make([]domain.Category, 0, len(items))
+	for _, c := range items {
+		var saved domain.Category
+		var err error
+		if c.ID == 0 {
+			saved, err = r.create(ctx, c)
+		} else {
+			saved, err = r.update(ctx, c.ID, c)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, saved)
+	}
+	return out, nil
+}
+
 func (r *CategoryRepo) Delete(ctx context.Context, id int) error {
+	r = r.of(ctx)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.categories[id]; !ok {
-		return errors.New("not found")
+	existing, ok := r.categories[id]
+	if !ok {
+		return domain.ErrNotFound
 	}
 	delete(r.categories, id)
+	r.changes.deleted(domain.AuditCategory, id)
+	return r.recordChange(ctx, domain.AuditDelete, &existing, nil)
+}
+
+// recordChange writes the audit entry and events for a category going from
+// before to after.
+func (r *CategoryRepo) recordChange(ctx context.Context, action domain.AuditAction, before, after *domain.Category) error {
+	var id int
+	if after != nil {
+		id = after.ID
+	} else {
+		id = before.ID
+	}
+	if err := r.audit.record(ctx, action, domain.AuditCategory, id, before, after); err != nil {
+		return err
+	}
+	events, err := domain.CategoryEvents(before, after)
+	if err != nil {
+		return err
+	}
+	r.outbox.record(ctx, events)
 	return nil
 }
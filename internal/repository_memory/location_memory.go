@@ -0,0 +1,134 @@
+package repository_memory
+
+import (
+	"context"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type LocationRepo struct {
+	mu        sync.RWMutex
+	nextID    int
+	locations map[int]domain.Location
+
+	tenants *partitions[LocationRepo]
+}
+
+func newLocationRepo() *LocationRepo {
+	return &LocationRepo{
+		nextID:    1,
+		locations: make(map[int]domain.Location),
+	}
+}
+
+func NewLocationRepo() *LocationRepo {
+	r := newLocationRepo()
+	r.tenants = partitioned(func(context.Context) *LocationRepo {
+		return newLocationRepo()
+	})
+	return r
+}
+
+func (r *LocationRepo) of(ctx context.Context) *LocationRepo {
+	return r.tenants.of(ctx, r)
+}
+
+func (r *LocationRepo) Create(ctx context.Context, l domain.Location) (domain.Location, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	l.ID = r.nextID
+	r.nextID++
+
+	l.Name = strings.TrimSpace(l.Name)
+	l.CreatedAt = now
+	l.UpdatedAt = now
+
+	r.locations[l.ID] = l
+	return l, nil
+}
+
+func (r *LocationRepo) GetByID(ctx context.Context, id int) (domain.Location, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	l, ok := r.locations[id]
+	if !ok {
+		return domain.Location{}, domain.ErrNotFound
+	}
+	return l, nil
+}
+
+func (r *LocationRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Location, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int, 0, len(r.locations))
+	for id := range r.locations {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(ids) {
+		return []domain.Location{}, nil
+	}
+
+	end := offset + limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	out := make([]domain.Location, 0, end-offset)
+	for _, id := range ids[offset:end] {
+		out = append(out, r.locations[id])
+	}
+	return out, nil
+}
+
+func (r *LocationRepo) Update(ctx context.Context, id int, patch domain.Location) (domain.Location, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.locations[id]
+	if !ok {
+		return domain.Location{}, domain.ErrNotFound
+	}
+
+	existing.Name = strings.TrimSpace(patch.Name)
+	existing.Address = strings.TrimSpace(patch.Address)
+	existing.UpdatedAt = time.Now().UTC()
+
+	r.locations[id] = existing
+	return existing, nil
+}
+
+func (r *LocationRepo) Delete(ctx context.Context, id int) error {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.locations[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.locations, id)
+	return nil
+}
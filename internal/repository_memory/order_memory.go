@@ -0,0 +1,197 @@
+package repository_memory
+
+import (
+	"context"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type OrderRepo struct {
+	mu         sync.RWMutex
+	nextID     int
+	nextItemID int
+	orders     map[int]domain.Order
+	byClientID map[string]int
+	products   *ProductRepo
+	shifts     *ShiftRepo
+
+	tenants *partitions[OrderRepo]
+}
+
+func newOrderRepo(products *ProductRepo, shifts *ShiftRepo) *OrderRepo {
+	r := &OrderRepo{
+		nextID:     1,
+		nextItemID: 1,
+		orders:     make(map[int]domain.Order),
+		byClientID: make(map[string]int),
+		products:   products,
+		shifts:     shifts,
+	}
+	shifts.sales = r.salesByShift
+	return r
+}
+
+// NewOrderRepo also lets shifts total their orders when they close.
+func NewOrderRepo(products *ProductRepo, shifts *ShiftRepo) *OrderRepo {
+	r := newOrderRepo(products, shifts)
+	r.tenants = partitioned(func(ctx context.Context) *OrderRepo {
+		return newOrderRepo(products.of(ctx), shifts.of(ctx))
+	})
+	return r
+}
+
+func (r *OrderRepo) of(ctx context.Context) *OrderRepo {
+	return r.tenants.of(ctx, r)
+}
+
+func (r *OrderRepo) Create(ctx context.Context, o domain.Order) (domain.Order, error) {
+	r = r.of(ctx)
+
+	// Holding the shift lock keeps the shift from closing between the
+	// status check and the insert.
+	if o.ShiftID != 0 {
+		r.shifts.mu.RLock()
+		defer r.shifts.mu.RUnlock()
+
+		s, ok := r.shifts.shifts[o.ShiftID]
+		if !ok {
+			return domain.Order{}, fmt.Errorf("%w: shift %d", domain.ErrNotFound, o.ShiftID)
+		}
+		if err := s.CheckSale(o.CreatedAt); err != nil {
+			return domain.Order{}, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o.ClientID = strings.TrimSpace(o.ClientID)
+	if _, ok := r.byClientID[o.ClientID]; ok && o.ClientID != "" {
+		return domain.Order{}, fmt.Errorf("%w: order with client_id %q already exists", domain.ErrDuplicate, o.ClientID)
+	}
+
+	costs, err := r.products.applyStock(ctx, o.StockChanges(), true)
+	if err != nil {
+		return domain.Order{}, err
+	}
+
+	o.ID = r.nextID
+	r.nextID++
+
+	o.RegisterID = strings.TrimSpace(o.RegisterID)
+	o.UserID = strings.TrimSpace(o.UserID)
+	o.Items = append([]domain.OrderItem{}, o.Items...)
+	for i := range o.Items {
+		o.Items[i].ID = r.nextItemID
+		o.Items[i].Cost = costs[i]
+		r.nextItemID++
+	}
+	o.Tenders = append([]domain.Tender{}, o.Tenders...)
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = time.Now()
+	}
+	o.CreatedAt = o.CreatedAt.UTC()
+
+	r.orders[o.ID] = o
+	if o.ClientID != "" {
+		r.byClientID[o.ClientID] = o.ID
+	}
+	return cloneOrder(o), nil
+}
+
+func (r *OrderRepo) GetByID(ctx context.Context, id int) (domain.Order, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	o, ok := r.orders[id]
+	if !ok {
+		return domain.Order{}, domain.ErrNotFound
+	}
+	return cloneOrder(o), nil
+}
+
+func (r *OrderRepo) GetByClientID(ctx context.Context, clientID string) (domain.Order, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byClientID[strings.TrimSpace(clientID)]
+	if !ok {
+		return domain.Order{}, domain.ErrNotFound
+	}
+	return cloneOrder(r.orders[id]), nil
+}
+
+func (r *OrderRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Order, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int, 0, len(r.orders))
+	for id := range r.orders {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(ids) {
+		return []domain.Order{}, nil
+	}
+
+	end := offset + limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	out := make([]domain.Order, 0, end-offset)
+	for _, id := range ids[offset:end] {
+		out = append(out, cloneOrder(r.orders[id]))
+	}
+	return out, nil
+}
+
+func (r *OrderRepo) SalesByShift(ctx context.Context, shiftID int) (domain.ShiftSales, error) {
+	r = r.of(ctx)
+	return r.salesByShift(shiftID), nil
+}
+
+func (r *OrderRepo) salesByShift(shiftID int) domain.ShiftSales {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sales := domain.ShiftSales{Tenders: make(map[domain.TenderMethod]int)}
+	for _, o := range r.orders {
+		if o.ShiftID != shiftID {
+			continue
+		}
+		sales.OrderCount++
+		sales.Subtotal += o.Subtotal
+		sales.Tax += o.Tax
+		sales.Total += o.Total
+		sales.Change += o.Change
+		for _, t := range o.Tenders {
+			sales.Tenders[t.Method] += t.Amount
+		}
+	}
+	return sales
+}
+
+func cloneOrder(o domain.Order) domain.Order {
+	o.Items = append([]domain.OrderItem{}, o.Items...)
+	o.Tenders = append([]domain.Tender{}, o.Tenders...)
+	return o
+}
@@ -0,0 +1,167 @@
+package repository_memory
+
+import (
+	"cmp"
+	"context"
+	"pos-api/internal/actor"
+	"pos-api/internal/domain"
+	"slices"
+	"sync"
+	"time"
+)
+
+// OutboxRepo holds the events recorded by the product and category
+// repositories. They take its lock while holding their own, never the other
+// way round.
+type OutboxRepo struct {
+	// relay serializes Relay, so no event is passed to two relays at once.
+	relay sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int
+	entries []outboxEntry
+	// head is the index of the oldest event not yet delivered.
+	head int
+	// held is the index from which events belong to a batch that may still
+	// be rolled back, or -1. Relay leaves them alone.
+	held int
+	// delivered holds the indexes of the delivered entries in the order
+	// they were delivered, which is their Seq order.
+	delivered []int
+	nextSeq   int
+
+	tenants *partitions[OutboxRepo]
+}
+
+type outboxEntry struct {
+	event       domain.Event
+	attempts    int
+	nextAttempt time.Time
+	delivered   bool
+}
+
+func newOutboxRepo() *OutboxRepo {
+	return &OutboxRepo{nextID: 1, held: -1, nextSeq: 1}
+}
+
+func NewOutboxRepo() *OutboxRepo {
+	r := newOutboxRepo()
+	r.tenants = partitioned(func(context.Context) *OutboxRepo {
+		return newOutboxRepo()
+	})
+	return r
+}
+
+func (r *OutboxRepo) of(ctx context.Context) *OutboxRepo {
+	return r.tenants.of(ctx, r)
+}
+
+// record adds events to the outbox, attributed to the actor of ctx.
+func (r *OutboxRepo) record(ctx context.Context, events []domain.Event) {
+	by := actor.From(ctx)
+	now := time.Now().UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range events {
+		e.ID = r.nextID
+		r.nextID++
+		e.Actor = by.Name
+		e.RequestID = by.RequestID
+		e.OccurredAt = now
+		r.entries = append(r.entries, outboxEntry{event: e, nextAttempt: now})
+	}
+}
+
+// mark holds back the events recorded from now on until release or
+// truncate, and returns the length of the outbox for truncate to return to.
+func (r *OutboxRepo) mark() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.held = len(r.entries)
+	return r.held
+}
+
+// release lets Relay have the events recorded since mark.
+func (r *OutboxRepo) release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.held = -1
+}
+
+// truncate drops the events recorded since mark returned n.
+func (r *OutboxRepo) truncate(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = r.entries[:n]
+	r.held = -1
+}
+
+func (r *OutboxRepo) Delivered(ctx context.Context, afterSeq, limit int) ([]domain.Event, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, _ := slices.BinarySearchFunc(r.delivered, afterSeq+1, func(i, seq int) int {
+		return cmp.Compare(r.entries[i].event.Seq, seq)
+	})
+	out := make([]domain.Event, 0)
+	for ; i < len(r.delivered) && len(out) < limit; i++ {
+		out = append(out, r.entries[r.delivered[i]].event)
+	}
+	return out, nil
+}
+
+func (r *OutboxRepo) Relay(ctx context.Context, limit int, fn func(domain.Event) error) (int, error) {
+	r = r.of(ctx)
+	r.relay.Lock()
+	defer r.relay.Unlock()
+
+	r.mu.Lock()
+	now := time.Now()
+	end := len(r.entries)
+	if r.held >= 0 {
+		end = r.held
+	}
+	due := make([]int, 0)
+	for i := r.head; i < end && len(due) < limit; i++ {
+		if e := r.entries[i]; !e.delivered && !e.nextAttempt.After(now) {
+			due = append(due, i)
+		}
+	}
+	events := make([]domain.Event, len(due))
+	for j, i := range due {
+		events[j] = r.entries[i].event
+	}
+	r.mu.Unlock()
+
+	// Entries before held are never truncated, so the indexes stay valid.
+	for j, i := range due {
+		r.mu.Lock()
+		events[j].Seq = r.nextSeq
+		r.nextSeq++
+		r.mu.Unlock()
+
+		err := fn(events[j])
+
+		r.mu.Lock()
+		e := &r.entries[i]
+		e.attempts++
+		if err != nil {
+			e.nextAttempt = time.Now().Add(min(10*time.Minute, time.Second<<min(e.attempts, 10)))
+		} else {
+			e.event.Seq = events[j].Seq
+			e.delivered = true
+			r.delivered = append(r.delivered, i)
+		}
+		for r.head < len(r.entries) && r.entries[r.head].delivered {
+			r.head++
+		}
+		r.mu.Unlock()
+	}
+	return len(due), nil
+}
@@ -0,0 +1,43 @@
+package repository_memory
+
+import (
+	"context"
+	"pos-api/internal/tenant"
+	"sync"
+)
+
+// partitions gives each tenant its own copy of a repository. The repository
+// a constructor returns holds the default tenant's data, which is also what
+// a context without a tenant sees; the copies of other tenants are built by
+// build the first time they are asked for, over the same tenant's copies of
+// the repositories they depend on. IDs are counted per tenant.
+type partitions[T any] struct {
+	mu    sync.Mutex
+	parts map[int]*T
+	build func(ctx context.Context) *T
+}
+
+func partitioned[T any](build func(ctx context.Context) *T) *partitions[T] {
+	return &partitions[T]{parts: make(map[int]*T), build: build}
+}
+
+// of returns the copy of the tenant of ctx, or root for the default tenant.
+// It returns root too when p is nil, as it is in the copies themselves.
+func (p *partitions[T]) of(ctx context.Context, root *T) *T {
+	if p == nil {
+		return root
+	}
+	id := tenant.From(ctx)
+	if id == 0 || id == tenant.Default {
+		return root
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	part, ok := p.parts[id]
+	if !ok {
+		part = p.build(ctx)
+		p.parts[id] = part
+	}
+	return part
+}
@@ -0,0 +1,191 @@
+package repository_memory
+
+import (
+	"context"
+	"fmt"
+	"pos-api/internal/actor"
+	"pos-api/internal/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PriceRepo keeps scheduled prices and reads the price history recorded by
+// the product repository.
+type PriceRepo struct {
+	products *ProductRepo
+
+	mu        sync.Mutex
+	nextID    int
+	scheduled map[int]domain.ScheduledPrice
+
+	tenants *partitions[PriceRepo]
+}
+
+func newPriceRepo(products *ProductRepo) *PriceRepo {
+	return &PriceRepo{
+		products:  products,
+		nextID:    1,
+		scheduled: make(map[int]domain.ScheduledPrice),
+	}
+}
+
+func NewPriceRepo(products *ProductRepo) *PriceRepo {
+	r := newPriceRepo(products)
+	r.tenants = partitioned(func(ctx context.Context) *PriceRepo {
+		return newPriceRepo(products.of(ctx))
+	})
+	return r
+}
+
+func (r *PriceRepo) of(ctx context.Context) *PriceRepo {
+	return r.tenants.of(ctx, r)
+}
+
+func (r *PriceRepo) History(ctx context.Context, productID int) ([]domain.PriceChange, error) {
+	r = r.of(ctx)
+	r.products.mu.RLock()
+	defer r.products.mu.RUnlock()
+
+	out := make([]domain.PriceChange, 0)
+	for i := len(r.products.priceHistory) - 1; i >= 0; i-- {
+		if c := r.products.priceHistory[i]; c.ProductID == productID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (r *PriceRepo) Schedule(ctx context.Context, sp domain.ScheduledPrice) (domain.ScheduledPrice, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.products.mu.RLock()
+	_, ok := r.products.products[sp.ProductID]
+	r.products.mu.RUnlock()
+	if !ok {
+		return domain.ScheduledPrice{}, domain.ErrNotFound
+	}
+
+	sp.ID = r.nextID
+	r.nextID++
+	sp.Status = domain.ScheduledPricePending
+	sp.CreatedAt = time.Now().UTC()
+	sp.AppliedAt = nil
+	r.scheduled[sp.ID] = sp
+	return sp, nil
+}
+
+func (r *PriceRepo) Upcoming(ctx context.Context, productID int) ([]domain.ScheduledPrice, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]domain.ScheduledPrice, 0)
+	for _, sp := range r.scheduled {
+		if sp.ProductID == productID && sp.Status == domain.ScheduledPricePending {
+			out = append(out, sp)
+		}
+	}
+	sortScheduled(out)
+	return out, nil
+}
+
+func (r *PriceRepo) Cancel(ctx context.Context, productID, id int) (domain.ScheduledPrice, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sp, ok := r.scheduled[id]
+	if !ok || sp.ProductID != productID {
+		return domain.ScheduledPrice{}, domain.ErrNotFound
+	}
+	if sp.Status != domain.ScheduledPricePending {
+		return domain.ScheduledPrice{}, fmt.Errorf("%w: scheduled price %d is already %s", domain.ErrConflict, id, sp.Status)
+	}
+	sp.Status = domain.ScheduledPriceCancelled
+	r.scheduled[id] = sp
+	return sp, nil
+}
+
+// ApplyDue applies the due prices in order. Those of products deleted since
+// they were scheduled are cancelled; in Postgres they go with the product.
+func (r *PriceRepo) ApplyDue(ctx context.Context, now time.Time) ([]domain.ScheduledPrice, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := make([]domain.ScheduledPrice, 0)
+	for _, sp := range r.scheduled {
+		if sp.Status == domain.ScheduledPricePending && !sp.EffectiveFrom.After(now) {
+			due = append(due, sp)
+		}
+	}
+	sortScheduled(due)
+
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+
+	applied := make([]domain.ScheduledPrice, 0, len(due))
+	for _, sp := range due {
+		p, ok := r.products.products[sp.ProductID]
+		if !ok {
+			sp.Status = domain.ScheduledPriceCancelled
+			r.scheduled[sp.ID] = sp
+			continue
+		}
+		if p.Price != sp.Price {
+			old := p.Price
+			by := actor.Actor{Name: sp.Actor, Reason: sp.Reason}
+			p.Price = sp.Price
+			p.ChangeSeq = r.products.changes.next()
+			p.UpdatedAt = time.Now().UTC()
+			r.products.products[p.ID] = p
+			r.products.recordPrice(p.ID, &old, sp.Price, by, sp.ID)
+
+			e, err := domain.NewEvent(domain.EventProductPriceChanged, p.ID, domain.PriceChangedEvent{
+				ProductID:        p.ID,
+				OldPrice:         old,
+				NewPrice:         sp.Price,
+				ScheduledPriceID: sp.ID,
+			})
+			if err != nil {
+				return nil, err
+			}
+			r.products.outbox.record(actor.With(ctx, by), []domain.Event{e})
+		}
+
+		at := time.Now().UTC()
+		sp.Status = domain.ScheduledPriceApplied
+		sp.AppliedAt = &at
+		r.scheduled[sp.ID] = sp
+		applied = append(applied, sp)
+	}
+	return applied, nil
+}
+
+func (r *PriceRepo) NextDue(ctx context.Context) (time.Time, bool, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var next time.Time
+	found := false
+	for _, sp := range r.scheduled {
+		if sp.Status == domain.ScheduledPricePending && (!found || sp.EffectiveFrom.Before(next)) {
+			next, found = sp.EffectiveFrom, true
+		}
+	}
+	return next, found, nil
+}
+
+// sortScheduled orders scheduled prices by when they take effect.
+func sortScheduled(items []domain.ScheduledPrice) {
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].EffectiveFrom.Equal(items[j].EffectiveFrom) {
+			return items[i].EffectiveFrom.Before(items[j].EffectiveFrom)
+		}
+		return items[i].ID < items[j].ID
+	})
+}
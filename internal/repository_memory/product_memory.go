@@ -2,7 +2,9 @@ package repository_memory
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"maps"
+	"pos-api/internal/actor"
 	"pos-api/internal/domain"
 	"pos-api/internal/repository"
 	"sort"
@@ -12,68 +14,204 @@ import (
 )
 
 type ProductRepo struct {
-	mu       sync.RWMutex
-	nextID   int
-	products map[int]domain.Product
+	mu            sync.RWMutex
+	nextID        int
+	nextVariantID int
+	products      map[int]domain.Product
+	bySKU         map[string]int
+	byBarcode     map[string]int
+	byVariantSKU  map[string]int
+	stock         map[stockKey]domain.StockLevel
+	nextLayerID   int
+	costs         map[stockKey]domain.ProductCost
+	layers        map[stockKey][]domain.CostLayer
+	nextChangeID  int
+	priceHistory  []domain.PriceChange
+	audit         *AuditRepo
+	outbox        *OutboxRepo
+	changes       *ChangeLog
+
+	tenants *partitions[ProductRepo]
+}
+
+type stockKey struct {
+	locationID, productID, variantID int
 }
 
-func NewProductRepo() *ProductRepo {
+func newProductRepo(audit *AuditRepo, outbox *OutboxRepo, changes *ChangeLog) *ProductRepo {
 	return &ProductRepo{
-		nextID:   1,
-		products: make(map[int]domain.Product),
+		nextID:        1,
+		nextVariantID: 1,
+		products:      make(map[int]domain.Product),
+		bySKU:         make(map[string]int),
+		byBarcode:     make(map[string]int),
+		byVariantSKU:  make(map[string]int),
+		stock:         make(map[stockKey]domain.StockLevel),
+		nextLayerID:   1,
+		costs:         make(map[stockKey]domain.ProductCost),
+		layers:        make(map[stockKey][]domain.CostLayer),
+		nextChangeID:  1,
+		audit:         audit,
+		outbox:        outbox,
+		changes:       changes,
 	}
 }
 
+func NewProductRepo(audit *AuditRepo, outbox *OutboxRepo, changes *ChangeLog) *ProductRepo {
+	r := newProductRepo(audit, outbox, changes)
+	r.tenants = partitioned(func(ctx context.Context) *ProductRepo {
+		return newProductRepo(audit.of(ctx), outbox.of(ctx), changes.of(ctx))
+	})
+	return r
+}
+
+func (r *ProductRepo) of(ctx context.Context) *ProductRepo {
+	return r.tenants.of(ctx, r)
+}
+
 func (r *ProductRepo) Seed(items []domain.Product) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	r.products = make(map[int]domain.Product, len(items))
+	r.bySKU = make(map[string]int, len(items))
+	r.byBarcode = make(map[string]int, len(items))
+	r.byVariantSKU = make(map[string]int)
 
-	maxID := 0
+	maxID, maxVariantID := 0, 0
 	for _, p := range items {
+		p.ChangeSeq = r.changes.next()
 		r.products[p.ID] = p
+		r.index(p)
 		if p.ID > maxID {
 			maxID = p.ID
 		}
+		for _, v := range p.Variants {
+			if v.ID > maxVariantID {
+				maxVariantID = v.ID
+			}
+		}
 	}
 	r.nextID = maxID + 1
+	r.nextVariantID = maxVariantID + 1
 }
 func (r *ProductRepo) Create(ctx context.Context, p domain.Product) (domain.Product, error) {
+	r = r.of(ctx)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	return r.create(ctx, p)
+}
+
+func (r *ProductRepo) create(ctx context.Context, p domain.Product) (domain.Product, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	p.SKU = strings.TrimSpace(p.SKU)
+	p.Barcodes = append([]string{}, p.Barcodes...)
+	if err := r.checkUnique(0, p); err != nil {
+		return domain.Product{}, err
+	}
+
 	now := time.Now().UTC()
 
 	p.ID = r.nextID
 	r.nextID++
 
-	p.Name = strings.TrimSpace(p.Name)
+	p.Options = cloneOptions(p.Options)
+	p.Variants = r.assignVariantIDs(p.ID, p.Variants)
+	p.ChangeSeq = r.changes.next()
 	p.CreatedAt = now
 	p.UpdatedAt = now
 
 	r.products[p.ID] = p
-	return p, nil
+	r.index(p)
+	r.recordPrice(p.ID, nil, p.Price, actor.From(ctx), 0)
+	if err := r.recordChange(ctx, domain.AuditCreate, nil, &p); err != nil {
+		return domain.Product{}, err
+	}
+	return cloneProduct(p), nil
 }
 
 func (r *ProductRepo) GetByID(ctx context.Context, id int) (domain.Product, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return heldProducts{r}.GetByID(ctx, id)
+}
+
+func (r *ProductRepo) GetBySKU(ctx context.Context, sku string) (domain.Product, error) {
+	r = r.of(ctx)
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	p, ok := r.products[id]
+	return heldProducts{r}.GetBySKU(ctx, sku)
+}
+
+func (r *ProductRepo) GetByBarcode(ctx context.Context, barcode string) (domain.Product, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return heldProducts{r}.GetByBarcode(ctx, barcode)
+}
+
+// heldProducts reads the products of a repository whose lock the caller
+// already holds.
+type heldProducts struct {
+	r *ProductRepo
+}
+
+func (h heldProducts) GetByID(ctx context.Context, id int) (domain.Product, error) {
+	p, ok := h.r.products[id]
+	if !ok {
+		return domain.Product{}, domain.ErrNotFound
+	}
+	return cloneProduct(p), nil
+}
+
+func (h heldProducts) GetBySKU(ctx context.Context, sku string) (domain.Product, error) {
+	id, ok := h.r.bySKU[sku]
 	if !ok {
-		return domain.Product{}, errors.New("not found")
+		return domain.Product{}, domain.ErrNotFound
 	}
-	return p, nil
+	return cloneProduct(h.r.products[id]), nil
 }
 
-func (r *ProductRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Product, error) {
+func (h heldProducts) GetByBarcode(ctx context.Context, barcode string) (domain.Product, error) {
+	id, ok := h.r.byBarcode[barcode]
+	if !ok {
+		return domain.Product{}, domain.ErrNotFound
+	}
+	return cloneProduct(h.r.products[id]), nil
+}
+
+// FindByName lists the products with the given name, ignoring case.
+func (r *ProductRepo) FindByName(ctx context.Context, name string) ([]domain.Product, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name = strings.TrimSpace(name)
+	out := make([]domain.Product, 0)
+	for _, p := range r.products {
+		if strings.EqualFold(p.Name, name) {
+			out = append(out, cloneProduct(p))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (r *ProductRepo) List(ctx context.Context, lp repository.ProductListParams) ([]domain.Product, error) {
+	r = r.of(ctx)
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	ids := make([]int, 0, len(r.products))
-	for id := range r.products {
-		ids = append(ids, id)
+	for id, p := range r.products {
+		if matchesProduct(p, lp) {
+			ids = append(ids, id)
+		}
 	}
 
 	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
@@ -97,36 +235,671 @@ func (r *ProductRepo) List(ctx context.Context, lp repository.ListParams) ([]dom
 
 	out := make([]domain.Product, 0, end-offset)
 	for _, id := range ids[offset:end] {
-		out = append(out, r.products[id])
+		out = append(out, cloneProduct(r.products[id]))
+	}
+	return out, nil
+}
+
+func (r *ProductRepo) ListByCategories(ctx context.Context, categoryIDs []int, lp repository.ListParams) (map[int][]domain.Product, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	wanted := make(map[int]bool, len(categoryIDs))
+	for _, id := range categoryIDs {
+		wanted[id] = true
+	}
+	ids := make([]int, 0)
+	for id, p := range r.products {
+		if wanted[p.CategoryID] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+
+	seen := make(map[int]int, len(categoryIDs))
+	out := make(map[int][]domain.Product, len(categoryIDs))
+	for _, id := range ids {
+		p := r.products[id]
+		n := seen[p.CategoryID]
+		seen[p.CategoryID]++
+		if n >= offset && n < offset+limit {
+			out[p.CategoryID] = append(out[p.CategoryID], cloneProduct(p))
+		}
+	}
+	return out, nil
+}
+
+// Export copies the matching products under the lock and calls fn once it
+// is released, so a slow consumer does not hold up writers.
+func (r *ProductRepo) Export(ctx context.Context, lp repository.ProductListParams, fn func(domain.Product) error) error {
+	r = r.of(ctx)
+	r.mu.RLock()
+	items := make([]domain.Product, 0, len(r.products))
+	for _, p := range r.products {
+		if matchesProduct(p, lp) {
+			items = append(items, cloneProduct(p))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	for _, p := range items {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchesProduct(p domain.Product, lp repository.ProductListParams) bool {
+	if lp.CategoryID != 0 && p.CategoryID != lp.CategoryID {
+		return false
+	}
+	q := strings.ToLower(strings.TrimSpace(lp.Query))
+	return q == "" || strings.Contains(strings.ToLower(p.Name), q) || strings.Contains(strings.ToLower(p.SKU), q)
+}
+
+func (r *ProductRepo) ListLowStock(ctx context.Context, lp repository.ListParams) ([]domain.Product, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	low := make([]domain.Product, 0)
+	for _, p := range r.products {
+		if p.IsLowStock() {
+			low = append(low, p)
+		}
+	}
+
+	sort.Slice(low, func(i, j int) bool {
+		a, b := low[i].Quantity-low[i].ReorderPoint, low[j].Quantity-low[j].ReorderPoint
+		if a != b {
+			return a < b
+		}
+		return low[i].ID < low[j].ID
+	})
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(low) {
+		return []domain.Product{}, nil
+	}
+
+	end := offset + limit
+	if end > len(low) {
+		end = len(low)
+	}
+
+	out := make([]domain.Product, 0, end-offset)
+	for _, p := range low[offset:end] {
+		out = append(out, cloneProduct(p))
 	}
 	return out, nil
 }
 
 func (r *ProductRepo) Update(ctx context.Context, id int, patch domain.Product) (domain.Product, error) {
+	r = r.of(ctx)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	return r.update(ctx, id, patch)
+}
+
+func (r *ProductRepo) update(ctx context.Context, id int, patch domain.Product) (domain.Product, error) {
 	existing, ok := r.products[id]
 	if !ok {
-		return domain.Product{}, errors.New("not found")
+		return domain.Product{}, domain.ErrNotFound
+	}
+
+	patch.SKU = strings.TrimSpace(patch.SKU)
+	if err := r.checkUnique(id, patch); err != nil {
+		return domain.Product{}, err
+	}
+	r.unindex(existing)
+	before := cloneProduct(existing)
+
+	if existing.Price != patch.Price {
+		old := existing.Price
+		r.recordPrice(id, &old, patch.Price, actor.From(ctx), 0)
 	}
 
 	existing.Name = strings.TrimSpace(patch.Name)
+	existing.SKU = patch.SKU
+	existing.Barcodes = append([]string{}, patch.Barcodes...)
+	existing.CategoryID = patch.CategoryID
 	existing.Price = patch.Price
-	existing.Quantity = patch.Quantity
+	existing.ReorderPoint = patch.ReorderPoint
+	existing.ReorderQuantity = patch.ReorderQuantity
+	existing.Options = cloneOptions(patch.Options)
+	// Stock changes with stock movements, not with the product: keep the
+	// stored quantities.
+	variants := r.assignVariantIDs(id, patch.Variants)
+	for i, v := range variants {
+		if old, ok := before.Variant(v.ID); ok {
+			variants[i].Quantity = old.Quantity
+		}
+	}
+	existing.Variants = variants
+	if len(variants) > 0 {
+		existing.Quantity = domain.TotalQuantity(variants)
+	}
+	existing.ChangeSeq = r.changes.next()
 	existing.UpdatedAt = time.Now().UTC()
 
 	r.products[id] = existing
-	return existing, nil
+	r.index(existing)
+	if err := r.recordChange(ctx, domain.AuditUpdate, &before, &existing); err != nil {
+		return domain.Product{}, err
+	}
+	return cloneProduct(existing), nil
+}
+
+// SaveAll creates the products without an ID and updates the rest. If any
+// of them cannot be saved, the earlier ones are rolled back.
+func (r *ProductRepo) SaveAll(ctx context.Context, items []domain.Product) ([]domain.Product, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	undo := r.snapshot()
+	defer r.outbox.release()
+	out := make([]domain.Product, 0, len(items))
+	for _, p := range items {
+		var saved domain.Product
+		var err error
+		if p.ID == 0 {
+			saved, err = r.create(ctx, p)
+		} else {
+			saved, err = r.update(ctx, p.ID, p)
+		}
+		if err != nil {
+			r.restore(undo)
+			return nil, err
+		}
+		out = append(out, saved)
+	}
+	return out, nil
+}
+
+// ApplyBatch checks and applies ops under a single lock, checking them all
+// before applying any.
+func (r *ProductRepo) ApplyBatch(ctx context.Context, ops []domain.ProductBatchOp, atomic bool, check repository.BatchCheck) ([]domain.Product, []error, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := make([]domain.Product, len(ops))
+	errs := make([]error, len(ops))
+	checked := make([]domain.ProductBatchOp, len(ops))
+	for i, op := range ops {
+		switch op.Action {
+		case domain.BatchCreate, domain.BatchUpdate:
+			checked[i], errs[i] = check(ctx, heldProducts{r}, op)
+		case domain.BatchDelete:
+			if _, ok := r.products[op.ID]; !ok {
+				errs[i] = domain.ErrNotFound
+				break
+			}
+			checked[i], errs[i] = check(ctx, heldProducts{r}, op)
+		default:
+			errs[i] = fmt.Errorf("%w: unknown action %q", domain.ErrInvalid, op.Action)
+		}
+		if errs[i] != nil && atomic {
+			return saved, errs, nil
+		}
+	}
+
+	undo := r.snapshot()
+	defer r.outbox.release()
+	for i, op := range checked {
+		if errs[i] != nil {
+			continue
+		}
+		var err error
+		switch op.Action {
+		case domain.BatchCreate:
+			saved[i], err = r.create(ctx, op.Product)
+		case domain.BatchUpdate:
+			saved[i], err = r.update(ctx, op.ID, op.Product)
+		case domain.BatchDelete:
+			err = r.remove(ctx, op.ID)
+		}
+		if err != nil {
+			r.restore(undo)
+			return nil, nil, err
+		}
+	}
+	return saved, errs, nil
+}
+
+// productSnapshot holds what create, update and remove touch, so a failed
+// batch can be undone.
+type productSnapshot struct {
+	nextID, nextVariantID          int
+	products                       map[int]domain.Product
+	bySKU, byBarcode, byVariantSKU map[string]int
+	stock                          map[stockKey]domain.StockLevel
+	nextChangeID                   int
+	priceHistory                   []domain.PriceChange
+	audit, outbox, changes         int
+}
+
+func (r *ProductRepo) snapshot() productSnapshot {
+	return productSnapshot{
+		nextID:        r.nextID,
+		nextVariantID: r.nextVariantID,
+		products:      maps.Clone(r.products),
+		bySKU:         maps.Clone(r.bySKU),
+		byBarcode:     maps.Clone(r.byBarcode),
+		byVariantSKU:  maps.Clone(r.byVariantSKU),
+		stock:         maps.Clone(r.stock),
+		nextChangeID:  r.nextChangeID,
+		priceHistory:  r.priceHistory[:len(r.priceHistory):len(r.priceHistory)],
+		audit:         r.audit.mark(),
+		outbox:        r.outbox.mark(),
+		changes:       r.changes.mark(),
+	}
+}
+
+func (r *ProductRepo) restore(s productSnapshot) {
+	r.nextID = s.nextID
+	r.nextVariantID = s.nextVariantID
+	r.products = s.products
+	r.bySKU = s.bySKU
+	r.byBarcode = s.byBarcode
+	r.byVariantSKU = s.byVariantSKU
+	r.stock = s.stock
+	r.nextChangeID = s.nextChangeID
+	r.priceHistory = s.priceHistory
+	r.audit.truncate(s.audit)
+	r.outbox.truncate(s.outbox)
+	r.changes.truncate(s.changes)
+}
+
+// recordPrice appends a price change to the history.
+func (r *ProductRepo) recordPrice(productID int, old *int, price int, by actor.Actor, scheduledID int) {
+	r.priceHistory = append(r.priceHistory, domain.PriceChange{
+		ID:               r.nextChangeID,
+		ProductID:        productID,
+		OldPrice:         old,
+		NewPrice:         price,
+		Actor:            by.Name,
+		Reason:           by.Reason,
+		ScheduledPriceID: scheduledID,
+		ChangedAt:        time.Now().UTC(),
+	})
+	r.nextChangeID++
 }
 
 func (r *ProductRepo) Delete(ctx context.Context, id int) error {
+	r = r.of(ctx)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.products[id]; !ok {
-		return errors.New("not found")
+	return r.remove(ctx, id)
+}
+
+func (r *ProductRepo) remove(ctx context.Context, id int) error {
+	existing, ok := r.products[id]
+	if !ok {
+		return domain.ErrNotFound
 	}
+	r.unindex(existing)
 	delete(r.products, id)
+	r.changes.deleted(domain.AuditProduct, id)
+	for k := range r.stock {
+		if k.productID == id {
+			delete(r.stock, k)
+		}
+	}
+	return r.recordChange(ctx, domain.AuditDelete, &existing, nil)
+}
+
+// recordChange writes the audit entry and events for a product going from
+// before to after.
+func (r *ProductRepo) recordChange(ctx context.Context, action domain.AuditAction, before, after *domain.Product) error {
+	var id int
+	if after != nil {
+		id = after.ID
+	} else {
+		id = before.ID
+	}
+	if err := r.audit.record(ctx, action, domain.AuditProduct, id, before, after); err != nil {
+		return err
+	}
+	events, err := domain.ProductEvents(before, after)
+	if err != nil {
+		return err
+	}
+	r.outbox.record(ctx, events)
+	return nil
+}
+
+func (r *ProductRepo) AdjustStock(ctx context.Context, changes []domain.StockChange) error {
+	r = r.of(ctx)
+	_, err := r.applyStock(ctx, changes, true)
+	return err
+}
+
+func (r *ProductRepo) StockByLocation(ctx context.Context, productID int) ([]domain.StockLevel, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	if _, ok := r.products[productID]; !ok {
+		r.mu.RUnlock()
+		return nil, domain.ErrNotFound
+	}
+	r.mu.RUnlock()
+
+	return r.ListStock(ctx, repository.StockListParams{ProductID: productID, Limit: 200})
+}
+
+func (r *ProductRepo) ListStock(ctx context.Context, lp repository.StockListParams) ([]domain.StockLevel, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	levels := make([]domain.StockLevel, 0)
+	for k, l := range r.stock {
+		if lp.LocationID != 0 && k.locationID != lp.LocationID {
+			continue
+		}
+		if lp.ProductID != 0 && k.productID != lp.ProductID {
+			continue
+		}
+		levels = append(levels, l)
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		a, b := levels[i], levels[j]
+		if a.LocationID != b.LocationID {
+			return a.LocationID < b.LocationID
+		}
+		if a.ProductID != b.ProductID {
+			return a.ProductID < b.ProductID
+		}
+		return a.VariantID < b.VariantID
+	})
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(levels) {
+		return []domain.StockLevel{}, nil
+	}
+
+	end := offset + limit
+	if end > len(levels) {
+		end = len(levels)
+	}
+	return levels[offset:end], nil
+}
+
+// checkUnique rejects a SKU or barcode already used by another product.
+func (r *ProductRepo) checkUnique(id int, p domain.Product) error {
+	if p.SKU != "" {
+		if owner, ok := r.bySKU[p.SKU]; ok && owner != id {
+			return fmt.Errorf("%w: sku %q is already used by product %d", domain.ErrDuplicate, p.SKU, owner)
+		}
+	}
+	for _, code := range p.Barcodes {
+		if owner, ok := r.byBarcode[code]; ok && owner != id {
+			return fmt.Errorf("%w: barcode %q is already used by product %d", domain.ErrDuplicate, code, owner)
+		}
+	}
+	for _, v := range p.Variants {
+		if v.SKU == "" {
+			continue
+		}
+		if owner, ok := r.byVariantSKU[v.SKU]; ok && owner != id {
+			return fmt.Errorf("%w: variant sku %q is already used by product %d", domain.ErrDuplicate, v.SKU, owner)
+		}
+	}
 	return nil
 }
+
+func (r *ProductRepo) index(p domain.Product) {
+	if p.SKU != "" {
+		r.bySKU[p.SKU] = p.ID
+	}
+	for _, code := range p.Barcodes {
+		r.byBarcode[code] = p.ID
+	}
+	for _, v := range p.Variants {
+		if v.SKU != "" {
+			r.byVariantSKU[v.SKU] = p.ID
+		}
+	}
+}
+
+func (r *ProductRepo) unindex(p domain.Product) {
+	if p.SKU != "" {
+		delete(r.bySKU, p.SKU)
+	}
+	for _, code := range p.Barcodes {
+		delete(r.byBarcode, code)
+	}
+	for _, v := range p.Variants {
+		if v.SKU != "" {
+			delete(r.byVariantSKU, v.SKU)
+		}
+	}
+}
+
+func (r *ProductRepo) assignVariantIDs(productID int, variants []domain.ProductVariant) []domain.ProductVariant {
+	out := make([]domain.ProductVariant, 0, len(variants))
+	for _, v := range variants {
+		if v.ID == 0 {
+			v.ID = r.nextVariantID
+			r.nextVariantID++
+		}
+		v.ProductID = productID
+		out = append(out, cloneVariant(v))
+	}
+	return out
+}
+
+// applyStock applies several stock changes at once. Either every change is
+// applied or, if a product or variant is missing or a decrease would take
+// stock below zero, none are. Changes of zero are checked but skipped.
+// Product totals change only when total is set, so stock in transit between
+// locations still counts towards them; only then are cost layers updated,
+// and the cost of each change is returned.
+func (r *ProductRepo) applyStock(ctx context.Context, changes []domain.StockChange, total bool) ([]domain.CostBasis, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pending := make(map[stockKey]int)
+	totals := make(map[stockKey]int)
+	for _, c := range changes {
+		p, ok := r.products[c.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("%w: product %d", domain.ErrNotFound, c.ProductID)
+		}
+		if c.VariantID != 0 {
+			if _, ok := p.Variant(c.VariantID); !ok {
+				return nil, fmt.Errorf("%w: variant %d of product %d", domain.ErrNotFound, c.VariantID, c.ProductID)
+			}
+		} else if len(p.Variants) > 0 {
+			return nil, fmt.Errorf("%w: product %d has variants, stock must name one", domain.ErrInvalid, c.ProductID)
+		}
+		if c.Delta == 0 {
+			continue
+		}
+
+		if c.LocationID != 0 {
+			k := stockKey{c.LocationID, c.ProductID, c.VariantID}
+			if _, seen := pending[k]; !seen {
+				pending[k] = r.stock[k].Quantity
+			}
+			pending[k] += c.Delta
+			if pending[k] < 0 {
+				return nil, fmt.Errorf("%w: insufficient stock for product %d at location %d", domain.ErrConflict, c.ProductID, c.LocationID)
+			}
+		}
+
+		if total && c.Delta < 0 {
+			k := stockKey{productID: c.ProductID, variantID: c.VariantID}
+			if _, seen := totals[k]; !seen {
+				totals[k] = p.Quantity
+				if v, ok := p.Variant(c.VariantID); ok {
+					totals[k] = v.Quantity
+				}
+			}
+			totals[k] += c.Delta
+			if totals[k] < 0 {
+				return nil, fmt.Errorf("%w: insufficient stock for product %d", domain.ErrConflict, c.ProductID)
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	for k, qty := range pending {
+		r.stock[k] = domain.StockLevel{
+			LocationID: k.locationID,
+			ProductID:  k.productID,
+			VariantID:  k.variantID,
+			Quantity:   qty,
+			UpdatedAt:  now,
+		}
+	}
+	if !total {
+		return nil, nil
+	}
+
+	costs := make([]domain.CostBasis, len(changes))
+	for i, c := range changes {
+		if c.Delta == 0 {
+			continue
+		}
+		p := cloneProduct(r.products[c.ProductID])
+		onHand := p.Quantity
+		for j := range p.Variants {
+			if p.Variants[j].ID == c.VariantID {
+				onHand = p.Variants[j].Quantity
+				p.Variants[j].Quantity += c.Delta
+			}
+		}
+		p.Quantity += c.Delta
+		p.ChangeSeq = r.changes.next()
+		p.UpdatedAt = now
+		r.products[c.ProductID] = p
+		costs[i] = r.applyCost(c, onHand, now)
+
+		events, err := domain.StockEvents(c, onHand+c.Delta, p.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		r.outbox.record(ctx, events)
+	}
+	return costs, nil
+}
+
+// applyCost adds a cost layer for incoming stock or consumes layers for
+// outgoing stock. onHand is the quantity before the change.
+func (r *ProductRepo) applyCost(c domain.StockChange, onHand int, now time.Time) domain.CostBasis {
+	k := stockKey{productID: c.ProductID, variantID: c.VariantID}
+	cost, ok := r.costs[k]
+	if !ok {
+		cost = domain.ProductCost{ProductID: c.ProductID, VariantID: c.VariantID}
+	}
+
+	var basis domain.CostBasis
+	switch {
+	case c.Delta > 0:
+		unitCost := cost.Receive(onHand, c.Delta, c.UnitCost)
+		r.layers[k] = append(r.layers[k], domain.CostLayer{
+			ID:         r.nextLayerID,
+			ProductID:  c.ProductID,
+			VariantID:  c.VariantID,
+			Quantity:   c.Delta,
+			Remaining:  c.Delta,
+			UnitCost:   unitCost,
+			ReceivedAt: now,
+		})
+		r.nextLayerID++
+		basis = domain.CostBasis{FIFO: c.Delta * unitCost, Average: c.Delta * unitCost, Last: c.Delta * unitCost}
+	case c.Delta < 0:
+		basis = cost.Issue(r.layers[k], -c.Delta)
+	}
+	r.costs[k] = cost
+	return basis
+}
+
+// CostLayers returns the layers of a product that still have stock, oldest
+// first.
+func (r *ProductRepo) CostLayers(ctx context.Context, productID int) ([]domain.CostLayer, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.products[productID]; !ok {
+		return nil, domain.ErrNotFound
+	}
+	out := make([]domain.CostLayer, 0)
+	for k, layers := range r.layers {
+		if k.productID != productID {
+			continue
+		}
+		for _, l := range layers {
+			if l.Remaining > 0 {
+				out = append(out, l)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func cloneProduct(p domain.Product) domain.Product {
+	p.Barcodes = append([]string{}, p.Barcodes...)
+	p.Options = cloneOptions(p.Options)
+	variants := make([]domain.ProductVariant, 0, len(p.Variants))
+	for _, v := range p.Variants {
+		variants = append(variants, cloneVariant(v))
+	}
+	p.Variants = variants
+	return p
+}
+
+func cloneOptions(options []domain.ProductOption) []domain.ProductOption {
+	out := make([]domain.ProductOption, 0, len(options))
+	for _, o := range options {
+		o.Values = append([]string{}, o.Values...)
+		out = append(out, o)
+	}
+	return out
+}
+
+func cloneVariant(v domain.ProductVariant) domain.ProductVariant {
+	options := make(map[string]string, len(v.Options))
+	for k, val := range v.Options {
+		options[k] = val
+	}
+	v.Options = options
+	if v.Price != nil {
+		price := *v.Price
+		v.Price = &price
+	}
+	return v
+}
@@ -0,0 +1,236 @@
+package repository_memory
+
+import (
+	"context"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type PurchaseOrderRepo struct {
+	mu         sync.RWMutex
+	nextID     int
+	nextLineID int
+	orders     map[int]domain.PurchaseOrder
+	products   *ProductRepo
+
+	tenants *partitions[PurchaseOrderRepo]
+}
+
+func newPurchaseOrderRepo(products *ProductRepo) *PurchaseOrderRepo {
+	return &PurchaseOrderRepo{
+		nextID:     1,
+		nextLineID: 1,
+		orders:     make(map[int]domain.PurchaseOrder),
+		products:   products,
+	}
+}
+
+func NewPurchaseOrderRepo(products *ProductRepo) *PurchaseOrderRepo {
+	r := newPurchaseOrderRepo(products)
+	r.tenants = partitioned(func(ctx context.Context) *PurchaseOrderRepo {
+		return newPurchaseOrderRepo(products.of(ctx))
+	})
+	return r
+}
+
+func (r *PurchaseOrderRepo) of(ctx context.Context) *PurchaseOrderRepo {
+	return r.tenants.of(ctx, r)
+}
+
+func (r *PurchaseOrderRepo) Create(ctx context.Context, po domain.PurchaseOrder) (domain.PurchaseOrder, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	po.ID = r.nextID
+	r.nextID++
+
+	po.Status = domain.PurchaseOrderDraft
+	po.Notes = strings.TrimSpace(po.Notes)
+	po.Lines = r.assignLineIDs(po.Lines)
+	po.CreatedAt = now
+	po.UpdatedAt = now
+
+	r.orders[po.ID] = po
+	return clonePurchaseOrder(po), nil
+}
+
+func (r *PurchaseOrderRepo) GetByID(ctx context.Context, id int) (domain.PurchaseOrder, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	po, ok := r.orders[id]
+	if !ok {
+		return domain.PurchaseOrder{}, domain.ErrNotFound
+	}
+	return clonePurchaseOrder(po), nil
+}
+
+func (r *PurchaseOrderRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.PurchaseOrder, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int, 0, len(r.orders))
+	for id := range r.orders {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(ids) {
+		return []domain.PurchaseOrder{}, nil
+	}
+
+	end := offset + limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	out := make([]domain.PurchaseOrder, 0, end-offset)
+	for _, id := range ids[offset:end] {
+		out = append(out, clonePurchaseOrder(r.orders[id]))
+	}
+	return out, nil
+}
+
+func (r *PurchaseOrderRepo) Update(ctx context.Context, id int, patch domain.PurchaseOrder) (domain.PurchaseOrder, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.orders[id]
+	if !ok {
+		return domain.PurchaseOrder{}, domain.ErrNotFound
+	}
+	if existing.Status != domain.PurchaseOrderDraft {
+		return domain.PurchaseOrder{}, fmt.Errorf("%w: only draft purchase orders can be edited", domain.ErrConflict)
+	}
+
+	existing.SupplierID = patch.SupplierID
+	existing.LocationID = patch.LocationID
+	existing.Notes = strings.TrimSpace(patch.Notes)
+	existing.Lines = r.assignLineIDs(patch.Lines)
+	existing.UpdatedAt = time.Now().UTC()
+
+	r.orders[id] = existing
+	return clonePurchaseOrder(existing), nil
+}
+
+func (r *PurchaseOrderRepo) Transition(ctx context.Context, id int, next domain.PurchaseOrderStatus) (domain.PurchaseOrder, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.orders[id]
+	if !ok {
+		return domain.PurchaseOrder{}, domain.ErrNotFound
+	}
+
+	now := time.Now().UTC()
+	if err := existing.Transition(next, now); err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	existing.UpdatedAt = now
+
+	r.orders[id] = existing
+	return clonePurchaseOrder(existing), nil
+}
+
+func (r *PurchaseOrderRepo) Receive(ctx context.Context, id int, lines []domain.ReceiveLine) (domain.PurchaseOrder, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.orders[id]
+	if !ok {
+		return domain.PurchaseOrder{}, domain.ErrNotFound
+	}
+
+	po := clonePurchaseOrder(existing)
+	now := time.Now().UTC()
+	received, err := po.Receive(lines, now)
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	po.UpdatedAt = now
+
+	if _, err := r.products.applyStock(ctx, received, true); err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+
+	r.orders[id] = po
+	return clonePurchaseOrder(po), nil
+}
+
+func (r *PurchaseOrderRepo) Purchasing(ctx context.Context, productIDs []int) (map[int]domain.ProductPurchasing, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[int]bool, len(productIDs))
+	for _, id := range productIDs {
+		wanted[id] = true
+	}
+
+	ids := make([]int, 0, len(r.orders))
+	for id := range r.orders {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	out := make(map[int]domain.ProductPurchasing)
+	for _, id := range ids {
+		po := r.orders[id]
+		if po.Status == domain.PurchaseOrderCancelled {
+			continue
+		}
+		open := po.Status == domain.PurchaseOrderSubmitted || po.Status == domain.PurchaseOrderPartiallyReceived
+		for _, l := range po.Lines {
+			if !wanted[l.ProductID] {
+				continue
+			}
+			info := out[l.ProductID]
+			info.ProductID = l.ProductID
+			info.SupplierID = po.SupplierID
+			info.UnitCost = l.UnitCost
+			if open {
+				info.OnOrder += l.Outstanding()
+			}
+			out[l.ProductID] = info
+		}
+	}
+	return out, nil
+}
+
+func (r *PurchaseOrderRepo) assignLineIDs(lines []domain.PurchaseOrderLine) []domain.PurchaseOrderLine {
+	out := make([]domain.PurchaseOrderLine, 0, len(lines))
+	for _, l := range lines {
+		l.ID = r.nextLineID
+		r.nextLineID++
+		l.ReceivedQuantity = 0
+		out = append(out, l)
+	}
+	return out
+}
+
+func clonePurchaseOrder(po domain.PurchaseOrder) domain.PurchaseOrder {
+	po.Lines = append([]domain.PurchaseOrderLine(nil), po.Lines...)
+	return po
+}
@@ -0,0 +1,254 @@
+package repository_memory
+
+import (
+	"context"
+	"pos-api/internal/domain"
+	"sort"
+)
+
+// ReportRepo aggregates the orders held by an OrderRepo the same way the
+// Postgres reports do with SQL.
+type ReportRepo struct {
+	orders     *OrderRepo
+	products   *ProductRepo
+	categories *CategoryRepo
+
+	tenants *partitions[ReportRepo]
+}
+
+func newReportRepo(orders *OrderRepo, products *ProductRepo, categories *CategoryRepo) *ReportRepo {
+	return &ReportRepo{orders: orders, products: products, categories: categories}
+}
+
+func NewReportRepo(orders *OrderRepo, products *ProductRepo, categories *CategoryRepo) *ReportRepo {
+	r := newReportRepo(orders, products, categories)
+	r.tenants = partitioned(func(ctx context.Context) *ReportRepo {
+		return newReportRepo(orders.of(ctx), products.of(ctx), categories.of(ctx))
+	})
+	return r
+}
+
+func (r *ReportRepo) of(ctx context.Context) *ReportRepo {
+	return r.tenants.of(ctx, r)
+}
+
+func (r *ReportRepo) SalesByPeriod(ctx context.Context, p domain.SalesReportParams) ([]domain.SalesPeriod, error) {
+	r = r.of(ctx)
+	byStart := make(map[int64]*domain.SalesPeriod)
+	for _, o := range r.ordersIn(p) {
+		start := p.GroupBy.PeriodStart(o.CreatedAt, p.Location)
+		s, ok := byStart[start.Unix()]
+		if !ok {
+			s = &domain.SalesPeriod{Start: start}
+			byStart[start.Unix()] = s
+		}
+		s.OrderCount++
+		s.Subtotal += o.Subtotal
+		s.Tax += o.Tax
+		s.Total += o.Total
+		for _, it := range o.Items {
+			s.ItemsSold += it.Quantity
+		}
+	}
+
+	items := make([]domain.SalesPeriod, 0, len(byStart))
+	for _, s := range byStart {
+		items = append(items, *s)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Start.Before(items[j].Start) })
+	return items, nil
+}
+
+func (r *ReportRepo) TopProducts(ctx context.Context, p domain.SalesReportParams, byQuantity bool) ([]domain.ProductSales, error) {
+	r = r.of(ctx)
+	byProduct := make(map[int]*domain.ProductSales)
+	for _, o := range r.ordersIn(p) {
+		for _, it := range o.Items {
+			s, ok := byProduct[it.ProductID]
+			if !ok {
+				s = &domain.ProductSales{ProductID: it.ProductID, Name: it.Name}
+				if prod, err := r.products.GetByID(ctx, it.ProductID); err == nil {
+					s.Name = prod.Name
+				}
+				byProduct[it.ProductID] = s
+			}
+			s.Quantity += it.Quantity
+			s.Revenue += it.LineTotal
+		}
+	}
+
+	items := make([]domain.ProductSales, 0, len(byProduct))
+	for _, s := range byProduct {
+		items = append(items, *s)
+	}
+	rank := func(s domain.ProductSales) (int, int) {
+		if byQuantity {
+			return s.Quantity, s.Revenue
+		}
+		return s.Revenue, s.Quantity
+	}
+	sort.Slice(items, func(i, j int) bool {
+		a1, a2 := rank(items[i])
+		b1, b2 := rank(items[j])
+		if a1 != b1 {
+			return a1 > b1
+		}
+		if a2 != b2 {
+			return a2 > b2
+		}
+		return items[i].ProductID < items[j].ProductID
+	})
+	if p.Top > 0 && len(items) > p.Top {
+		items = items[:p.Top]
+	}
+	return items, nil
+}
+
+func (r *ReportRepo) SalesByCategory(ctx context.Context, p domain.SalesReportParams) ([]domain.CategorySales, error) {
+	r = r.of(ctx)
+	byCategory := make(map[int]*domain.CategorySales)
+	for _, o := range r.ordersIn(p) {
+		for _, it := range o.Items {
+			categoryID, name := r.category(ctx, it.ProductID)
+
+			s, ok := byCategory[categoryID]
+			if !ok {
+				s = &domain.CategorySales{CategoryID: categoryID, Name: name}
+				byCategory[categoryID] = s
+			}
+			s.Quantity += it.Quantity
+			s.Revenue += it.LineTotal
+		}
+	}
+
+	items := make([]domain.CategorySales, 0, len(byCategory))
+	for _, s := range byCategory {
+		items = append(items, *s)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Revenue != items[j].Revenue {
+			return items[i].Revenue > items[j].Revenue
+		}
+		return items[i].CategoryID < items[j].CategoryID
+	})
+	return items, nil
+}
+
+// category returns the current category of a product. A deleted category
+// counts as uncategorised, as it does with ON DELETE SET NULL in Postgres.
+func (r *ReportRepo) category(ctx context.Context, productID int) (int, string) {
+	p, err := r.products.GetByID(ctx, productID)
+	if err != nil || p.CategoryID == 0 {
+		return 0, ""
+	}
+	c, err := r.categories.GetByID(ctx, p.CategoryID)
+	if err != nil {
+		return 0, ""
+	}
+	return c.ID, c.Name
+}
+
+// ordersIn copies the orders created in [p.From, p.To).
+func (r *ReportRepo) ordersIn(p domain.SalesReportParams) []domain.Order {
+	r.orders.mu.RLock()
+	defer r.orders.mu.RUnlock()
+
+	out := make([]domain.Order, 0)
+	for _, o := range r.orders.orders {
+		if !o.CreatedAt.Before(p.From) && o.CreatedAt.Before(p.To) {
+			out = append(out, cloneOrder(o))
+		}
+	}
+	return out
+}
+
+func (r *ReportRepo) InventoryValuation(ctx context.Context) ([]domain.ProductValuation, error) {
+	r = r.of(ctx)
+	r.products.mu.RLock()
+	defer r.products.mu.RUnlock()
+
+	items := make([]domain.ProductValuation, 0, len(r.products.products))
+	for _, p := range r.products.products {
+		if len(p.Variants) == 0 {
+			items = append(items, r.value(p, 0, p.SKU, p.Quantity))
+			continue
+		}
+		for _, v := range p.Variants {
+			items = append(items, r.value(p, v.ID, v.SKU, v.Quantity))
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].ProductID != items[j].ProductID {
+			return items[i].ProductID < items[j].ProductID
+		}
+		return items[i].VariantID < items[j].VariantID
+	})
+	return items, nil
+}
+
+// value must be called with the product lock held.
+func (r *ReportRepo) value(p domain.Product, variantID int, sku string, quantity int) domain.ProductValuation {
+	k := stockKey{productID: p.ID, variantID: variantID}
+	return domain.ProductValuation{
+		ProductID:  p.ID,
+		VariantID:  variantID,
+		Name:       p.Name,
+		SKU:        sku,
+		CategoryID: p.CategoryID,
+		Quantity:   quantity,
+		Value:      r.products.costs[k].Value(r.products.layers[k], quantity),
+	}
+}
+
+func (r *ReportRepo) CostOfSalesByPeriod(ctx context.Context, p domain.SalesReportParams) ([]domain.SalesCost, error) {
+	r = r.of(ctx)
+	byStart := make(map[int64]*domain.SalesCost)
+	for _, o := range r.ordersIn(p) {
+		start := p.GroupBy.PeriodStart(o.CreatedAt, p.Location)
+		s, ok := byStart[start.Unix()]
+		if !ok {
+			s = &domain.SalesCost{Start: start}
+			byStart[start.Unix()] = s
+		}
+		for _, it := range o.Items {
+			s.Revenue += it.LineTotal
+			s.Cost = s.Cost.Add(it.Cost)
+		}
+	}
+
+	items := make([]domain.SalesCost, 0, len(byStart))
+	for _, s := range byStart {
+		items = append(items, *s)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Start.Before(items[j].Start) })
+	return items, nil
+}
+
+func (r *ReportRepo) CostOfSalesByCategory(ctx context.Context, p domain.SalesReportParams) ([]domain.SalesCost, error) {
+	r = r.of(ctx)
+	byCategory := make(map[int]*domain.SalesCost)
+	for _, o := range r.ordersIn(p) {
+		for _, it := range o.Items {
+			categoryID, name := r.category(ctx, it.ProductID)
+			s, ok := byCategory[categoryID]
+			if !ok {
+				s = &domain.SalesCost{CategoryID: categoryID, Name: name}
+				byCategory[categoryID] = s
+			}
+			s.Revenue += it.LineTotal
+			s.Cost = s.Cost.Add(it.Cost)
+		}
+	}
+
+	items := make([]domain.SalesCost, 0, len(byCategory))
+	for _, s := range byCategory {
+		items = append(items, *s)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Revenue != items[j].Revenue {
+			return items[i].Revenue > items[j].Revenue
+		}
+		return items[i].CategoryID < items[j].CategoryID
+	})
+	return items, nil
+}
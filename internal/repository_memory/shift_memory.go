@@ -0,0 +1,185 @@
+package repository_memory
+
+import (
+	"context"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ShiftRepo struct {
+	mu             sync.RWMutex
+	nextID         int
+	nextMovementID int
+	shifts         map[int]domain.Shift
+	movements      map[int][]domain.CashMovement
+	// sales totals a shift's orders. NewOrderRepo wires it up; until then
+	// a shift closes with no sales.
+	sales func(shiftID int) domain.ShiftSales
+
+	tenants *partitions[ShiftRepo]
+}
+
+func newShiftRepo() *ShiftRepo {
+	return &ShiftRepo{
+		nextID:         1,
+		nextMovementID: 1,
+		shifts:         make(map[int]domain.Shift),
+		movements:      make(map[int][]domain.CashMovement),
+		sales: func(int) domain.ShiftSales {
+			return domain.ShiftSales{Tenders: map[domain.TenderMethod]int{}}
+		},
+	}
+}
+
+func NewShiftRepo() *ShiftRepo {
+	r := newShiftRepo()
+	r.tenants = partitioned(func(context.Context) *ShiftRepo {
+		return newShiftRepo()
+	})
+	return r
+}
+
+func (r *ShiftRepo) of(ctx context.Context) *ShiftRepo {
+	return r.tenants.of(ctx, r)
+}
+
+func (r *ShiftRepo) Open(ctx context.Context, s domain.Shift) (domain.Shift, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s.RegisterID = strings.TrimSpace(s.RegisterID)
+	s.UserID = strings.TrimSpace(s.UserID)
+	for _, existing := range r.shifts {
+		if existing.RegisterID == s.RegisterID && existing.Status == domain.ShiftOpen {
+			return domain.Shift{}, fmt.Errorf("%w: register %q already has open shift %d", domain.ErrConflict, s.RegisterID, existing.ID)
+		}
+	}
+
+	s.ID = r.nextID
+	r.nextID++
+
+	s.Status = domain.ShiftOpen
+	s.ExpectedCash = nil
+	s.CountedCash = nil
+	s.Variance = nil
+	s.OpenedAt = time.Now().UTC()
+	s.ClosedAt = nil
+
+	r.shifts[s.ID] = s
+	return s, nil
+}
+
+func (r *ShiftRepo) GetByID(ctx context.Context, id int) (domain.Shift, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.shifts[id]
+	if !ok {
+		return domain.Shift{}, domain.ErrNotFound
+	}
+	return s, nil
+}
+
+func (r *ShiftRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Shift, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int, 0, len(r.shifts))
+	for id := range r.shifts {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(ids) {
+		return []domain.Shift{}, nil
+	}
+
+	end := offset + limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	out := make([]domain.Shift, 0, end-offset)
+	for _, id := range ids[offset:end] {
+		out = append(out, r.shifts[id])
+	}
+	return out, nil
+}
+
+func (r *ShiftRepo) AddCashMovement(ctx context.Context, m domain.CashMovement) (domain.CashMovement, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.shifts[m.ShiftID]
+	if !ok {
+		return domain.CashMovement{}, domain.ErrNotFound
+	}
+	if s.Status != domain.ShiftOpen {
+		return domain.CashMovement{}, fmt.Errorf("%w: shift %d is closed", domain.ErrConflict, s.ID)
+	}
+
+	m.ID = r.nextMovementID
+	r.nextMovementID++
+
+	m.Reason = strings.TrimSpace(m.Reason)
+	m.CreatedAt = time.Now().UTC()
+
+	r.movements[m.ShiftID] = append(r.movements[m.ShiftID], m)
+	return m, nil
+}
+
+func (r *ShiftRepo) CashMovements(ctx context.Context, shiftID int) ([]domain.CashMovement, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.shifts[shiftID]; !ok {
+		return nil, domain.ErrNotFound
+	}
+	return append([]domain.CashMovement{}, r.movements[shiftID]...), nil
+}
+
+func (r *ShiftRepo) Close(ctx context.Context, id int, countedCash int) (domain.Shift, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.shifts[id]
+	if !ok {
+		return domain.Shift{}, domain.ErrNotFound
+	}
+	if s.Status != domain.ShiftOpen {
+		return domain.Shift{}, fmt.Errorf("%w: shift %d is already closed", domain.ErrConflict, id)
+	}
+
+	expected, _, _ := domain.ExpectedCash(s.OpeningFloat, r.sales(id), r.movements[id])
+	variance := countedCash - expected
+	now := time.Now().UTC()
+
+	s.Status = domain.ShiftClosed
+	s.ExpectedCash = &expected
+	s.CountedCash = &countedCash
+	s.Variance = &variance
+	s.ClosedAt = &now
+
+	r.shifts[id] = s
+	return s, nil
+}
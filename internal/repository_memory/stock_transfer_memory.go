@@ -0,0 +1,143 @@
+package repository_memory
+
+import (
+	"context"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type StockTransferRepo struct {
+	mu        sync.RWMutex
+	nextID    int
+	transfers map[int]domain.StockTransfer
+	products  *ProductRepo
+
+	tenants *partitions[StockTransferRepo]
+}
+
+func newStockTransferRepo(products *ProductRepo) *StockTransferRepo {
+	return &StockTransferRepo{
+		nextID:    1,
+		transfers: make(map[int]domain.StockTransfer),
+		products:  products,
+	}
+}
+
+func NewStockTransferRepo(products *ProductRepo) *StockTransferRepo {
+	r := newStockTransferRepo(products)
+	r.tenants = partitioned(func(ctx context.Context) *StockTransferRepo {
+		return newStockTransferRepo(products.of(ctx))
+	})
+	return r
+}
+
+func (r *StockTransferRepo) of(ctx context.Context) *StockTransferRepo {
+	return r.tenants.of(ctx, r)
+}
+
+func (r *StockTransferRepo) Create(ctx context.Context, t domain.StockTransfer) (domain.StockTransfer, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.products.applyStock(ctx, t.Ship(), false); err != nil {
+		return domain.StockTransfer{}, err
+	}
+
+	now := time.Now().UTC()
+
+	t.ID = r.nextID
+	r.nextID++
+
+	t.Status = domain.StockTransferInTransit
+	t.Notes = strings.TrimSpace(t.Notes)
+	t.Lines = append([]domain.StockTransferLine{}, t.Lines...)
+	t.ReceivedAt = nil
+	t.CreatedAt = now
+	t.UpdatedAt = now
+
+	r.transfers[t.ID] = t
+	return cloneStockTransfer(t), nil
+}
+
+func (r *StockTransferRepo) GetByID(ctx context.Context, id int) (domain.StockTransfer, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.transfers[id]
+	if !ok {
+		return domain.StockTransfer{}, domain.ErrNotFound
+	}
+	return cloneStockTransfer(t), nil
+}
+
+func (r *StockTransferRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.StockTransfer, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int, 0, len(r.transfers))
+	for id := range r.transfers {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(ids) {
+		return []domain.StockTransfer{}, nil
+	}
+
+	end := offset + limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	out := make([]domain.StockTransfer, 0, end-offset)
+	for _, id := range ids[offset:end] {
+		out = append(out, cloneStockTransfer(r.transfers[id]))
+	}
+	return out, nil
+}
+
+func (r *StockTransferRepo) Complete(ctx context.Context, id int, next domain.StockTransferStatus) (domain.StockTransfer, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.transfers[id]
+	if !ok {
+		return domain.StockTransfer{}, domain.ErrNotFound
+	}
+
+	t := cloneStockTransfer(existing)
+	now := time.Now().UTC()
+	changes, err := t.Complete(next, now)
+	if err != nil {
+		return domain.StockTransfer{}, err
+	}
+	if _, err := r.products.applyStock(ctx, changes, false); err != nil {
+		return domain.StockTransfer{}, err
+	}
+	t.UpdatedAt = now
+
+	r.transfers[id] = t
+	return cloneStockTransfer(t), nil
+}
+
+func cloneStockTransfer(t domain.StockTransfer) domain.StockTransfer {
+	t.Lines = append([]domain.StockTransferLine{}, t.Lines...)
+	return t
+}
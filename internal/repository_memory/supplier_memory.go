@@ -0,0 +1,137 @@
+package repository_memory
+
+import (
+	"context"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SupplierRepo struct {
+	mu        sync.RWMutex
+	nextID    int
+	suppliers map[int]domain.Supplier
+
+	tenants *partitions[SupplierRepo]
+}
+
+func newSupplierRepo() *SupplierRepo {
+	return &SupplierRepo{
+		nextID:    1,
+		suppliers: make(map[int]domain.Supplier),
+	}
+}
+
+func NewSupplierRepo() *SupplierRepo {
+	r := newSupplierRepo()
+	r.tenants = partitioned(func(context.Context) *SupplierRepo {
+		return newSupplierRepo()
+	})
+	return r
+}
+
+func (r *SupplierRepo) of(ctx context.Context) *SupplierRepo {
+	return r.tenants.of(ctx, r)
+}
+
+func (r *SupplierRepo) Create(ctx context.Context, s domain.Supplier) (domain.Supplier, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	s.ID = r.nextID
+	r.nextID++
+
+	s.Name = strings.TrimSpace(s.Name)
+	s.CreatedAt = now
+	s.UpdatedAt = now
+
+	r.suppliers[s.ID] = s
+	return s, nil
+}
+
+func (r *SupplierRepo) GetByID(ctx context.Context, id int) (domain.Supplier, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.suppliers[id]
+	if !ok {
+		return domain.Supplier{}, domain.ErrNotFound
+	}
+	return s, nil
+}
+
+func (r *SupplierRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Supplier, error) {
+	r = r.of(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int, 0, len(r.suppliers))
+	for id := range r.suppliers {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(ids) {
+		return []domain.Supplier{}, nil
+	}
+
+	end := offset + limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	out := make([]domain.Supplier, 0, end-offset)
+	for _, id := range ids[offset:end] {
+		out = append(out, r.suppliers[id])
+	}
+	return out, nil
+}
+
+func (r *SupplierRepo) Update(ctx context.Context, id int, patch domain.Supplier) (domain.Supplier, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.suppliers[id]
+	if !ok {
+		return domain.Supplier{}, domain.ErrNotFound
+	}
+
+	existing.Name = strings.TrimSpace(patch.Name)
+	existing.ContactName = strings.TrimSpace(patch.ContactName)
+	existing.Phone = strings.TrimSpace(patch.Phone)
+	existing.Email = strings.TrimSpace(patch.Email)
+	existing.Address = strings.TrimSpace(patch.Address)
+	existing.UpdatedAt = time.Now().UTC()
+
+	r.suppliers[id] = existing
+	return existing, nil
+}
+
+func (r *SupplierRepo) Delete(ctx context.Context, id int) error {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.suppliers[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.suppliers, id)
+	return nil
+}
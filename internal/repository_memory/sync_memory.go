@@ -0,0 +1,167 @@
+package repository_memory
+
+import (
+	"cmp"
+	"context"
+	"pos-api/internal/domain"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ChangeLog numbers the changes made by the product and category
+// repositories from one sequence and keeps a tombstone for each deletion.
+// They take its lock while holding their own, never the other way round.
+type ChangeLog struct {
+	mu         sync.Mutex
+	seq        int
+	tombstones []domain.CatalogTombstone
+
+	tenants *partitions[ChangeLog]
+}
+
+func newChangeLog() *ChangeLog {
+	return &ChangeLog{}
+}
+
+func NewChangeLog() *ChangeLog {
+	l := newChangeLog()
+	l.tenants = partitioned(func(context.Context) *ChangeLog {
+		return newChangeLog()
+	})
+	return l
+}
+
+func (l *ChangeLog) of(ctx context.Context) *ChangeLog {
+	return l.tenants.of(ctx, l)
+}
+
+// next returns the next change number.
+func (l *ChangeLog) next() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seq++
+	return l.seq
+}
+
+// deleted records the deletion of an entity.
+func (l *ChangeLog) deleted(entityType string, id int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seq++
+	l.tombstones = append(l.tombstones, domain.CatalogTombstone{
+		ChangeSeq:  l.seq,
+		EntityType: entityType,
+		EntityID:   id,
+		DeletedAt:  time.Now().UTC(),
+	})
+}
+
+// mark returns the number of tombstones, for truncate to return to.
+func (l *ChangeLog) mark() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.tombstones)
+}
+
+// truncate drops the tombstones recorded since mark returned n. The change
+// numbers they took are not reused.
+func (l *ChangeLog) truncate(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tombstones = l.tombstones[:n]
+}
+
+type SyncRepo struct {
+	changes    *ChangeLog
+	products   *ProductRepo
+	categories *CategoryRepo
+
+	tenants *partitions[SyncRepo]
+}
+
+func newSyncRepo(changes *ChangeLog, products *ProductRepo, categories *CategoryRepo) *SyncRepo {
+	return &SyncRepo{changes: changes, products: products, categories: categories}
+}
+
+// NewSyncRepo reads changes from the product and category repositories that
+// share changes.
+func NewSyncRepo(changes *ChangeLog, products *ProductRepo, categories *CategoryRepo) *SyncRepo {
+	r := newSyncRepo(changes, products, categories)
+	r.tenants = partitioned(func(ctx context.Context) *SyncRepo {
+		return newSyncRepo(changes.of(ctx), products.of(ctx), categories.of(ctx))
+	})
+	return r
+}
+
+func (r *SyncRepo) of(ctx context.Context) *SyncRepo {
+	return r.tenants.of(ctx, r)
+}
+
+func (r *SyncRepo) Changes(ctx context.Context, since, limit int) (domain.CatalogChanges, error) {
+	r = r.of(ctx)
+
+	// Holding both read locks waits out any change in progress, so every
+	// number up to the sequence's current value is visible.
+	r.products.mu.RLock()
+	defer r.products.mu.RUnlock()
+	r.categories.mu.RLock()
+	defer r.categories.mu.RUnlock()
+	r.changes.mu.Lock()
+	defer r.changes.mu.Unlock()
+
+	type change struct {
+		seq       int
+		product   *domain.Product
+		category  *domain.Category
+		tombstone *domain.CatalogTombstone
+	}
+	var changed []change
+	for _, p := range r.products.products {
+		if p.ChangeSeq > since {
+			p := cloneProduct(p)
+			changed = append(changed, change{seq: p.ChangeSeq, product: &p})
+		}
+	}
+	for _, c := range r.categories.categories {
+		if c.ChangeSeq > since {
+			changed = append(changed, change{seq: c.ChangeSeq, category: &c})
+		}
+	}
+	// Tombstones are in change order.
+	i, _ := slices.BinarySearchFunc(r.changes.tombstones, since+1, func(t domain.CatalogTombstone, seq int) int {
+		return cmp.Compare(t.ChangeSeq, seq)
+	})
+	for _, t := range r.changes.tombstones[i:] {
+		changed = append(changed, change{seq: t.ChangeSeq, tombstone: &t})
+	}
+	slices.SortFunc(changed, func(a, b change) int { return cmp.Compare(a.seq, b.seq) })
+
+	out := domain.CatalogChanges{
+		Since:      since,
+		Watermark:  max(r.changes.seq, since),
+		Products:   []domain.Product{},
+		Categories: []domain.Category{},
+		Deleted:    []domain.CatalogTombstone{},
+	}
+	if len(changed) > limit {
+		changed = changed[:limit]
+		out.HasMore = true
+		out.Watermark = changed[limit-1].seq
+	}
+	for _, c := range changed {
+		switch {
+		case c.product != nil:
+			out.Products = append(out.Products, *c.product)
+		case c.category != nil:
+			out.Categories = append(out.Categories, *c.category)
+		default:
+			out.Deleted = append(out.Deleted, *c.tombstone)
+		}
+	}
+	return out, nil
+}
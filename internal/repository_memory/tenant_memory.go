@@ -0,0 +1,184 @@
+package repository_memory
+
+import (
+	"context"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"pos-api/internal/tenant"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TenantRepo keeps the tenants. It starts with the default tenant, as the
+// database does once migrated.
+type TenantRepo struct {
+	mu      sync.RWMutex
+	nextID  int
+	tenants map[int]domain.Tenant
+}
+
+func NewTenantRepo() *TenantRepo {
+	now := time.Now().UTC()
+	return &TenantRepo{
+		nextID: tenant.Default + 1,
+		tenants: map[int]domain.Tenant{
+			tenant.Default: {ID: tenant.Default, Slug: "default", Name: "Default", CreatedAt: now, UpdatedAt: now},
+		},
+	}
+}
+
+func (r *TenantRepo) Create(ctx context.Context, t domain.Tenant) (domain.Tenant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t = trimTenant(t)
+	if err := r.checkSlug(0, t.Slug); err != nil {
+		return domain.Tenant{}, err
+	}
+
+	now := time.Now().UTC()
+	t.ID = r.nextID
+	r.nextID++
+	t.Settings.TaxRate = cloneTaxRate(t.Settings.TaxRate)
+	t.CreatedAt = now
+	t.UpdatedAt = now
+
+	r.tenants[t.ID] = t
+	return cloneTenant(t), nil
+}
+
+func (r *TenantRepo) GetByID(ctx context.Context, id int) (domain.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tenants[id]
+	if !ok {
+		return domain.Tenant{}, domain.ErrNotFound
+	}
+	return cloneTenant(t), nil
+}
+
+func (r *TenantRepo) GetBySlug(ctx context.Context, slug string) (domain.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	slug = strings.TrimSpace(slug)
+	for _, t := range r.tenants {
+		if t.Slug == slug {
+			return cloneTenant(t), nil
+		}
+	}
+	return domain.Tenant{}, domain.ErrNotFound
+}
+
+func (r *TenantRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.sortedIDs()
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(ids) {
+		return []domain.Tenant{}, nil
+	}
+
+	end := offset + limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	out := make([]domain.Tenant, 0, end-offset)
+	for _, id := range ids[offset:end] {
+		out = append(out, cloneTenant(r.tenants[id]))
+	}
+	return out, nil
+}
+
+func (r *TenantRepo) ListActiveIDs(ctx context.Context) ([]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]int, 0, len(r.tenants))
+	for _, id := range r.sortedIDs() {
+		if !r.tenants[id].Suspended {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+func (r *TenantRepo) Update(ctx context.Context, id int, patch domain.Tenant) (domain.Tenant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.tenants[id]
+	if !ok {
+		return domain.Tenant{}, domain.ErrNotFound
+	}
+	patch = trimTenant(patch)
+	if err := r.checkSlug(id, patch.Slug); err != nil {
+		return domain.Tenant{}, err
+	}
+
+	existing.Slug = patch.Slug
+	existing.Name = patch.Name
+	existing.Suspended = patch.Suspended
+	existing.Settings = patch.Settings
+	existing.Settings.TaxRate = cloneTaxRate(patch.Settings.TaxRate)
+	existing.UpdatedAt = time.Now().UTC()
+
+	r.tenants[id] = existing
+	return cloneTenant(existing), nil
+}
+
+func (r *TenantRepo) sortedIDs() []int {
+	ids := make([]int, 0, len(r.tenants))
+	for id := range r.tenants {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (r *TenantRepo) checkSlug(id int, slug string) error {
+	for _, t := range r.tenants {
+		if t.ID != id && t.Slug == slug {
+			return fmt.Errorf("%w: tenant slug %q is already taken", domain.ErrDuplicate, slug)
+		}
+	}
+	return nil
+}
+
+func trimTenant(t domain.Tenant) domain.Tenant {
+	t.Slug = strings.TrimSpace(t.Slug)
+	t.Name = strings.TrimSpace(t.Name)
+	t.Settings.Timezone = strings.TrimSpace(t.Settings.Timezone)
+	t.Settings.StoreName = strings.TrimSpace(t.Settings.StoreName)
+	t.Settings.StoreAddress = strings.TrimSpace(t.Settings.StoreAddress)
+	t.Settings.StorePhone = strings.TrimSpace(t.Settings.StorePhone)
+	t.Settings.ReceiptFooter = strings.TrimSpace(t.Settings.ReceiptFooter)
+	return t
+}
+
+func cloneTenant(t domain.Tenant) domain.Tenant {
+	t.Settings.TaxRate = cloneTaxRate(t.Settings.TaxRate)
+	return t
+}
+
+func cloneTaxRate(rate *float64) *float64 {
+	if rate == nil {
+		return nil
+	}
+	v := *rate
+	return &v
+}
@@ -0,0 +1,293 @@
+package repository_memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+type WebhookRepo struct {
+	mu             sync.Mutex
+	nextID         int
+	subscriptions  map[int]domain.WebhookSubscription
+	nextDeliveryID int
+	deliveries     []domain.WebhookDelivery
+
+	tenants *partitions[WebhookRepo]
+}
+
+func newWebhookRepo() *WebhookRepo {
+	return &WebhookRepo{
+		nextID:         1,
+		subscriptions:  make(map[int]domain.WebhookSubscription),
+		nextDeliveryID: 1,
+	}
+}
+
+func NewWebhookRepo() *WebhookRepo {
+	r := newWebhookRepo()
+	r.tenants = partitioned(func(context.Context) *WebhookRepo {
+		return newWebhookRepo()
+	})
+	return r
+}
+
+func (r *WebhookRepo) of(ctx context.Context) *WebhookRepo {
+	return r.tenants.of(ctx, r)
+}
+
+func cloneWebhook(s domain.WebhookSubscription) domain.WebhookSubscription {
+	s.EventTypes = append([]domain.EventType{}, s.EventTypes...)
+	return s
+}
+
+func (r *WebhookRepo) Create(ctx context.Context, s domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	s = cloneWebhook(s)
+	s.ID = r.nextID
+	r.nextID++
+	s.CreatedAt = now
+	s.UpdatedAt = now
+
+	r.subscriptions[s.ID] = s
+	return cloneWebhook(s), nil
+}
+
+func (r *WebhookRepo) GetByID(ctx context.Context, id int) (domain.WebhookSubscription, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.subscriptions[id]
+	if !ok {
+		return domain.WebhookSubscription{}, domain.ErrNotFound
+	}
+	return cloneWebhook(s), nil
+}
+
+func (r *WebhookRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.WebhookSubscription, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]int, 0, len(r.subscriptions))
+	for id := range r.subscriptions {
+		ids = append(ids, id)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(ids) {
+		return []domain.WebhookSubscription{}, nil
+	}
+	end := min(offset+limit, len(ids))
+
+	out := make([]domain.WebhookSubscription, 0, end-offset)
+	for _, id := range ids[offset:end] {
+		out = append(out, cloneWebhook(r.subscriptions[id]))
+	}
+	return out, nil
+}
+
+func (r *WebhookRepo) Update(ctx context.Context, id int, s domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.subscriptions[id]
+	if !ok {
+		return domain.WebhookSubscription{}, domain.ErrNotFound
+	}
+	existing.URL = s.URL
+	existing.EventTypes = append([]domain.EventType{}, s.EventTypes...)
+	if s.Secret != "" {
+		existing.Secret = s.Secret
+	}
+	existing.Disabled = s.Disabled
+	existing.UpdatedAt = time.Now().UTC()
+
+	r.subscriptions[id] = existing
+	return cloneWebhook(existing), nil
+}
+
+// Delete removes the subscription with its deliveries.
+func (r *WebhookRepo) Delete(ctx context.Context, id int) error {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.subscriptions, id)
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(d domain.WebhookDelivery) bool {
+		return d.SubscriptionID == id
+	})
+	return nil
+}
+
+func (r *WebhookRepo) Enqueue(ctx context.Context, e domain.Event) (int, error) {
+	r = r.of(ctx)
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]int, 0)
+	for id, s := range r.subscriptions {
+		if s.Wants(e.Type) && !r.queued(id, e.ID) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	now := time.Now().UTC()
+	for _, id := range ids {
+		r.add(domain.WebhookDelivery{
+			SubscriptionID: id,
+			EventID:        e.ID,
+			EventType:      e.Type,
+			Payload:        payload,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+	return len(ids), nil
+}
+
+// queued reports whether event has been queued for the subscription,
+// redeliveries aside.
+func (r *WebhookRepo) queued(subscriptionID, event int) bool {
+	return slices.ContainsFunc(r.deliveries, func(d domain.WebhookDelivery) bool {
+		return d.SubscriptionID == subscriptionID && d.EventID == event && d.RedeliveryOf == 0
+	})
+}
+
+// add appends d as a new pending delivery and returns it.
+func (r *WebhookRepo) add(d domain.WebhookDelivery) domain.WebhookDelivery {
+	d.ID = r.nextDeliveryID
+	r.nextDeliveryID++
+	d.Status = domain.WebhookPending
+	r.deliveries = append(r.deliveries, d)
+	return d
+}
+
+func (r *WebhookRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := make([]int, 0)
+	for i, d := range r.deliveries {
+		if d.Status == domain.WebhookPending && !d.NextAttemptAt.After(now) {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(a, b int) bool {
+		return r.deliveries[due[a]].NextAttemptAt.Before(r.deliveries[due[b]].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	out := make([]domain.WebhookDelivery, 0, len(due))
+	for _, i := range due {
+		r.deliveries[i].NextAttemptAt = now.Add(lease)
+		out = append(out, r.deliveries[i])
+	}
+	return out, nil
+}
+
+func (r *WebhookRepo) SaveAttempt(ctx context.Context, d domain.WebhookDelivery) error {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.deliveries {
+		if r.deliveries[i].ID != d.ID {
+			continue
+		}
+		existing := &r.deliveries[i]
+		existing.Status = d.Status
+		existing.Attempts = d.Attempts
+		existing.NextAttemptAt = d.NextAttemptAt
+		existing.LastStatusCode = d.LastStatusCode
+		existing.LastError = d.LastError
+		existing.DeliveredAt = d.DeliveredAt
+		return nil
+	}
+	// The subscription was deleted while the delivery was being sent.
+	return nil
+}
+
+func (r *WebhookRepo) Deliveries(ctx context.Context, lp repository.WebhookDeliveryListParams) ([]domain.WebhookDelivery, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	out := make([]domain.WebhookDelivery, 0)
+	for i := len(r.deliveries) - 1; i >= 0 && len(out) < limit; i-- {
+		d := r.deliveries[i]
+		if d.SubscriptionID != lp.SubscriptionID || lp.Status != "" && string(d.Status) != lp.Status {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+func (r *WebhookRepo) Redeliver(ctx context.Context, subscriptionID, id int) (domain.WebhookDelivery, error) {
+	r = r.of(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, d := range r.deliveries {
+		if d.ID != id || d.SubscriptionID != subscriptionID {
+			continue
+		}
+		now := time.Now().UTC()
+		return r.add(domain.WebhookDelivery{
+			SubscriptionID: d.SubscriptionID,
+			EventID:        d.EventID,
+			EventType:      d.EventType,
+			Payload:        d.Payload,
+			NextAttemptAt:  now,
+			RedeliveryOf:   d.ID,
+			CreatedAt:      now,
+		}), nil
+	}
+	return domain.WebhookDelivery{}, fmt.Errorf("%w: delivery %d of webhook %d", domain.ErrNotFound, id, subscriptionID)
+}
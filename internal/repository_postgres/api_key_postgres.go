@@ -0,0 +1,134 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"pos-api/internal/tenant"
+)
+
+// APIKeyRepo keeps the api_keys table, which row-level security does not
+// cover; the queries made for a tenant name it themselves.
+type APIKeyRepo struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepo(db *sql.DB) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+const apiKeyColumns = `id, tenant_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }, k *domain.APIKey) error {
+	var scopes []byte
+	if err := row.Scan(
+		&k.ID,
+		&k.TenantID,
+		&k.Name,
+		&k.Prefix,
+		&k.Hash,
+		&scopes,
+		&k.ExpiresAt,
+		&k.LastUsedAt,
+		&k.RevokedAt,
+		&k.CreatedAt,
+	); err != nil {
+		return err
+	}
+	return json.Unmarshal(scopes, &k.Scopes)
+}
+
+func (r *APIKeyRepo) Create(ctx context.Context, k domain.APIKey) (domain.APIKey, error) {
+	if k.Scopes == nil {
+		k.Scopes = []string{}
+	}
+	scopes, err := json.Marshal(k.Scopes)
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	var out domain.APIKey
+	err = scanAPIKey(r.db.QueryRowContext(ctx, `
+		INSERT INTO api_keys (tenant_id, name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5::jsonb, $6, NOW())
+		RETURNING `+apiKeyColumns,
+		k.TenantID, k.Name, k.Prefix, k.Hash, string(scopes), k.ExpiresAt), &out)
+	if err != nil {
+		return domain.APIKey{}, uniqueViolation(err)
+	}
+	return out, nil
+}
+
+func (r *APIKeyRepo) GetByID(ctx context.Context, id int) (domain.APIKey, error) {
+	return r.getOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1 AND tenant_id = $2`, id, tenant.From(ctx))
+}
+
+func (r *APIKeyRepo) GetByPrefix(ctx context.Context, prefix string) (domain.APIKey, error) {
+	return r.getOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = $1`, prefix)
+}
+
+func (r *APIKeyRepo) getOne(ctx context.Context, query string, args ...any) (domain.APIKey, error) {
+	var out domain.APIKey
+	if err := scanAPIKey(r.db.QueryRowContext(ctx, query, args...), &out); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.APIKey{}, domain.ErrNotFound
+		}
+		return domain.APIKey{}, err
+	}
+	return out, nil
+}
+
+func (r *APIKeyRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.APIKey, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE tenant_id = $1
+		ORDER BY id
+		LIMIT $2 OFFSET $3
+	`, tenant.From(ctx), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.APIKey, 0)
+	for rows.Next() {
+		var k domain.APIKey
+		if err := scanAPIKey(rows, &k); err != nil {
+			return nil, err
+		}
+		items = append(items, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *APIKeyRepo) Revoke(ctx context.Context, id int, at time.Time) (domain.APIKey, error) {
+	return r.getOne(ctx, `
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, $1)
+		WHERE id = $2 AND tenant_id = $3
+		RETURNING `+apiKeyColumns,
+		at, id, tenant.From(ctx))
+}
+
+func (r *APIKeyRepo) Touch(ctx context.Context, id int, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, at, id)
+	return err
+}
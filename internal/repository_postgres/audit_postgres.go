@@ -0,0 +1,123 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"pos-api/internal/actor"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+)
+
+type AuditRepo struct {
+	db *sql.DB
+}
+
+func NewAuditRepo(db *sql.DB) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+// recordAudit adds a change to the audit log, attributed to the actor of ctx.
+// It runs on the caller's transaction so the entry commits with the change.
+func recordAudit(ctx context.Context, q querier, action domain.AuditAction, entityType string, entityID int, before, after any) error {
+	e, err := domain.NewAuditEntry(action, entityType, entityID, before, after)
+	if err != nil {
+		return err
+	}
+	return recordAuditEntries(ctx, q, []domain.AuditEntry{e})
+}
+
+// recordAuditEntries adds entries to the audit log with one statement, in
+// order, attributed to the actor of ctx.
+func recordAuditEntries(ctx context.Context, q querier, entries []domain.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	ids := make([]int, len(entries))
+	actions := make([]string, len(entries))
+	types := make([]string, len(entries))
+	before := make([]string, len(entries))
+	after := make([]string, len(entries))
+	changes := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i], actions[i], types[i] = e.EntityID, string(e.Action), e.EntityType
+		before[i], after[i] = string(e.Before), string(e.After)
+		if e.Changes != nil {
+			b, err := json.Marshal(e.Changes)
+			if err != nil {
+				return err
+			}
+			changes[i] = string(b)
+		}
+	}
+
+	by := actor.From(ctx)
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO audit_log (actor, action, entity_type, entity_id, before, after, changes, request_id, ip, created_at)
+		SELECT $1, v.action, v.entity_type, v.entity_id, v.before::jsonb, v.after::jsonb, NULLIF(v.changes, '')::jsonb, $2, $3, NOW()
+		FROM unnest($4::text[], $5::text[], $6::int[], $7::text[], $8::text[], $9::text[]) WITH ORDINALITY
+			AS v(action, entity_type, entity_id, before, after, changes, n)
+		ORDER BY v.n
+	`, by.Name, by.RequestID, by.IP, actions, types, ids, before, after, changes)
+	return err
+}
+
+func (r *AuditRepo) List(ctx context.Context, lp repository.AuditListParams) ([]domain.AuditEntry, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, actor, action, entity_type, entity_id,
+			COALESCE(before, 'null'), COALESCE(after, 'null'), COALESCE(changes, 'null'),
+			request_id, ip, created_at
+		FROM audit_log
+		WHERE tenant_id = current_tenant_id()
+			AND ($1 = '' OR entity_type = $1)
+			AND ($2 = 0 OR entity_id = $2)
+			AND ($3 = '' OR actor = $3)
+		ORDER BY id DESC
+		LIMIT $4 OFFSET $5
+	`, lp.EntityType, lp.EntityID, lp.Actor, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.AuditEntry, 0)
+	for rows.Next() {
+		var e domain.AuditEntry
+		var before, after, changes []byte
+		if err := rows.Scan(
+			&e.ID,
+			&e.Actor,
+			&e.Action,
+			&e.EntityType,
+			&e.EntityID,
+			&before,
+			&after,
+			&changes,
+			&e.RequestID,
+			&e.IP,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		e.Before = before
+		e.After = after
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, err
+		}
+		items = append(items, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -18,50 +18,112 @@ func NewCategoryRepo(db *sql.DB) *CategoryRepo {
 	return &CategoryRepo{db: db}
 }
 
+const categoryColumns = `id, name, description, change_seq, created_at, updated_at`
+
+func scanCategory(row interface{ Scan(...any) error }, c *domain.Category) error {
+	return row.Scan(
+		&c.ID,
+		&c.Name,
+		&c.Description,
+		&c.ChangeSeq,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+}
+
 func (r *CategoryRepo) Create(ctx context.Context, c domain.Category) (domain.Category, error) {
+	var out domain.Category
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		out, err = createCategory(ctx, tx, c)
+		return err
+	})
+	if err != nil {
+		return domain.Category{}, err
+	}
+	return out, nil
+}
+
+func createCategory(ctx context.Context, q querier, c domain.Category) (domain.Category, error) {
 	c.Name = strings.TrimSpace(c.Name)
 	c.Description = strings.TrimSpace(c.Description)
 
 	var out domain.Category
-	err := r.db.QueryRowContext(ctx, `
+	err := scanCategory(q.QueryRowContext(ctx, `
 		INSERT INTO categories (name, description, created_at, updated_at)
 		VALUES ($1, $2, NOW(), NOW())
-		RETURNING id, name, description, created_at, updated_at
-	`, c.Name, c.Description).Scan(
-		&out.ID,
-		&out.Name,
-		&out.Description,
-		&out.CreatedAt,
-		&out.UpdatedAt,
-	)
+		RETURNING `+categoryColumns+`
+	`, c.Name, c.Description), &out)
 	if err != nil {
 		return domain.Category{}, err
 	}
+	if err := recordCategoryChange(ctx, q, domain.AuditCreate, nil, &out); err != nil {
+		return domain.Category{}, err
+	}
+	return out, nil
+}
+
+// lockCategory locks a category for the rest of the transaction and returns
+// it as it stands.
+func lockCategory(ctx context.Context, q querier, id int) (domain.Category, error) {
+	var out domain.Category
+	err := scanCategory(q.QueryRowContext(ctx, `
+		SELECT `+categoryColumns+`
+		FROM categories
+		WHERE id = $1 AND tenant_id = current_tenant_id()
+		FOR UPDATE
+	`, id), &out)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Category{}, domain.ErrNotFound
+		}
+		return domain.Category{}, err
+	}
 	return out, nil
 }
 
 func (r *CategoryRepo) GetByID(ctx context.Context, id int) (domain.Category, error) {
 	var out domain.Category
-	err := r.db.QueryRowContext(ctx, `
-		SELECT id, name, description, created_at, updated_at
+	err := scanCategory(r.db.QueryRowContext(ctx, `
+		SELECT `+categoryColumns+`
 		FROM categories
-		WHERE id = $1
-	`, id).Scan(
-		&out.ID,
-		&out.Name,
-		&out.Description,
-		&out.CreatedAt,
-		&out.UpdatedAt,
-	)
+		WHERE id = $1 AND tenant_id = current_tenant_id()
+	`, id), &out)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return domain.Category{}, errors.New("not found")
+			return domain.Category{}, domain.ErrNotFound
 		}
 		return domain.Category{}, err
 	}
 	return out, nil
 }
 
+func (r *CategoryRepo) GetByIDs(ctx context.Context, ids []int) ([]domain.Category, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+categoryColumns+`
+		FROM categories
+		WHERE id = ANY($1) AND tenant_id = current_tenant_id()
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.Category, 0, len(ids))
+	for rows.Next() {
+		var c domain.Category
+		if err := scanCategory(rows, &c); err != nil {
+			return nil, err
+		}
+		items = append(items, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 func (r *CategoryRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Category, error) {
 	limit := lp.Limit
 	offset := lp.Offset
@@ -73,8 +135,9 @@ func (r *CategoryRepo) List(ctx context.Context, lp repository.ListParams) ([]do
 	}
 
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, name, description, created_at, updated_at
+		SELECT `+categoryColumns+`
 		FROM categories
+		WHERE tenant_id = current_tenant_id()
 		ORDER BY id DESC
 		LIMIT $1 OFFSET $2
 	`, limit, offset)
@@ -86,13 +149,7 @@ func (r *CategoryRepo) List(ctx context.Context, lp repository.ListParams) ([]do
 	items := make([]domain.Category, 0)
 	for rows.Next() {
 		var c domain.Category
-		if err := rows.Scan(
-			&c.ID,
-			&c.Name,
-			&c.Description,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-		); err != nil {
+		if err := scanCategory(rows, &c); err != nil {
 			return nil, err
 		}
 		items = append(items, c)
@@ -103,47 +160,143 @@ func (r *CategoryRepo) List(ctx context.Context, lp repository.ListParams) ([]do
 	return items, nil
 }
 
+func (r *CategoryRepo) Export(ctx context.Context, fn func(domain.Category) error) error {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE tenant_id = current_tenant_id()
+		ORDER BY id`
+	return withCursor(ctx, r.db, query, nil, func(rows *sql.Rows) error {
+		var c domain.Category
+		if err := scanCategory(rows, &c); err != nil {
+			return err
+		}
+		return fn(c)
+	})
+}
+
 func (r *CategoryRepo) Update(ctx context.Context, id int, patch domain.Category) (domain.Category, error) {
+	var out domain.Category
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		out, err = updateCategory(ctx, tx, id, patch)
+		return err
+	})
+	if err != nil {
+		return domain.Category{}, err
+	}
+	return out, nil
+}
+
+func updateCategory(ctx context.Context, q querier, id int, patch domain.Category) (domain.Category, error) {
 	patch.Name = strings.TrimSpace(patch.Name)
 	patch.Description = strings.TrimSpace(patch.Description)
 
+	before, err := lockCategory(ctx, q, id)
+	if err != nil {
+		return domain.Category{}, err
+	}
+
 	var out domain.Category
-	err := r.db.QueryRowContext(ctx, `
+	err = scanCategory(q.QueryRowContext(ctx, `
 		UPDATE categories
 		SET name = $1, description = $2, updated_at = NOW()
-		WHERE id = $3
-		RETURNING id, name, description, created_at, updated_at
-	`, patch.Name, patch.Description, id).Scan(
-		&out.ID,
-		&out.Name,
-		&out.Description,
-		&out.CreatedAt,
-		&out.UpdatedAt,
-	)
+		WHERE id = $3 AND tenant_id = current_tenant_id()
+		RETURNING `+categoryColumns+`
+	`, patch.Name, patch.Description, id), &out)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return domain.Category{}, errors.New("not found")
-		}
+		return domain.Category{}, err
+	}
+	if err := recordCategoryChange(ctx, q, domain.AuditUpdate, &before, &out); err != nil {
 		return domain.Category{}, err
 	}
 	return out, nil
 }
 
-func (r *CategoryRepo) Delete(ctx context.Context, id int) error {
-	res, err := r.db.ExecContext(ctx, `
-		DELETE FROM categories
-		WHERE id = $1
-	`, id)
+// FindByName lists the categories with the given name, ignoring case.
+func (r *CategoryRepo) FindByName(ctx context.Context, name string) ([]domain.Category, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+categoryColumns+`
+		FROM categories
+		WHERE lower(name) = lower($1) AND tenant_id = current_tenant_id()
+		ORDER BY id
+	`, strings.TrimSpace(name))
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.Category, 0)
+	for rows.Next() {
+		var c domain.Category
+		if err := scanCategory(rows, &c); err != nil {
+			return nil, err
+		}
+		items = append(items, c)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
 
-	affected, err := res.RowsAffected()
+// SaveAll creates the categories without an ID and updates the rest in one
+// transaction.
+func (r *CategoryRepo) SaveAll(ctx context.Context, items []domain.Category) ([]domain.Category, error) {
+	out := make([]domain.Category, 0, len(items))
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, c := range items {
+			var saved domain.Category
+			var err error
+			if c.ID == 0 {
+				saved, err = createCategory(ctx, tx, c)
+			} else {
+				saved, err = updateCategory(ctx, tx, c.ID, c)
+			}
+			if err != nil {
+				return err
+			}
+			out = append(out, saved)
+		}
+		return nil
+	})
 	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *CategoryRepo) Delete(ctx context.Context, id int) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		before, err := lockCategory(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM categories
+			WHERE id = $1 AND tenant_id = current_tenant_id()
+		`, id); err != nil {
+			return err
+		}
+		return recordCategoryChange(ctx, tx, domain.AuditDelete, &before, nil)
+	})
+}
+
+// recordCategoryChange writes the audit entry and events for a category
+// going from before to after.
+func recordCategoryChange(ctx context.Context, q querier, action domain.AuditAction, before, after *domain.Category) error {
+	var id int
+	if after != nil {
+		id = after.ID
+	} else {
+		id = before.ID
+	}
+	if err := recordAudit(ctx, q, action, domain.AuditCategory, id, before, after); err != nil {
 		return err
 	}
-	if affected == 0 {
-		return errors.New("not found")
+	events, err := domain.CategoryEvents(before, after)
+	if err != nil {
+		return err
 	}
-	return nil
+	return recordEvents(ctx, q, events)
 }
@@ -0,0 +1,20 @@
+package repository_postgres
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"pos-api/internal/domain"
+)
+
+// uniqueViolation translates a Postgres unique constraint error into
+// domain.ErrDuplicate and passes every other error through.
+func uniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return fmt.Errorf("%w: %s", domain.ErrDuplicate, pgErr.Detail)
+	}
+	return err
+}
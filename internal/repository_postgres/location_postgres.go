@@ -0,0 +1,150 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+)
+
+type LocationRepo struct {
+	db *sql.DB
+}
+
+func NewLocationRepo(db *sql.DB) *LocationRepo {
+	return &LocationRepo{db: db}
+}
+
+func (r *LocationRepo) Create(ctx context.Context, l domain.Location) (domain.Location, error) {
+	l.Name = strings.TrimSpace(l.Name)
+	l.Address = strings.TrimSpace(l.Address)
+
+	var out domain.Location
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO locations (name, address, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		RETURNING id, name, address, created_at, updated_at
+	`, l.Name, l.Address).Scan(
+		&out.ID,
+		&out.Name,
+		&out.Address,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+	)
+	if err != nil {
+		return domain.Location{}, err
+	}
+	return out, nil
+}
+
+func (r *LocationRepo) GetByID(ctx context.Context, id int) (domain.Location, error) {
+	var out domain.Location
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, address, created_at, updated_at
+		FROM locations
+		WHERE id = $1 AND tenant_id = current_tenant_id()
+	`, id).Scan(
+		&out.ID,
+		&out.Name,
+		&out.Address,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Location{}, domain.ErrNotFound
+		}
+		return domain.Location{}, err
+	}
+	return out, nil
+}
+
+func (r *LocationRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Location, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, address, created_at, updated_at
+		FROM locations
+		WHERE tenant_id = current_tenant_id()
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.Location, 0)
+	for rows.Next() {
+		var l domain.Location
+		if err := rows.Scan(
+			&l.ID,
+			&l.Name,
+			&l.Address,
+			&l.CreatedAt,
+			&l.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *LocationRepo) Update(ctx context.Context, id int, patch domain.Location) (domain.Location, error) {
+	patch.Name = strings.TrimSpace(patch.Name)
+	patch.Address = strings.TrimSpace(patch.Address)
+
+	var out domain.Location
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE locations
+		SET name = $1, address = $2, updated_at = NOW()
+		WHERE id = $3 AND tenant_id = current_tenant_id()
+		RETURNING id, name, address, created_at, updated_at
+	`, patch.Name, patch.Address, id).Scan(
+		&out.ID,
+		&out.Name,
+		&out.Address,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Location{}, domain.ErrNotFound
+		}
+		return domain.Location{}, err
+	}
+	return out, nil
+}
+
+func (r *LocationRepo) Delete(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM locations
+		WHERE id = $1 AND tenant_id = current_tenant_id()
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+)
+
+type OrderRepo struct {
+	db *sql.DB
+}
+
+func NewOrderRepo(db *sql.DB) *OrderRepo {
+	return &OrderRepo{db: db}
+}
+
+const orderColumns = `id, COALESCE(client_id, ''), COALESCE(shift_id, 0), register_id, user_id, COALESCE(location_id, 0), subtotal, tax, total, paid, change, created_at`
+
+func scanOrder(row interface{ Scan(...any) error }, o *domain.Order) error {
+	return row.Scan(
+		&o.ID,
+		&o.ClientID,
+		&o.ShiftID,
+		&o.RegisterID,
+		&o.UserID,
+		&o.LocationID,
+		&o.Subtotal,
+		&o.Tax,
+		&o.Total,
+		&o.Paid,
+		&o.Change,
+		&o.CreatedAt,
+	)
+}
+
+func (r *OrderRepo) Create(ctx context.Context, o domain.Order) (domain.Order, error) {
+	var out domain.Order
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// FOR SHARE keeps the shift from closing until the order commits.
+		if o.ShiftID != 0 {
+			s, err := getShift(ctx, tx, o.ShiftID, "FOR SHARE")
+			if errors.Is(err, domain.ErrNotFound) {
+				return fmt.Errorf("%w: shift %d", domain.ErrNotFound, o.ShiftID)
+			}
+			if err != nil {
+				return err
+			}
+			if err := s.CheckSale(o.CreatedAt); err != nil {
+				return err
+			}
+		}
+
+		costs, err := applyStockChanges(ctx, tx, o.StockChanges(), true)
+		if err != nil {
+			return err
+		}
+
+		row := tx.QueryRowContext(ctx, `
+			INSERT INTO orders (client_id, shift_id, register_id, user_id, location_id, subtotal, tax, total, paid, change, created_at)
+			VALUES (NULLIF($1, ''), NULLIF($2, 0), $3, $4, NULLIF($5, 0), $6, $7, $8, $9, $10, COALESCE($11, NOW()))
+			RETURNING `+orderColumns,
+			strings.TrimSpace(o.ClientID), o.ShiftID, strings.TrimSpace(o.RegisterID), strings.TrimSpace(o.UserID), o.LocationID,
+			o.Subtotal, o.Tax, o.Total, o.Paid, o.Change, sql.NullTime{Time: o.CreatedAt, Valid: !o.CreatedAt.IsZero()})
+		if err := scanOrder(row, &out); err != nil {
+			return err
+		}
+
+		out.Items = make([]domain.OrderItem, 0, len(o.Items))
+		for i, it := range o.Items {
+			it.Cost = costs[i]
+			if err := tx.QueryRowContext(ctx, `
+				INSERT INTO order_items (order_id, product_id, variant_id, name, sku, quantity, unit_price, line_total,
+					shortfall, cost_fifo, cost_average, cost_last)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+				RETURNING id
+			`, out.ID, it.ProductID, it.VariantID, it.Name, it.SKU, it.Quantity, it.UnitPrice, it.LineTotal,
+				it.Shortfall, it.Cost.FIFO, it.Cost.Average, it.Cost.Last).Scan(&it.ID); err != nil {
+				return err
+			}
+			out.Items = append(out.Items, it)
+		}
+
+		out.Tenders = make([]domain.Tender, 0, len(o.Tenders))
+		for _, t := range o.Tenders {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO order_tenders (order_id, method, amount)
+				VALUES ($1, $2, $3)
+			`, out.ID, t.Method, t.Amount); err != nil {
+				return err
+			}
+			out.Tenders = append(out.Tenders, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Order{}, uniqueViolation(err)
+	}
+	return out, nil
+}
+
+func (r *OrderRepo) GetByID(ctx context.Context, id int) (domain.Order, error) {
+	return r.getOne(ctx, `SELECT `+orderColumns+` FROM orders WHERE id = $1 AND tenant_id = current_tenant_id()`, id)
+}
+
+func (r *OrderRepo) GetByClientID(ctx context.Context, clientID string) (domain.Order, error) {
+	return r.getOne(ctx, `SELECT `+orderColumns+` FROM orders WHERE client_id = $1 AND tenant_id = current_tenant_id()`, strings.TrimSpace(clientID))
+}
+
+func (r *OrderRepo) getOne(ctx context.Context, query string, arg any) (domain.Order, error) {
+	var out domain.Order
+	err := scanOrder(r.db.QueryRowContext(ctx, query, arg), &out)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Order{}, domain.ErrNotFound
+		}
+		return domain.Order{}, err
+	}
+
+	items := []domain.Order{out}
+	if err := r.loadLines(ctx, items); err != nil {
+		return domain.Order{}, err
+	}
+	return items[0], nil
+}
+
+func (r *OrderRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Order, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+orderColumns+`
+		FROM orders
+		WHERE tenant_id = current_tenant_id()
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.Order, 0)
+	for rows.Next() {
+		var o domain.Order
+		if err := scanOrder(rows, &o); err != nil {
+			return nil, err
+		}
+		items = append(items, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.loadLines(ctx, items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *OrderRepo) SalesByShift(ctx context.Context, shiftID int) (domain.ShiftSales, error) {
+	return salesByShift(ctx, r.db, shiftID)
+}
+
+// loadLines fills in the items and tenders of the given orders with one
+// query each.
+func (r *OrderRepo) loadLines(ctx context.Context, orders []domain.Order) error {
+	index := make(map[int]int, len(orders))
+	ids := make([]int, 0, len(orders))
+	for i := range orders {
+		orders[i].Items = []domain.OrderItem{}
+		orders[i].Tenders = []domain.Tender{}
+		index[orders[i].ID] = i
+		ids = append(ids, orders[i].ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	itemRows, err := r.db.QueryContext(ctx, `
+		SELECT order_id, id, product_id, variant_id, name, sku, quantity, unit_price, line_total,
+		       shortfall, cost_fifo, cost_average, cost_last
+		FROM order_items
+		WHERE order_id = ANY($1) AND tenant_id = current_tenant_id()
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var orderID int
+		var it domain.OrderItem
+		if err := itemRows.Scan(&orderID, &it.ID, &it.ProductID, &it.VariantID, &it.Name, &it.SKU, &it.Quantity, &it.UnitPrice, &it.LineTotal,
+			&it.Shortfall, &it.Cost.FIFO, &it.Cost.Average, &it.Cost.Last); err != nil {
+			return err
+		}
+		i := index[orderID]
+		orders[i].Items = append(orders[i].Items, it)
+	}
+	if err := itemRows.Err(); err != nil {
+		return err
+	}
+
+	tenderRows, err := r.db.QueryContext(ctx, `
+		SELECT order_id, method, amount
+		FROM order_tenders
+		WHERE order_id = ANY($1) AND tenant_id = current_tenant_id()
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer tenderRows.Close()
+
+	for tenderRows.Next() {
+		var orderID int
+		var t domain.Tender
+		if err := tenderRows.Scan(&orderID, &t.Method, &t.Amount); err != nil {
+			return err
+		}
+		i := index[orderID]
+		orders[i].Tenders = append(orders[i].Tenders, t)
+	}
+	return tenderRows.Err()
+}
+
+func salesByShift(ctx context.Context, q querier, shiftID int) (domain.ShiftSales, error) {
+	sales := domain.ShiftSales{Tenders: make(map[domain.TenderMethod]int)}
+	err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(subtotal), 0), COALESCE(SUM(tax), 0),
+		       COALESCE(SUM(total), 0), COALESCE(SUM(change), 0)
+		FROM orders
+		WHERE shift_id = $1 AND tenant_id = current_tenant_id()
+	`, shiftID).Scan(&sales.OrderCount, &sales.Subtotal, &sales.Tax, &sales.Total, &sales.Change)
+	if err != nil {
+		return domain.ShiftSales{}, err
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT t.method, SUM(t.amount)
+		FROM order_tenders t
+		JOIN orders o ON o.id = t.order_id AND o.tenant_id = current_tenant_id()
+		WHERE o.shift_id = $1 AND t.tenant_id = current_tenant_id()
+		GROUP BY t.method
+	`, shiftID)
+	if err != nil {
+		return domain.ShiftSales{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var method domain.TenderMethod
+		var amount int
+		if err := rows.Scan(&method, &amount); err != nil {
+			return domain.ShiftSales{}, err
+		}
+		sales.Tenders[method] = amount
+	}
+	if err := rows.Err(); err != nil {
+		return domain.ShiftSales{}, err
+	}
+	return sales, nil
+}
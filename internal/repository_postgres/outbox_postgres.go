@@ -0,0 +1,147 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"pos-api/internal/actor"
+	"pos-api/internal/domain"
+)
+
+type OutboxRepo struct {
+	db *sql.DB
+}
+
+func NewOutboxRepo(db *sql.DB) *OutboxRepo {
+	return &OutboxRepo{db: db}
+}
+
+// recordEvents adds events to the outbox with one statement, in order,
+// attributed to the actor of ctx. It runs on the caller's transaction so the
+// events commit with the change.
+func recordEvents(ctx context.Context, q querier, events []domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	types := make([]string, len(events))
+	ids := make([]int, len(events))
+	data := make([]string, len(events))
+	for i, e := range events {
+		types[i], ids[i], data[i] = string(e.Type), e.EntityID, string(e.Data)
+	}
+
+	by := actor.From(ctx)
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO outbox_events (type, entity_id, data, actor, request_id, occurred_at)
+		SELECT v.type, v.entity_id, v.data::jsonb, $1, $2, NOW()
+		FROM unnest($3::text[], $4::int[], $5::text[]) WITH ORDINALITY AS v(type, entity_id, data, n)
+		ORDER BY v.n
+	`, by.Name, by.RequestID, types, ids, data)
+	return err
+}
+
+// outboxRelayLock is the advisory lock relays hold, so they publish events
+// one batch at a time and in delivery_seq order.
+const outboxRelayLock = 0x6f7574626f78 // "outbox"
+
+func (r *OutboxRepo) Delivered(ctx context.Context, afterSeq, limit int) ([]domain.Event, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, delivery_seq, type, entity_id, data, actor, request_id, occurred_at
+		FROM outbox_events
+		WHERE delivery_seq > $1 AND delivered_at IS NOT NULL AND tenant_id = current_tenant_id()
+		ORDER BY delivery_seq
+		LIMIT $2
+	`, afterSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.Event, 0)
+	for rows.Next() {
+		var e domain.Event
+		var data []byte
+		if err := rows.Scan(&e.ID, &e.Seq, &e.Type, &e.EntityID, &data, &e.Actor, &e.RequestID, &e.OccurredAt); err != nil {
+			return nil, err
+		}
+		e.Data = data
+		items = append(items, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Relay holds an advisory lock until its transaction ends, so relays on
+// other instances wait for it to commit before they number any events.
+func (r *OutboxRepo) Relay(ctx context.Context, limit int, fn func(domain.Event) error) (int, error) {
+	n := 0
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, outboxRelayLock); err != nil {
+			return err
+		}
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id, type, entity_id, data, actor, request_id, occurred_at
+			FROM outbox_events
+			WHERE delivered_at IS NULL AND next_attempt_at <= NOW() AND tenant_id = current_tenant_id()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		`, limit)
+		if err != nil {
+			return err
+		}
+		due := make([]domain.Event, 0)
+		for rows.Next() {
+			var e domain.Event
+			var data []byte
+			if err := rows.Scan(&e.ID, &e.Type, &e.EntityID, &data, &e.Actor, &e.RequestID, &e.OccurredAt); err != nil {
+				rows.Close()
+				return err
+			}
+			e.Data = data
+			due = append(due, e)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, e := range due {
+			n++
+			if err := tx.QueryRowContext(ctx, `
+				UPDATE outbox_events SET delivery_seq = nextval('outbox_delivery_seq')
+				WHERE id = $1 AND tenant_id = current_tenant_id()
+				RETURNING delivery_seq
+			`, e.ID).Scan(&e.Seq); err != nil {
+				return err
+			}
+			if err := fn(e); err != nil {
+				// Back off exponentially, from 2 seconds up to 10 minutes.
+				_, err = tx.ExecContext(ctx, `
+					UPDATE outbox_events
+					SET attempts = attempts + 1, last_error = $2, delivery_seq = NULL,
+						next_attempt_at = NOW() + LEAST(INTERVAL '10 minutes', INTERVAL '1 second' * POWER(2, LEAST(attempts + 1, 10)))
+					WHERE id = $1 AND tenant_id = current_tenant_id()
+				`, e.ID, err.Error())
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE outbox_events
+				SET attempts = attempts + 1, last_error = '', delivered_at = NOW()
+				WHERE id = $1 AND tenant_id = current_tenant_id()
+			`, e.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
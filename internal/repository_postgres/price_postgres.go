@@ -0,0 +1,268 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pos-api/internal/actor"
+	"pos-api/internal/domain"
+)
+
+// dueBatch bounds how many scheduled prices one ApplyDue call applies.
+const dueBatch = 100
+
+type PriceRepo struct {
+	db *sql.DB
+}
+
+func NewPriceRepo(db *sql.DB) *PriceRepo {
+	return &PriceRepo{db: db}
+}
+
+const scheduledPriceColumns = `id, product_id, price, effective_from, reason, actor, status, created_at, applied_at`
+
+func scanScheduledPrice(row interface{ Scan(...any) error }, sp *domain.ScheduledPrice) error {
+	return row.Scan(
+		&sp.ID,
+		&sp.ProductID,
+		&sp.Price,
+		&sp.EffectiveFrom,
+		&sp.Reason,
+		&sp.Actor,
+		&sp.Status,
+		&sp.CreatedAt,
+		&sp.AppliedAt,
+	)
+}
+
+// recordPrice adds a price change to the history.
+func recordPrice(ctx context.Context, q querier, productID int, old *int, price int, by actor.Actor, scheduledID int) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO price_history (product_id, old_price, new_price, actor, reason, scheduled_price_id, changed_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), NOW())
+	`, productID, old, price, by.Name, by.Reason, scheduledID)
+	return err
+}
+
+// recordPrices records the prices of several products in one statement. old
+// holds their previous prices in the same order, or is empty for new
+// products: unnest pads it with NULLs.
+func recordPrices(ctx context.Context, q querier, productIDs, old, prices []int, by actor.Actor) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	if old == nil {
+		old = []int{}
+	}
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO price_history (product_id, old_price, new_price, actor, reason, changed_at)
+		SELECT v.product_id, v.old_price, v.new_price, $1, $2, NOW()
+		FROM unnest($3::int[], $4::int[], $5::int[]) AS v(product_id, old_price, new_price)
+	`, by.Name, by.Reason, productIDs, old, prices)
+	return err
+}
+
+func (r *PriceRepo) History(ctx context.Context, productID int) ([]domain.PriceChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, product_id, old_price, new_price, actor, reason, COALESCE(scheduled_price_id, 0), changed_at
+		FROM price_history
+		WHERE product_id = $1 AND tenant_id = current_tenant_id()
+		ORDER BY changed_at DESC, id DESC
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.PriceChange, 0)
+	for rows.Next() {
+		var c domain.PriceChange
+		if err := rows.Scan(
+			&c.ID,
+			&c.ProductID,
+			&c.OldPrice,
+			&c.NewPrice,
+			&c.Actor,
+			&c.Reason,
+			&c.ScheduledPriceID,
+			&c.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PriceRepo) Schedule(ctx context.Context, sp domain.ScheduledPrice) (domain.ScheduledPrice, error) {
+	var out domain.ScheduledPrice
+	err := scanScheduledPrice(r.db.QueryRowContext(ctx, `
+		INSERT INTO scheduled_prices (product_id, price, effective_from, reason, actor, status, created_at)
+		SELECT id, $2, $3, $4, $5, 'pending', NOW()
+		FROM products
+		WHERE id = $1 AND tenant_id = current_tenant_id()
+		RETURNING `+scheduledPriceColumns,
+		sp.ProductID, sp.Price, sp.EffectiveFrom, sp.Reason, sp.Actor,
+	), &out)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ScheduledPrice{}, domain.ErrNotFound
+		}
+		return domain.ScheduledPrice{}, err
+	}
+	return out, nil
+}
+
+func (r *PriceRepo) Upcoming(ctx context.Context, productID int) ([]domain.ScheduledPrice, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+scheduledPriceColumns+`
+		FROM scheduled_prices
+		WHERE product_id = $1 AND status = 'pending' AND tenant_id = current_tenant_id()
+		ORDER BY effective_from, id
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.ScheduledPrice, 0)
+	for rows.Next() {
+		var sp domain.ScheduledPrice
+		if err := scanScheduledPrice(rows, &sp); err != nil {
+			return nil, err
+		}
+		items = append(items, sp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PriceRepo) Cancel(ctx context.Context, productID, id int) (domain.ScheduledPrice, error) {
+	var out domain.ScheduledPrice
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		err := scanScheduledPrice(tx.QueryRowContext(ctx, `
+			SELECT `+scheduledPriceColumns+`
+			FROM scheduled_prices
+			WHERE id = $1 AND product_id = $2 AND tenant_id = current_tenant_id()
+			FOR UPDATE
+		`, id, productID), &out)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+		if out.Status != domain.ScheduledPricePending {
+			return fmt.Errorf("%w: scheduled price %d is already %s", domain.ErrConflict, id, out.Status)
+		}
+
+		out.Status = domain.ScheduledPriceCancelled
+		_, err = tx.ExecContext(ctx, `UPDATE scheduled_prices SET status = 'cancelled' WHERE id = $1 AND tenant_id = current_tenant_id()`, id)
+		return err
+	})
+	if err != nil {
+		return domain.ScheduledPrice{}, err
+	}
+	return out, nil
+}
+
+// ApplyDue applies up to dueBatch due prices. Rows locked by another
+// instance applying them at the same time are skipped.
+func (r *PriceRepo) ApplyDue(ctx context.Context, now time.Time) ([]domain.ScheduledPrice, error) {
+	applied := make([]domain.ScheduledPrice, 0)
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT `+scheduledPriceColumns+`
+			FROM scheduled_prices
+			WHERE status = 'pending' AND effective_from <= $1 AND tenant_id = current_tenant_id()
+			ORDER BY effective_from, id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		`, now, dueBatch)
+		if err != nil {
+			return err
+		}
+		due := make([]domain.ScheduledPrice, 0)
+		for rows.Next() {
+			var sp domain.ScheduledPrice
+			if err := scanScheduledPrice(rows, &sp); err != nil {
+				rows.Close()
+				return err
+			}
+			due = append(due, sp)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, sp := range due {
+			var old int
+			err := tx.QueryRowContext(ctx, `
+				UPDATE products p
+				SET price = $1, updated_at = NOW()
+				FROM (SELECT id, price FROM products WHERE id = $2 AND tenant_id = current_tenant_id() FOR UPDATE) old
+				WHERE p.id = old.id AND p.tenant_id = current_tenant_id()
+				RETURNING old.price
+			`, sp.Price, sp.ProductID).Scan(&old)
+			if err != nil {
+				return err
+			}
+			if old != sp.Price {
+				by := actor.Actor{Name: sp.Actor, Reason: sp.Reason}
+				if err := recordPrice(ctx, tx, sp.ProductID, &old, sp.Price, by, sp.ID); err != nil {
+					return err
+				}
+				e, err := domain.NewEvent(domain.EventProductPriceChanged, sp.ProductID, domain.PriceChangedEvent{
+					ProductID:        sp.ProductID,
+					OldPrice:         old,
+					NewPrice:         sp.Price,
+					ScheduledPriceID: sp.ID,
+				})
+				if err != nil {
+					return err
+				}
+				if err := recordEvents(actor.With(ctx, by), tx, []domain.Event{e}); err != nil {
+					return err
+				}
+			}
+
+			err = tx.QueryRowContext(ctx, `
+				UPDATE scheduled_prices
+				SET status = 'applied', applied_at = NOW()
+				WHERE id = $1 AND tenant_id = current_tenant_id()
+				RETURNING status, applied_at
+			`, sp.ID).Scan(&sp.Status, &sp.AppliedAt)
+			if err != nil {
+				return err
+			}
+			applied = append(applied, sp)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+func (r *PriceRepo) NextDue(ctx context.Context) (time.Time, bool, error) {
+	var next sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT MIN(effective_from)
+		FROM scheduled_prices
+		WHERE status = 'pending' AND tenant_id = current_tenant_id()
+	`).Scan(&next)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return next.Time, next.Valid, nil
+}
@@ -3,9 +3,13 @@ package repository_postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 
+	"pos-api/internal/actor"
 	"pos-api/internal/domain"
 	"pos-api/internal/repository"
 )
@@ -18,52 +22,173 @@ func NewProductRepo(db *sql.DB) *ProductRepo {
 	return &ProductRepo{db: db}
 }
 
+const productSelect = `
+	SELECT p.id, p.name, COALESCE(p.sku, ''),
+		COALESCE((SELECT string_agg(b.barcode, ',' ORDER BY b.barcode) FROM product_barcodes b WHERE b.product_id = p.id AND b.tenant_id = current_tenant_id()), ''),
+		COALESCE(p.category_id, 0), p.price, p.quantity, p.reorder_point, p.reorder_quantity, p.options,
+		COALESCE((
+			SELECT json_agg(json_build_object(
+				'id', v.id,
+				'product_id', v.product_id,
+				'sku', COALESCE(v.sku, ''),
+				'options', v.options,
+				'price', v.price,
+				'quantity', v.quantity
+			) ORDER BY v.id)
+			FROM product_variants v WHERE v.product_id = p.id AND v.tenant_id = current_tenant_id()
+		), '[]'),
+		p.change_seq, p.created_at, p.updated_at
+	FROM products p`
+
+func scanProduct(row interface{ Scan(...any) error }, p *domain.Product) error {
+	var barcodes string
+	var options, variants []byte
+	if err := row.Scan(
+		&p.ID,
+		&p.Name,
+		&p.SKU,
+		&barcodes,
+		&p.CategoryID,
+		&p.Price,
+		&p.Quantity,
+		&p.ReorderPoint,
+		&p.ReorderQuantity,
+		&options,
+		&variants,
+		&p.ChangeSeq,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	); err != nil {
+		return err
+	}
+	p.Barcodes = []string{}
+	if barcodes != "" {
+		p.Barcodes = strings.Split(barcodes, ",")
+	}
+	if err := json.Unmarshal(options, &p.Options); err != nil {
+		return err
+	}
+	return json.Unmarshal(variants, &p.Variants)
+}
+
 func (r *ProductRepo) Create(ctx context.Context, p domain.Product) (domain.Product, error) {
+	var out domain.Product
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		out, err = createProduct(ctx, tx, p)
+		return err
+	})
+	if err != nil {
+		return domain.Product{}, uniqueViolation(err)
+	}
+	return out, nil
+}
+
+func createProduct(ctx context.Context, tx *sql.Tx, p domain.Product) (domain.Product, error) {
 	p.Name = strings.TrimSpace(p.Name)
+	p.SKU = strings.TrimSpace(p.SKU)
 
-	var out domain.Product
-	err := r.db.QueryRowContext(ctx, `
-		INSERT INTO products (name, price, quantity, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
-		RETURNING id, name, price, quantity, created_at, updated_at
-	`, p.Name, p.Price, p.Quantity).Scan(
-		&out.ID,
-		&out.Name,
-		&out.Price,
-		&out.Quantity,
-		&out.CreatedAt,
-		&out.UpdatedAt,
-	)
+	options, err := marshalOptions(p.Options)
 	if err != nil {
 		return domain.Product{}, err
 	}
+
+	var id int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO products (name, sku, category_id, price, quantity, reorder_point, reorder_quantity, options, created_at, updated_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, 0), $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING id
+	`, p.Name, p.SKU, p.CategoryID, p.Price, p.Quantity, p.ReorderPoint, p.ReorderQuantity, options).Scan(&id)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	if err := insertBarcodes(ctx, tx, id, p.Barcodes); err != nil {
+		return domain.Product{}, err
+	}
+	if err := saveVariants(ctx, tx, id, p.Variants); err != nil {
+		return domain.Product{}, err
+	}
+	if err := recordPrice(ctx, tx, id, nil, p.Price, actor.From(ctx), 0); err != nil {
+		return domain.Product{}, err
+	}
+
+	var out domain.Product
+	if err := scanProduct(tx.QueryRowContext(ctx, productSelect+` WHERE p.id = $1 AND p.tenant_id = current_tenant_id()`, id), &out); err != nil {
+		return domain.Product{}, err
+	}
+	if err := recordProductChange(ctx, tx, domain.AuditCreate, nil, &out); err != nil {
+		return domain.Product{}, err
+	}
 	return out, nil
 }
 
+// lockProduct locks a product for the rest of the transaction and returns it
+// as it stands.
+func lockProduct(ctx context.Context, q querier, id int) (domain.Product, error) {
+	if err := q.QueryRowContext(ctx, `SELECT id FROM products WHERE id = $1 AND tenant_id = current_tenant_id() FOR UPDATE`, id).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Product{}, domain.ErrNotFound
+		}
+		return domain.Product{}, err
+	}
+	var out domain.Product
+	err := scanProduct(q.QueryRowContext(ctx, productSelect+` WHERE p.id = $1 AND p.tenant_id = current_tenant_id()`, id), &out)
+	return out, err
+}
+
 func (r *ProductRepo) GetByID(ctx context.Context, id int) (domain.Product, error) {
+	return getProduct(ctx, r.db, productSelect+` WHERE p.id = $1 AND p.tenant_id = current_tenant_id()`, id)
+}
+
+func (r *ProductRepo) GetBySKU(ctx context.Context, sku string) (domain.Product, error) {
+	return getProduct(ctx, r.db, productSelect+` WHERE p.sku = $1 AND p.tenant_id = current_tenant_id()`, sku)
+}
+
+func (r *ProductRepo) GetByBarcode(ctx context.Context, barcode string) (domain.Product, error) {
+	return getProduct(ctx, r.db, productSelect+` JOIN product_barcodes pb ON pb.product_id = p.id AND pb.tenant_id = current_tenant_id() WHERE pb.barcode = $1 AND p.tenant_id = current_tenant_id()`, barcode)
+}
+
+// FindByName lists the products with the given name, ignoring case.
+func (r *ProductRepo) FindByName(ctx context.Context, name string) ([]domain.Product, error) {
+	rows, err := r.db.QueryContext(ctx, productSelect+` WHERE lower(p.name) = lower($1) AND p.tenant_id = current_tenant_id() ORDER BY p.id`, strings.TrimSpace(name))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.Product, 0)
+	for rows.Next() {
+		var p domain.Product
+		if err := scanProduct(rows, &p); err != nil {
+			return nil, err
+		}
+		items = append(items, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func getProduct(ctx context.Context, q querier, query string, arg any) (domain.Product, error) {
 	var out domain.Product
-	err := r.db.QueryRowContext(ctx, `
-		SELECT id, name, price, quantity, created_at, updated_at
-		FROM products
-		WHERE id = $1
-	`, id).Scan(
-		&out.ID,
-		&out.Name,
-		&out.Price,
-		&out.Quantity,
-		&out.CreatedAt,
-		&out.UpdatedAt,
-	)
+	err := scanProduct(q.QueryRowContext(ctx, query, arg), &out)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return domain.Product{}, errors.New("not found")
+			return domain.Product{}, domain.ErrNotFound
 		}
 		return domain.Product{}, err
 	}
 	return out, nil
 }
 
-func (r *ProductRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Product, error) {
+// productFilter restricts productSelect to the filters of repository.ProductListParams.
+const productFilter = `
+	WHERE p.tenant_id = current_tenant_id()
+		AND ($1 = '' OR strpos(lower(p.name), lower($1)) > 0 OR strpos(lower(COALESCE(p.sku, '')), lower($1)) > 0)
+		AND ($2 = 0 OR p.category_id = $2)`
+
+func (r *ProductRepo) List(ctx context.Context, lp repository.ProductListParams) ([]domain.Product, error) {
 	limit := lp.Limit
 	offset := lp.Offset
 	if limit <= 0 || limit > 200 {
@@ -73,10 +198,93 @@ func (r *ProductRepo) List(ctx context.Context, lp repository.ListParams) ([]dom
 		offset = 0
 	}
 
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, name, price, quantity, created_at, updated_at
-		FROM products
-		ORDER BY id DESC
+	rows, err := r.db.QueryContext(ctx, productSelect+productFilter+`
+		ORDER BY p.id DESC
+		LIMIT $3 OFFSET $4
+	`, strings.TrimSpace(lp.Query), lp.CategoryID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.Product, 0)
+	for rows.Next() {
+		var p domain.Product
+		if err := scanProduct(rows, &p); err != nil {
+			return nil, err
+		}
+		items = append(items, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *ProductRepo) ListByCategories(ctx context.Context, categoryIDs []int, lp repository.ListParams) (map[int][]domain.Product, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, productSelect+`
+		WHERE p.tenant_id = current_tenant_id() AND p.id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY category_id ORDER BY id DESC) AS n
+				FROM products
+				WHERE category_id = ANY($1) AND tenant_id = current_tenant_id()
+			) ranked
+			WHERE n > $2 AND n <= $2 + $3
+		)
+		ORDER BY p.id DESC
+	`, categoryIDs, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make(map[int][]domain.Product, len(categoryIDs))
+	for rows.Next() {
+		var p domain.Product
+		if err := scanProduct(rows, &p); err != nil {
+			return nil, err
+		}
+		items[p.CategoryID] = append(items[p.CategoryID], p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *ProductRepo) Export(ctx context.Context, lp repository.ProductListParams, fn func(domain.Product) error) error {
+	query := productSelect + productFilter + ` ORDER BY p.id`
+	return withCursor(ctx, r.db, query, []any{strings.TrimSpace(lp.Query), lp.CategoryID}, func(rows *sql.Rows) error {
+		var p domain.Product
+		if err := scanProduct(rows, &p); err != nil {
+			return err
+		}
+		return fn(p)
+	})
+}
+
+func (r *ProductRepo) ListLowStock(ctx context.Context, lp repository.ListParams) ([]domain.Product, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, productSelect+`
+		WHERE p.reorder_point > 0 AND p.quantity <= p.reorder_point AND p.tenant_id = current_tenant_id()
+		ORDER BY p.quantity - p.reorder_point, p.id
 		LIMIT $1 OFFSET $2
 	`, limit, offset)
 	if err != nil {
@@ -87,14 +295,7 @@ func (r *ProductRepo) List(ctx context.Context, lp repository.ListParams) ([]dom
 	items := make([]domain.Product, 0)
 	for rows.Next() {
 		var p domain.Product
-		if err := rows.Scan(
-			&p.ID,
-			&p.Name,
-			&p.Price,
-			&p.Quantity,
-			&p.CreatedAt,
-			&p.UpdatedAt,
-		); err != nil {
+		if err := scanProduct(rows, &p); err != nil {
 			return nil, err
 		}
 		items = append(items, p)
@@ -106,46 +307,888 @@ func (r *ProductRepo) List(ctx context.Context, lp repository.ListParams) ([]dom
 }
 
 func (r *ProductRepo) Update(ctx context.Context, id int, patch domain.Product) (domain.Product, error) {
+	var out domain.Product
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		out, err = updateProduct(ctx, tx, id, patch)
+		return err
+	})
+	if err != nil {
+		return domain.Product{}, uniqueViolation(err)
+	}
+	return out, nil
+}
+
+func updateProduct(ctx context.Context, tx *sql.Tx, id int, patch domain.Product) (domain.Product, error) {
 	patch.Name = strings.TrimSpace(patch.Name)
+	patch.SKU = strings.TrimSpace(patch.SKU)
 
-	var out domain.Product
-	err := r.db.QueryRowContext(ctx, `
+	options, err := marshalOptions(patch.Options)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	before, err := lockProduct(ctx, tx, id)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	_, err = tx.ExecContext(ctx, `
 		UPDATE products
-		SET name = $1, price = $2, quantity = $3, updated_at = NOW()
-		WHERE id = $4
-		RETURNING id, name, price, quantity, created_at, updated_at
-	`, patch.Name, patch.Price, patch.Quantity, id).Scan(
-		&out.ID,
-		&out.Name,
-		&out.Price,
-		&out.Quantity,
-		&out.CreatedAt,
-		&out.UpdatedAt,
-	)
+		SET name = $1, sku = NULLIF($2, ''), category_id = NULLIF($3, 0), price = $4,
+			reorder_point = $5, reorder_quantity = $6, options = $7, updated_at = NOW()
+		WHERE id = $8 AND tenant_id = current_tenant_id()
+	`, patch.Name, patch.SKU, patch.CategoryID, patch.Price, patch.ReorderPoint, patch.ReorderQuantity, options, id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return domain.Product{}, errors.New("not found")
+		return domain.Product{}, err
+	}
+	if before.Price != patch.Price {
+		if err := recordPrice(ctx, tx, id, &before.Price, patch.Price, actor.From(ctx), 0); err != nil {
+			return domain.Product{}, err
 		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_barcodes WHERE product_id = $1 AND tenant_id = current_tenant_id()`, id); err != nil {
+		return domain.Product{}, err
+	}
+	if err := insertBarcodes(ctx, tx, id, patch.Barcodes); err != nil {
+		return domain.Product{}, err
+	}
+	if err := saveVariants(ctx, tx, id, patch.Variants); err != nil {
 		return domain.Product{}, err
 	}
+	// The stock of a product with variants is theirs, which changes when
+	// variants are added with opening stock or removed.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE products
+		SET quantity = v.total
+		FROM (SELECT SUM(quantity) AS total FROM product_variants WHERE product_id = $1 AND tenant_id = current_tenant_id()) v
+		WHERE id = $1 AND tenant_id = current_tenant_id() AND v.total IS NOT NULL
+	`, id); err != nil {
+		return domain.Product{}, err
+	}
+
+	var out domain.Product
+	if err := scanProduct(tx.QueryRowContext(ctx, productSelect+` WHERE p.id = $1 AND p.tenant_id = current_tenant_id()`, id), &out); err != nil {
+		return domain.Product{}, err
+	}
+	if err := recordProductChange(ctx, tx, domain.AuditUpdate, &before, &out); err != nil {
+		return domain.Product{}, err
+	}
+	return out, nil
+}
+
+// SaveAll creates the products without an ID and updates the rest in one
+// transaction, so either all of them are saved or none are.
+func (r *ProductRepo) SaveAll(ctx context.Context, items []domain.Product) ([]domain.Product, error) {
+	out := make([]domain.Product, 0, len(items))
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, p := range items {
+			var saved domain.Product
+			var err error
+			if p.ID == 0 {
+				saved, err = createProduct(ctx, tx, p)
+			} else {
+				saved, err = updateProduct(ctx, tx, p.ID, p)
+			}
+			if err != nil {
+				return err
+			}
+			out = append(out, saved)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, uniqueViolation(err)
+	}
 	return out, nil
 }
 
 func (r *ProductRepo) Delete(ctx context.Context, id int) error {
-	res, err := r.db.ExecContext(ctx, `
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		return deleteProduct(ctx, tx, id)
+	})
+}
+
+func deleteProduct(ctx context.Context, q querier, id int) error {
+	before, err := lockProduct(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, `
 		DELETE FROM products
-		WHERE id = $1
-	`, id)
+		WHERE id = $1 AND tenant_id = current_tenant_id()
+	`, id); err != nil {
+		return err
+	}
+	return recordProductChange(ctx, q, domain.AuditDelete, &before, nil)
+}
+
+// recordProductChange writes the audit entry and events for a product going
+// from before to after.
+func recordProductChange(ctx context.Context, q querier, action domain.AuditAction, before, after *domain.Product) error {
+	var id int
+	if after != nil {
+		id = after.ID
+	} else {
+		id = before.ID
+	}
+	if err := recordAudit(ctx, q, action, domain.AuditProduct, id, before, after); err != nil {
+		return err
+	}
+	events, err := domain.ProductEvents(before, after)
 	if err != nil {
 		return err
 	}
+	return recordEvents(ctx, q, events)
+}
+
+// ApplyBatch runs ops in one transaction, with a statement per kind of change
+// rather than per operation. The products ops update or delete are locked, in
+// ID order, before any operation is checked, so the checks hold until commit.
+func (r *ProductRepo) ApplyBatch(ctx context.Context, ops []domain.ProductBatchOp, atomic bool, check repository.BatchCheck) ([]domain.Product, []error, error) {
+	saved := make([]domain.Product, len(ops))
+	errs := make([]error, len(ops))
+	errFailed := errors.New("batch failed")
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		ids := make([]int, 0, len(ops))
+		for _, op := range ops {
+			if op.Action != domain.BatchCreate {
+				ids = append(ids, op.ID)
+			}
+		}
+		before, err := lockProducts(ctx, tx, ids)
+		if err != nil {
+			return err
+		}
+
+		checked := make([]domain.ProductBatchOp, 0, len(ops))
+		index := make([]int, 0, len(ops))
+		for i, op := range ops {
+			switch op.Action {
+			case domain.BatchCreate, domain.BatchUpdate:
+				op, errs[i] = check(ctx, txProducts{tx}, op)
+			case domain.BatchDelete:
+				if _, ok := before[op.ID]; !ok {
+					errs[i] = domain.ErrNotFound
+					break
+				}
+				op, errs[i] = check(ctx, txProducts{tx}, op)
+			default:
+				errs[i] = fmt.Errorf("%w: unknown action %q", domain.ErrInvalid, op.Action)
+			}
+			if errs[i] != nil {
+				if atomic {
+					return errFailed
+				}
+				continue
+			}
+			checked = append(checked, op)
+			index = append(index, i)
+		}
 
-	affected, err := res.RowsAffected()
+		out, err := applyProductBatch(ctx, tx, checked, before)
+		if err != nil {
+			return err
+		}
+		for j, i := range index {
+			saved[i] = out[j]
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errFailed) {
+		return nil, nil, uniqueViolation(err)
+	}
+	return saved, errs, nil
+}
+
+// lockProducts locks the given products, in ID order, for the rest of the
+// transaction and returns those that exist by ID.
+func lockProducts(ctx context.Context, tx *sql.Tx, ids []int) (map[int]domain.Product, error) {
+	out := make(map[int]domain.Product, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	if _, err := tx.ExecContext(ctx, `
+		SELECT id FROM products
+		WHERE id = ANY($1) AND tenant_id = current_tenant_id()
+		ORDER BY id
+		FOR UPDATE
+	`, ids); err != nil {
+		return nil, err
+	}
+	items, err := changedProducts(ctx, tx, ids)
 	if err != nil {
+		return nil, err
+	}
+	for _, p := range items {
+		out[p.ID] = p
+	}
+	return out, nil
+}
+
+// txProducts reads products on a transaction.
+type txProducts struct {
+	tx *sql.Tx
+}
+
+func (t txProducts) GetByID(ctx context.Context, id int) (domain.Product, error) {
+	return getProduct(ctx, t.tx, productSelect+` WHERE p.id = $1 AND p.tenant_id = current_tenant_id()`, id)
+}
+
+func (t txProducts) GetBySKU(ctx context.Context, sku string) (domain.Product, error) {
+	return getProduct(ctx, t.tx, productSelect+` WHERE p.sku = $1 AND p.tenant_id = current_tenant_id()`, sku)
+}
+
+func (t txProducts) GetByBarcode(ctx context.Context, barcode string) (domain.Product, error) {
+	return getProduct(ctx, t.tx, productSelect+` JOIN product_barcodes pb ON pb.product_id = p.id AND pb.tenant_id = current_tenant_id() WHERE pb.barcode = $1 AND p.tenant_id = current_tenant_id()`, barcode)
+}
+
+// applyProductBatch applies checked batch operations, whose products before
+// holds as they stood, and returns the saved product of each (zero for
+// deletes).
+func applyProductBatch(ctx context.Context, tx *sql.Tx, ops []domain.ProductBatchOp, before map[int]domain.Product) ([]domain.Product, error) {
+	var creates, updates []domain.Product
+	var deleted []int
+	for _, op := range ops {
+		switch op.Action {
+		case domain.BatchCreate:
+			creates = append(creates, op.Product)
+		case domain.BatchUpdate:
+			op.Product.ID = op.ID
+			updates = append(updates, op.Product)
+		case domain.BatchDelete:
+			deleted = append(deleted, op.ID)
+		}
+	}
+
+	if len(deleted) > 0 {
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM products
+			WHERE id = ANY($1) AND tenant_id = current_tenant_id()
+		`, deleted); err != nil {
+			return nil, err
+		}
+	}
+	// New products get their IDs first, so each row is known to belong to
+	// its operation.
+	if err := assignProductIDs(ctx, tx, creates); err != nil {
+		return nil, err
+	}
+	if err := insertProducts(ctx, tx, creates); err != nil {
+		return nil, err
+	}
+	if err := updateProducts(ctx, tx, updates); err != nil {
+		return nil, err
+	}
+
+	written := append(slices.Clip(creates), updates...)
+	ids := make([]int, 0, len(written))
+	for _, p := range written {
+		ids = append(ids, p.ID)
+	}
+	updatedIDs := ids[len(creates):]
+	if len(updatedIDs) > 0 {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM product_barcodes WHERE product_id = ANY($1) AND tenant_id = current_tenant_id()`, updatedIDs); err != nil {
+			return nil, err
+		}
+	}
+	if err := insertBarcodesOf(ctx, tx, written); err != nil {
+		return nil, err
+	}
+	if err := saveVariantsOf(ctx, tx, written); err != nil {
+		return nil, err
+	}
+	if err := totalVariantStock(ctx, tx, updatedIDs); err != nil {
+		return nil, err
+	}
+
+	by := actor.From(ctx)
+	var changedIDs, oldPrices, newPrices, createdIDs, createdPrices []int
+	for _, p := range creates {
+		createdIDs = append(createdIDs, p.ID)
+		createdPrices = append(createdPrices, p.Price)
+	}
+	for _, p := range updates {
+		if old := before[p.ID].Price; old != p.Price {
+			changedIDs = append(changedIDs, p.ID)
+			oldPrices = append(oldPrices, old)
+			newPrices = append(newPrices, p.Price)
+		}
+	}
+	if err := recordPrices(ctx, tx, createdIDs, nil, createdPrices, by); err != nil {
+		return nil, err
+	}
+	if err := recordPrices(ctx, tx, changedIDs, oldPrices, newPrices, by); err != nil {
+		return nil, err
+	}
+
+	after := make(map[int]domain.Product, len(ids))
+	if len(ids) > 0 {
+		items, err := changedProducts(ctx, tx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range items {
+			after[p.ID] = p
+		}
+	}
+
+	out := make([]domain.Product, len(ops))
+	entries := make([]domain.AuditEntry, 0, len(ops))
+	events := make([]domain.Event, 0, len(ops))
+	created := 0
+	for i, op := range ops {
+		var from, to *domain.Product
+		var action domain.AuditAction
+		switch op.Action {
+		case domain.BatchCreate:
+			out[i] = after[creates[created].ID]
+			created++
+			action, to = domain.AuditCreate, &out[i]
+		case domain.BatchUpdate:
+			out[i] = after[op.ID]
+			b := before[op.ID]
+			action, from, to = domain.AuditUpdate, &b, &out[i]
+		case domain.BatchDelete:
+			b := before[op.ID]
+			action, from = domain.AuditDelete, &b
+		}
+		id := op.ID
+		if to != nil {
+			id = to.ID
+		}
+		e, err := domain.NewAuditEntry(action, domain.AuditProduct, id, from, to)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+		changed, err := domain.ProductEvents(from, to)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, changed...)
+	}
+	if err := recordAuditEntries(ctx, tx, entries); err != nil {
+		return nil, err
+	}
+	if err := recordEvents(ctx, tx, events); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func insertBarcodes(ctx context.Context, tx *sql.Tx, productID int, barcodes []string) error {
+	return insertBarcodesOf(ctx, tx, []domain.Product{{ID: productID, Barcodes: barcodes}})
+}
+
+// insertBarcodesOf adds the barcodes of every product in one statement.
+func insertBarcodesOf(ctx context.Context, tx *sql.Tx, products []domain.Product) error {
+	var codes []string
+	var ids []int
+	for _, p := range products {
+		for _, code := range p.Barcodes {
+			codes = append(codes, code)
+			ids = append(ids, p.ID)
+		}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO product_barcodes (barcode, product_id)
+		SELECT v.barcode, v.product_id FROM unnest($1::text[], $2::int[]) AS v(barcode, product_id)
+	`, codes, ids)
+	return err
+}
+
+func marshalOptions(options []domain.ProductOption) ([]byte, error) {
+	if options == nil {
+		options = []domain.ProductOption{}
+	}
+	return json.Marshal(options)
+}
+
+// saveVariants makes the stored variants match the given set: variants with
+// an ID are updated, new ones inserted and the rest deleted. The stock of a
+// variant is only set when it is inserted; after that it changes with stock
+// movements.
+func saveVariants(ctx context.Context, tx *sql.Tx, productID int, variants []domain.ProductVariant) error {
+	return saveVariantsOf(ctx, tx, []domain.Product{{ID: productID, Variants: variants}})
+}
+
+// saveVariantsOf does what saveVariants does for every product at once, with
+// one statement each for deleting, updating and inserting variants.
+func saveVariantsOf(ctx context.Context, tx *sql.Tx, products []domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	productIDs := make([]int, 0, len(products))
+	keep := make([]int, 0)
+	var updated, inserted variantRows
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+		for _, v := range p.Variants {
+			var err error
+			if v.ID == 0 {
+				err = inserted.add(p.ID, v)
+			} else {
+				keep = append(keep, v.ID)
+				err = updated.add(p.ID, v)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM product_variants
+		WHERE product_id = ANY($1) AND NOT (id = ANY($2)) AND tenant_id = current_tenant_id()
+	`, productIDs, keep); err != nil {
 		return err
 	}
-	if affected == 0 {
-		return errors.New("not found")
+
+	if len(updated.ids) > 0 {
+		rows, err := tx.QueryContext(ctx, `
+			UPDATE product_variants pv
+			SET sku = NULLIF(v.sku, ''), options = v.options::jsonb, price = v.price
+			FROM unnest($1::int[], $2::int[], $3::text[], $4::text[], $5::int[]) AS v(id, product_id, sku, options, price)
+			WHERE pv.id = v.id AND pv.product_id = v.product_id AND pv.tenant_id = current_tenant_id()
+			RETURNING pv.id
+		`, updated.ids, updated.productIDs, updated.skus, updated.options, updated.prices)
+		if err != nil {
+			return err
+		}
+		found := make(map[int]bool, len(updated.ids))
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			found[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		for i, id := range updated.ids {
+			if !found[id] {
+				return fmt.Errorf("%w: variant %d of product %d", domain.ErrNotFound, id, updated.productIDs[i])
+			}
+		}
+	}
+
+	if len(inserted.productIDs) > 0 {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO product_variants (product_id, sku, options, price, quantity)
+			SELECT v.product_id, NULLIF(v.sku, ''), v.options::jsonb, v.price, v.quantity
+			FROM unnest($1::int[], $2::text[], $3::text[], $4::int[], $5::int[]) WITH ORDINALITY
+				AS v(product_id, sku, options, price, quantity, n)
+			ORDER BY v.n
+		`, inserted.productIDs, inserted.skus, inserted.options, inserted.prices, inserted.quantities); err != nil {
+			return err
+		}
 	}
 	return nil
 }
+
+// variantRows holds variants column by column, for unnest.
+type variantRows struct {
+	ids, productIDs, quantities []int
+	prices                      []*int
+	skus, options               []string
+}
+
+func (r *variantRows) add(productID int, v domain.ProductVariant) error {
+	options, err := json.Marshal(v.Options)
+	if err != nil {
+		return err
+	}
+	r.ids = append(r.ids, v.ID)
+	r.productIDs = append(r.productIDs, productID)
+	r.quantities = append(r.quantities, v.Quantity)
+	r.prices = append(r.prices, v.Price)
+	r.skus = append(r.skus, v.SKU)
+	r.options = append(r.options, string(options))
+	return nil
+}
+
+// totalVariantStock sets the quantity of each of the products that has
+// variants to theirs, which changes when variants are added with opening
+// stock or removed.
+func totalVariantStock(ctx context.Context, tx *sql.Tx, productIDs []int) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `
+		UPDATE products p
+		SET quantity = v.total
+		FROM (
+			SELECT product_id, SUM(quantity) AS total
+			FROM product_variants
+			WHERE product_id = ANY($1) AND tenant_id = current_tenant_id()
+			GROUP BY product_id
+		) v
+		WHERE p.id = v.product_id AND p.tenant_id = current_tenant_id()
+	`, productIDs)
+	return err
+}
+
+// assignProductIDs takes an ID for each of products from the products
+// sequence.
+func assignProductIDs(ctx context.Context, tx *sql.Tx, products []domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT nextval(pg_get_serial_sequence('products', 'id')) FROM generate_series(1, $1)`, len(products))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for i := 0; rows.Next(); i++ {
+		if err := rows.Scan(&products[i].ID); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// productRows holds products column by column, for unnest.
+type productRows struct {
+	ids, categoryIDs, prices, quantities, reorderPoints, reorderQuantities []int
+	names, skus, options                                                   []string
+}
+
+func newProductRows(products []domain.Product) (productRows, error) {
+	var r productRows
+	for _, p := range products {
+		options, err := marshalOptions(p.Options)
+		if err != nil {
+			return productRows{}, err
+		}
+		r.ids = append(r.ids, p.ID)
+		r.categoryIDs = append(r.categoryIDs, p.CategoryID)
+		r.prices = append(r.prices, p.Price)
+		r.quantities = append(r.quantities, p.Quantity)
+		r.reorderPoints = append(r.reorderPoints, p.ReorderPoint)
+		r.reorderQuantities = append(r.reorderQuantities, p.ReorderQuantity)
+		r.names = append(r.names, strings.TrimSpace(p.Name))
+		r.skus = append(r.skus, strings.TrimSpace(p.SKU))
+		r.options = append(r.options, string(options))
+	}
+	return r, nil
+}
+
+// insertProducts inserts products, whose IDs are already taken, in one
+// statement.
+func insertProducts(ctx context.Context, tx *sql.Tx, products []domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	r, err := newProductRows(products)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO products (id, name, sku, category_id, price, quantity, reorder_point, reorder_quantity, options, created_at, updated_at)
+		SELECT v.id, v.name, NULLIF(v.sku, ''), NULLIF(v.category_id, 0), v.price, v.quantity, v.reorder_point, v.reorder_quantity, v.options::jsonb, NOW(), NOW()
+		FROM unnest($1::int[], $2::text[], $3::text[], $4::int[], $5::int[], $6::int[], $7::int[], $8::int[], $9::text[])
+			AS v(id, name, sku, category_id, price, quantity, reorder_point, reorder_quantity, options)
+		ORDER BY v.id
+	`, r.ids, r.names, r.skus, r.categoryIDs, r.prices, r.quantities, r.reorderPoints, r.reorderQuantities, r.options)
+	return err
+}
+
+// updateProducts updates products in one statement, leaving their stock as
+// updateProduct does.
+func updateProducts(ctx context.Context, tx *sql.Tx, products []domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	r, err := newProductRows(products)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE products p
+		SET name = v.name, sku = NULLIF(v.sku, ''), category_id = NULLIF(v.category_id, 0), price = v.price,
+			reorder_point = v.reorder_point, reorder_quantity = v.reorder_quantity, options = v.options::jsonb, updated_at = NOW()
+		FROM unnest($1::int[], $2::text[], $3::text[], $4::int[], $5::int[], $6::int[], $7::int[], $8::text[])
+			AS v(id, name, sku, category_id, price, reorder_point, reorder_quantity, options)
+		WHERE p.id = v.id AND p.tenant_id = current_tenant_id()
+	`, r.ids, r.names, r.skus, r.categoryIDs, r.prices, r.reorderPoints, r.reorderQuantities, r.options)
+	return err
+}
+
+func (r *ProductRepo) AdjustStock(ctx context.Context, changes []domain.StockChange) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := applyStockChanges(ctx, tx, changes, true)
+		return err
+	})
+}
+
+func (r *ProductRepo) CostLayers(ctx context.Context, productID int) ([]domain.CostLayer, error) {
+	if _, err := r.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, product_id, variant_id, quantity, remaining, unit_cost, received_at
+		FROM cost_layers
+		WHERE product_id = $1 AND remaining > 0 AND tenant_id = current_tenant_id()
+		ORDER BY received_at, id
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.CostLayer, 0)
+	for rows.Next() {
+		var l domain.CostLayer
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.VariantID, &l.Quantity, &l.Remaining, &l.UnitCost, &l.ReceivedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *ProductRepo) StockByLocation(ctx context.Context, productID int) ([]domain.StockLevel, error) {
+	if _, err := r.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return r.ListStock(ctx, repository.StockListParams{ProductID: productID, Limit: 200})
+}
+
+func (r *ProductRepo) ListStock(ctx context.Context, lp repository.StockListParams) ([]domain.StockLevel, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT location_id, product_id, variant_id, quantity, updated_at
+		FROM stock_levels
+		WHERE tenant_id = current_tenant_id()
+		  AND ($1 = 0 OR location_id = $1)
+		  AND ($2 = 0 OR product_id = $2)
+		ORDER BY location_id, product_id, variant_id
+		LIMIT $3 OFFSET $4
+	`, lp.LocationID, lp.ProductID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.StockLevel, 0)
+	for rows.Next() {
+		var l domain.StockLevel
+		if err := rows.Scan(
+			&l.LocationID,
+			&l.ProductID,
+			&l.VariantID,
+			&l.Quantity,
+			&l.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// applyStockChanges adjusts location levels inside tx and, when total is set,
+// the variant and product quantities too. Transfers leave totals alone because
+// stock in transit still counts towards them; only then are cost layers
+// updated and stock events recorded, and the cost of each change is returned.
+// Changes of zero are skipped.
+func applyStockChanges(ctx context.Context, tx *sql.Tx, changes []domain.StockChange, total bool) ([]domain.CostBasis, error) {
+	var costs []domain.CostBasis
+	if total {
+		costs = make([]domain.CostBasis, len(changes))
+	}
+	for i, c := range changes {
+		if c.Delta == 0 {
+			continue
+		}
+		if c.LocationID != 0 {
+			var qty int
+			err := tx.QueryRowContext(ctx, `
+				INSERT INTO stock_levels (location_id, product_id, variant_id, quantity, updated_at)
+				VALUES ($1, $2, $3, $4, NOW())
+				ON CONFLICT (location_id, product_id, variant_id)
+				DO UPDATE SET quantity = stock_levels.quantity + EXCLUDED.quantity, updated_at = NOW()
+				RETURNING quantity
+			`, c.LocationID, c.ProductID, c.VariantID, c.Delta).Scan(&qty)
+			if err != nil {
+				return nil, err
+			}
+			if qty < 0 {
+				return nil, fmt.Errorf("%w: insufficient stock for product %d at location %d", domain.ErrConflict, c.ProductID, c.LocationID)
+			}
+		}
+		if !total {
+			continue
+		}
+
+		onHand, remaining := 0, 0
+		if c.VariantID != 0 {
+			var qty int
+			err := tx.QueryRowContext(ctx, `
+				UPDATE product_variants
+				SET quantity = quantity + $1
+				WHERE id = $2 AND product_id = $3 AND tenant_id = current_tenant_id()
+				RETURNING quantity
+			`, c.Delta, c.VariantID, c.ProductID).Scan(&qty)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: variant %d of product %d", domain.ErrNotFound, c.VariantID, c.ProductID)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if c.Delta < 0 && qty < 0 {
+				return nil, fmt.Errorf("%w: insufficient stock for product %d", domain.ErrConflict, c.ProductID)
+			}
+			onHand, remaining = qty-c.Delta, qty
+		}
+
+		var qty int
+		err := tx.QueryRowContext(ctx, `
+			UPDATE products
+			SET quantity = quantity + $1, updated_at = NOW()
+			WHERE id = $2 AND tenant_id = current_tenant_id()
+			  AND ($3 <> 0 OR NOT EXISTS (SELECT 1 FROM product_variants WHERE product_id = $2 AND tenant_id = current_tenant_id()))
+			RETURNING quantity
+		`, c.Delta, c.ProductID, c.VariantID).Scan(&qty)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: product %d does not exist or needs a variant", domain.ErrInvalid, c.ProductID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if c.Delta < 0 && qty < 0 {
+			return nil, fmt.Errorf("%w: insufficient stock for product %d", domain.ErrConflict, c.ProductID)
+		}
+		if c.VariantID == 0 {
+			onHand, remaining = qty-c.Delta, qty
+		}
+		events, err := domain.StockEvents(c, remaining, qty)
+		if err != nil {
+			return nil, err
+		}
+		if err := recordEvents(ctx, tx, events); err != nil {
+			return nil, err
+		}
+
+		cost, err := applyCost(ctx, tx, c, onHand)
+		if err != nil {
+			return nil, err
+		}
+		costs[i] = cost
+	}
+	return costs, nil
+}
+
+// applyCost adds a cost layer for incoming stock or consumes layers for
+// outgoing stock. onHand is the quantity before the change.
+func applyCost(ctx context.Context, tx *sql.Tx, c domain.StockChange, onHand int) (domain.CostBasis, error) {
+	if c.Delta == 0 {
+		return domain.CostBasis{}, nil
+	}
+
+	cost := domain.ProductCost{ProductID: c.ProductID, VariantID: c.VariantID}
+	err := tx.QueryRowContext(ctx, `
+		SELECT average_cost, last_cost
+		FROM product_costs
+		WHERE product_id = $1 AND variant_id = $2 AND tenant_id = current_tenant_id()
+		FOR UPDATE
+	`, c.ProductID, c.VariantID).Scan(&cost.AverageCost, &cost.LastCost)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return domain.CostBasis{}, err
+	}
+
+	if c.Delta > 0 {
+		unitCost := cost.Receive(onHand, c.Delta, c.UnitCost)
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO cost_layers (product_id, variant_id, quantity, remaining, unit_cost, received_at)
+			VALUES ($1, $2, $3, $3, $4, NOW())
+		`, c.ProductID, c.VariantID, c.Delta, unitCost); err != nil {
+			return domain.CostBasis{}, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO product_costs (product_id, variant_id, average_cost, last_cost)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (product_id, variant_id)
+			DO UPDATE SET average_cost = EXCLUDED.average_cost, last_cost = EXCLUDED.last_cost
+		`, c.ProductID, c.VariantID, cost.AverageCost, cost.LastCost); err != nil {
+			return domain.CostBasis{}, err
+		}
+		total := c.Delta * unitCost
+		return domain.CostBasis{FIFO: total, Average: total, Last: total}, nil
+	}
+
+	layers, err := costLayers(ctx, tx, c.ProductID, c.VariantID, true)
+	if err != nil {
+		return domain.CostBasis{}, err
+	}
+	before := make([]int, len(layers))
+	for i, l := range layers {
+		before[i] = l.Remaining
+	}
+	basis := cost.Issue(layers, -c.Delta)
+	for i, l := range layers {
+		if l.Remaining == before[i] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE cost_layers SET remaining = $1 WHERE id = $2 AND tenant_id = current_tenant_id()`, l.Remaining, l.ID); err != nil {
+			return domain.CostBasis{}, err
+		}
+	}
+	return basis, nil
+}
+
+// costLayers loads the layers of a product variant that still have stock,
+// oldest first, optionally locking them for consumption.
+func costLayers(ctx context.Context, q querier, productID, variantID int, forUpdate bool) ([]domain.CostLayer, error) {
+	query := `
+		SELECT id, product_id, variant_id, quantity, remaining, unit_cost, received_at
+		FROM cost_layers
+		WHERE product_id = $1 AND variant_id = $2 AND remaining > 0 AND tenant_id = current_tenant_id()
+		ORDER BY received_at, id`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	rows, err := q.QueryContext(ctx, query, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.CostLayer, 0)
+	for rows.Next() {
+		var l domain.CostLayer
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.VariantID, &l.Quantity, &l.Remaining, &l.UnitCost, &l.ReceivedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,340 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+)
+
+type PurchaseOrderRepo struct {
+	db *sql.DB
+}
+
+func NewPurchaseOrderRepo(db *sql.DB) *PurchaseOrderRepo {
+	return &PurchaseOrderRepo{db: db}
+}
+
+const purchaseOrderColumns = `id, supplier_id, COALESCE(location_id, 0), status, notes, submitted_at, received_at, created_at, updated_at`
+
+func scanPurchaseOrder(row interface{ Scan(...any) error }, po *domain.PurchaseOrder) error {
+	return row.Scan(
+		&po.ID,
+		&po.SupplierID,
+		&po.LocationID,
+		&po.Status,
+		&po.Notes,
+		&po.SubmittedAt,
+		&po.ReceivedAt,
+		&po.CreatedAt,
+		&po.UpdatedAt,
+	)
+}
+
+func (r *PurchaseOrderRepo) Create(ctx context.Context, po domain.PurchaseOrder) (domain.PurchaseOrder, error) {
+	var out domain.PurchaseOrder
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx, `
+			INSERT INTO purchase_orders (supplier_id, location_id, status, notes, created_at, updated_at)
+			VALUES ($1, NULLIF($2, 0), $3, $4, NOW(), NOW())
+			RETURNING `+purchaseOrderColumns,
+			po.SupplierID, po.LocationID, domain.PurchaseOrderDraft, strings.TrimSpace(po.Notes))
+		if err := scanPurchaseOrder(row, &out); err != nil {
+			return err
+		}
+
+		lines, err := insertPurchaseOrderLines(ctx, tx, out.ID, po.Lines)
+		if err != nil {
+			return err
+		}
+		out.Lines = lines
+		return nil
+	})
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	return out, nil
+}
+
+func (r *PurchaseOrderRepo) GetByID(ctx context.Context, id int) (domain.PurchaseOrder, error) {
+	return getPurchaseOrder(ctx, r.db, id, false)
+}
+
+func (r *PurchaseOrderRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.PurchaseOrder, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+purchaseOrderColumns+`
+		FROM purchase_orders
+		WHERE tenant_id = current_tenant_id()
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.PurchaseOrder, 0)
+	index := make(map[int]int)
+	ids := make([]int, 0)
+	for rows.Next() {
+		var po domain.PurchaseOrder
+		if err := scanPurchaseOrder(rows, &po); err != nil {
+			return nil, err
+		}
+		po.Lines = []domain.PurchaseOrderLine{}
+		index[po.ID] = len(items)
+		ids = append(ids, po.ID)
+		items = append(items, po)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	lineRows, err := r.db.QueryContext(ctx, `
+		SELECT purchase_order_id, id, product_id, COALESCE(variant_id, 0), quantity, received_quantity, unit_cost
+		FROM purchase_order_lines
+		WHERE purchase_order_id = ANY($1) AND tenant_id = current_tenant_id()
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer lineRows.Close()
+
+	for lineRows.Next() {
+		var poID int
+		var l domain.PurchaseOrderLine
+		if err := lineRows.Scan(&poID, &l.ID, &l.ProductID, &l.VariantID, &l.Quantity, &l.ReceivedQuantity, &l.UnitCost); err != nil {
+			return nil, err
+		}
+		i := index[poID]
+		items[i].Lines = append(items[i].Lines, l)
+	}
+	if err := lineRows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PurchaseOrderRepo) Update(ctx context.Context, id int, patch domain.PurchaseOrder) (domain.PurchaseOrder, error) {
+	var out domain.PurchaseOrder
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		existing, err := getPurchaseOrder(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if existing.Status != domain.PurchaseOrderDraft {
+			return fmt.Errorf("%w: only draft purchase orders can be edited", domain.ErrConflict)
+		}
+
+		row := tx.QueryRowContext(ctx, `
+			UPDATE purchase_orders
+			SET supplier_id = $1, location_id = NULLIF($2, 0), notes = $3, updated_at = NOW()
+			WHERE id = $4 AND tenant_id = current_tenant_id()
+			RETURNING `+purchaseOrderColumns,
+			patch.SupplierID, patch.LocationID, strings.TrimSpace(patch.Notes), id)
+		if err := scanPurchaseOrder(row, &out); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM purchase_order_lines WHERE purchase_order_id = $1 AND tenant_id = current_tenant_id()`, id); err != nil {
+			return err
+		}
+		lines, err := insertPurchaseOrderLines(ctx, tx, id, patch.Lines)
+		if err != nil {
+			return err
+		}
+		out.Lines = lines
+		return nil
+	})
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	return out, nil
+}
+
+func (r *PurchaseOrderRepo) Transition(ctx context.Context, id int, next domain.PurchaseOrderStatus) (domain.PurchaseOrder, error) {
+	var out domain.PurchaseOrder
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		po, err := getPurchaseOrder(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if err := po.Transition(next, time.Now().UTC()); err != nil {
+			return err
+		}
+		if err := savePurchaseOrderStatus(ctx, tx, &po); err != nil {
+			return err
+		}
+		out = po
+		return nil
+	})
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	return out, nil
+}
+
+func (r *PurchaseOrderRepo) Receive(ctx context.Context, id int, lines []domain.ReceiveLine) (domain.PurchaseOrder, error) {
+	var out domain.PurchaseOrder
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		po, err := getPurchaseOrder(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		received, err := po.Receive(lines, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		for _, l := range po.Lines {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE purchase_order_lines
+				SET received_quantity = $1
+				WHERE id = $2 AND tenant_id = current_tenant_id()
+			`, l.ReceivedQuantity, l.ID); err != nil {
+				return err
+			}
+		}
+
+		if _, err := applyStockChanges(ctx, tx, received, true); err != nil {
+			return err
+		}
+
+		if err := savePurchaseOrderStatus(ctx, tx, &po); err != nil {
+			return err
+		}
+		out = po
+		return nil
+	})
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	return out, nil
+}
+
+func (r *PurchaseOrderRepo) Purchasing(ctx context.Context, productIDs []int) (map[int]domain.ProductPurchasing, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH last_line AS (
+			SELECT DISTINCT ON (l.product_id) l.product_id, po.supplier_id, l.unit_cost
+			FROM purchase_order_lines l
+			JOIN purchase_orders po ON po.id = l.purchase_order_id AND po.tenant_id = current_tenant_id()
+			WHERE l.product_id = ANY($1) AND po.status <> 'cancelled' AND l.tenant_id = current_tenant_id()
+			ORDER BY l.product_id, po.id DESC, l.id DESC
+		), on_order AS (
+			SELECT l.product_id, SUM(l.quantity - l.received_quantity) AS quantity
+			FROM purchase_order_lines l
+			JOIN purchase_orders po ON po.id = l.purchase_order_id AND po.tenant_id = current_tenant_id()
+			WHERE l.product_id = ANY($1) AND po.status IN ('submitted', 'partially_received') AND l.tenant_id = current_tenant_id()
+			GROUP BY l.product_id
+		)
+		SELECT ll.product_id, ll.supplier_id, ll.unit_cost, COALESCE(oo.quantity, 0)
+		FROM last_line ll
+		LEFT JOIN on_order oo ON oo.product_id = ll.product_id
+	`, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[int]domain.ProductPurchasing)
+	for rows.Next() {
+		var info domain.ProductPurchasing
+		if err := rows.Scan(&info.ProductID, &info.SupplierID, &info.UnitCost, &info.OnOrder); err != nil {
+			return nil, err
+		}
+		out[info.ProductID] = info
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func getPurchaseOrder(ctx context.Context, q querier, id int, forUpdate bool) (domain.PurchaseOrder, error) {
+	query := `SELECT ` + purchaseOrderColumns + ` FROM purchase_orders WHERE id = $1 AND tenant_id = current_tenant_id()`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var out domain.PurchaseOrder
+	if err := scanPurchaseOrder(q.QueryRowContext(ctx, query, id), &out); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.PurchaseOrder{}, domain.ErrNotFound
+		}
+		return domain.PurchaseOrder{}, err
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, product_id, COALESCE(variant_id, 0), quantity, received_quantity, unit_cost
+		FROM purchase_order_lines
+		WHERE purchase_order_id = $1 AND tenant_id = current_tenant_id()
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	defer rows.Close()
+
+	out.Lines = make([]domain.PurchaseOrderLine, 0)
+	for rows.Next() {
+		var l domain.PurchaseOrderLine
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.VariantID, &l.Quantity, &l.ReceivedQuantity, &l.UnitCost); err != nil {
+			return domain.PurchaseOrder{}, err
+		}
+		out.Lines = append(out.Lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	return out, nil
+}
+
+func insertPurchaseOrderLines(ctx context.Context, tx *sql.Tx, poID int, lines []domain.PurchaseOrderLine) ([]domain.PurchaseOrderLine, error) {
+	out := make([]domain.PurchaseOrderLine, 0, len(lines))
+	for _, l := range lines {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO purchase_order_lines (purchase_order_id, product_id, variant_id, quantity, received_quantity, unit_cost)
+			VALUES ($1, $2, NULLIF($3, 0), $4, 0, $5)
+			RETURNING id, product_id, COALESCE(variant_id, 0), quantity, received_quantity, unit_cost
+		`, poID, l.ProductID, l.VariantID, l.Quantity, l.UnitCost).Scan(
+			&l.ID,
+			&l.ProductID,
+			&l.VariantID,
+			&l.Quantity,
+			&l.ReceivedQuantity,
+			&l.UnitCost,
+		)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+func savePurchaseOrderStatus(ctx context.Context, tx *sql.Tx, po *domain.PurchaseOrder) error {
+	return tx.QueryRowContext(ctx, `
+		UPDATE purchase_orders
+		SET status = $1, submitted_at = $2, received_at = $3, updated_at = NOW()
+		WHERE id = $4 AND tenant_id = current_tenant_id()
+		RETURNING updated_at
+	`, po.Status, po.SubmittedAt, po.ReceivedAt, po.ID).Scan(&po.UpdatedAt)
+}
@@ -0,0 +1,248 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"pos-api/internal/domain"
+)
+
+type ReportRepo struct {
+	db *sql.DB
+}
+
+func NewReportRepo(db *sql.DB) *ReportRepo {
+	return &ReportRepo{db: db}
+}
+
+func (r *ReportRepo) SalesByPeriod(ctx context.Context, p domain.SalesReportParams) ([]domain.SalesPeriod, error) {
+	// Truncating the local wall-clock time and converting back gives period
+	// boundaries at local midnight.
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT date_trunc($3, o.created_at AT TIME ZONE $4) AT TIME ZONE $4 AS start,
+		       COUNT(*), COALESCE(SUM(i.quantity), 0),
+		       SUM(o.subtotal), SUM(o.tax), SUM(o.total)
+		FROM orders o
+		LEFT JOIN LATERAL (
+			SELECT SUM(quantity) AS quantity FROM order_items WHERE order_id = o.id AND tenant_id = current_tenant_id()
+		) i ON TRUE
+		WHERE o.created_at >= $1 AND o.created_at < $2 AND o.tenant_id = current_tenant_id()
+		GROUP BY 1
+		ORDER BY 1
+	`, p.From, p.To, string(p.GroupBy), p.Location.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.SalesPeriod, 0)
+	for rows.Next() {
+		var s domain.SalesPeriod
+		if err := rows.Scan(&s.Start, &s.OrderCount, &s.ItemsSold, &s.Subtotal, &s.Tax, &s.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *ReportRepo) TopProducts(ctx context.Context, p domain.SalesReportParams, byQuantity bool) ([]domain.ProductSales, error) {
+	order := `revenue DESC, quantity DESC`
+	if byQuantity {
+		order = `quantity DESC, revenue DESC`
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT i.product_id, COALESCE(p.name, MAX(i.name)),
+		       SUM(i.quantity) AS quantity, SUM(i.line_total) AS revenue
+		FROM order_items i
+		JOIN orders o ON o.id = i.order_id AND i.tenant_id = current_tenant_id()
+		LEFT JOIN products p ON p.id = i.product_id AND p.tenant_id = current_tenant_id()
+		WHERE o.created_at >= $1 AND o.created_at < $2 AND o.tenant_id = current_tenant_id()
+		GROUP BY i.product_id, p.name
+		ORDER BY `+order+`, i.product_id
+		LIMIT $3
+	`, p.From, p.To, p.Top)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.ProductSales, 0)
+	for rows.Next() {
+		var s domain.ProductSales
+		if err := rows.Scan(&s.ProductID, &s.Name, &s.Quantity, &s.Revenue); err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *ReportRepo) SalesByCategory(ctx context.Context, p domain.SalesReportParams) ([]domain.CategorySales, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT COALESCE(c.id, 0), COALESCE(c.name, ''),
+		       SUM(i.quantity) AS quantity, SUM(i.line_total) AS revenue
+		FROM order_items i
+		JOIN orders o ON o.id = i.order_id AND i.tenant_id = current_tenant_id()
+		LEFT JOIN products p ON p.id = i.product_id AND p.tenant_id = current_tenant_id()
+		LEFT JOIN categories c ON c.id = p.category_id AND c.tenant_id = current_tenant_id()
+		WHERE o.created_at >= $1 AND o.created_at < $2 AND o.tenant_id = current_tenant_id()
+		GROUP BY c.id, c.name
+		ORDER BY revenue DESC, 1
+	`, p.From, p.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.CategorySales, 0)
+	for rows.Next() {
+		var s domain.CategorySales
+		if err := rows.Scan(&s.CategoryID, &s.Name, &s.Quantity, &s.Revenue); err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *ReportRepo) InventoryValuation(ctx context.Context) ([]domain.ProductValuation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.id, 0, p.name, COALESCE(p.sku, ''), COALESCE(p.category_id, 0), p.quantity,
+		       COALESCE(c.average_cost, 0), COALESCE(c.last_cost, 0)
+		FROM products p
+		LEFT JOIN product_costs c ON c.product_id = p.id AND c.variant_id = 0 AND c.tenant_id = current_tenant_id()
+		WHERE p.tenant_id = current_tenant_id()
+			AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.tenant_id = current_tenant_id())
+		UNION ALL
+		SELECT p.id, v.id, p.name, COALESCE(v.sku, ''), COALESCE(p.category_id, 0), v.quantity,
+		       COALESCE(c.average_cost, 0), COALESCE(c.last_cost, 0)
+		FROM product_variants v
+		JOIN products p ON p.id = v.product_id AND p.tenant_id = current_tenant_id()
+		LEFT JOIN product_costs c ON c.product_id = v.product_id AND c.variant_id = v.id AND c.tenant_id = current_tenant_id()
+		WHERE v.tenant_id = current_tenant_id()
+		ORDER BY 1, 2
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type costKey struct{ productID, variantID int }
+	items := make([]domain.ProductValuation, 0)
+	costs := make([]domain.ProductCost, 0)
+	index := make(map[costKey]int)
+	for rows.Next() {
+		var v domain.ProductValuation
+		var c domain.ProductCost
+		if err := rows.Scan(&v.ProductID, &v.VariantID, &v.Name, &v.SKU, &v.CategoryID, &v.Quantity, &c.AverageCost, &c.LastCost); err != nil {
+			return nil, err
+		}
+		index[costKey{v.ProductID, v.VariantID}] = len(items)
+		items = append(items, v)
+		costs = append(costs, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	layerRows, err := r.db.QueryContext(ctx, `
+		SELECT id, product_id, variant_id, quantity, remaining, unit_cost, received_at
+		FROM cost_layers
+		WHERE remaining > 0 AND tenant_id = current_tenant_id()
+		ORDER BY received_at, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer layerRows.Close()
+
+	layers := make(map[int][]domain.CostLayer)
+	for layerRows.Next() {
+		var l domain.CostLayer
+		if err := layerRows.Scan(&l.ID, &l.ProductID, &l.VariantID, &l.Quantity, &l.Remaining, &l.UnitCost, &l.ReceivedAt); err != nil {
+			return nil, err
+		}
+		if i, ok := index[costKey{l.ProductID, l.VariantID}]; ok {
+			layers[i] = append(layers[i], l)
+		}
+	}
+	if err := layerRows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		items[i].Value = costs[i].Value(layers[i], items[i].Quantity)
+	}
+	return items, nil
+}
+
+func (r *ReportRepo) CostOfSalesByPeriod(ctx context.Context, p domain.SalesReportParams) ([]domain.SalesCost, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT date_trunc($3, o.created_at AT TIME ZONE $4) AT TIME ZONE $4 AS start,
+		       SUM(i.line_total), SUM(i.cost_fifo), SUM(i.cost_average), SUM(i.cost_last)
+		FROM order_items i
+		JOIN orders o ON o.id = i.order_id AND i.tenant_id = current_tenant_id()
+		WHERE o.created_at >= $1 AND o.created_at < $2 AND o.tenant_id = current_tenant_id()
+		GROUP BY 1
+		ORDER BY 1
+	`, p.From, p.To, string(p.GroupBy), p.Location.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.SalesCost, 0)
+	for rows.Next() {
+		var s domain.SalesCost
+		if err := rows.Scan(&s.Start, &s.Revenue, &s.Cost.FIFO, &s.Cost.Average, &s.Cost.Last); err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *ReportRepo) CostOfSalesByCategory(ctx context.Context, p domain.SalesReportParams) ([]domain.SalesCost, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT COALESCE(c.id, 0), COALESCE(c.name, ''),
+		       SUM(i.line_total) AS revenue, SUM(i.cost_fifo), SUM(i.cost_average), SUM(i.cost_last)
+		FROM order_items i
+		JOIN orders o ON o.id = i.order_id AND i.tenant_id = current_tenant_id()
+		LEFT JOIN products p ON p.id = i.product_id AND p.tenant_id = current_tenant_id()
+		LEFT JOIN categories c ON c.id = p.category_id AND c.tenant_id = current_tenant_id()
+		WHERE o.created_at >= $1 AND o.created_at < $2 AND o.tenant_id = current_tenant_id()
+		GROUP BY c.id, c.name
+		ORDER BY revenue DESC, 1
+	`, p.From, p.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.SalesCost, 0)
+	for rows.Next() {
+		var s domain.SalesCost
+		if err := rows.Scan(&s.CategoryID, &s.Name, &s.Revenue, &s.Cost.FIFO, &s.Cost.Average, &s.Cost.Last); err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
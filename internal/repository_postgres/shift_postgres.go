@@ -0,0 +1,209 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+)
+
+type ShiftRepo struct {
+	db *sql.DB
+}
+
+func NewShiftRepo(db *sql.DB) *ShiftRepo {
+	return &ShiftRepo{db: db}
+}
+
+const shiftColumns = `id, register_id, user_id, status, opening_float, expected_cash, counted_cash, variance, opened_at, closed_at`
+
+func scanShift(row interface{ Scan(...any) error }, s *domain.Shift) error {
+	return row.Scan(
+		&s.ID,
+		&s.RegisterID,
+		&s.UserID,
+		&s.Status,
+		&s.OpeningFloat,
+		&s.ExpectedCash,
+		&s.CountedCash,
+		&s.Variance,
+		&s.OpenedAt,
+		&s.ClosedAt,
+	)
+}
+
+func (r *ShiftRepo) Open(ctx context.Context, s domain.Shift) (domain.Shift, error) {
+	var out domain.Shift
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO shifts (register_id, user_id, status, opening_float, opened_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING `+shiftColumns,
+		strings.TrimSpace(s.RegisterID), strings.TrimSpace(s.UserID), domain.ShiftOpen, s.OpeningFloat)
+	if err := scanShift(row, &out); err != nil {
+		if errors.Is(uniqueViolation(err), domain.ErrConflict) {
+			return domain.Shift{}, fmt.Errorf("%w: register %q already has an open shift", domain.ErrConflict, s.RegisterID)
+		}
+		return domain.Shift{}, err
+	}
+	return out, nil
+}
+
+func (r *ShiftRepo) GetByID(ctx context.Context, id int) (domain.Shift, error) {
+	return getShift(ctx, r.db, id, "")
+}
+
+func (r *ShiftRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Shift, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+shiftColumns+`
+		FROM shifts
+		WHERE tenant_id = current_tenant_id()
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.Shift, 0)
+	for rows.Next() {
+		var s domain.Shift
+		if err := scanShift(rows, &s); err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *ShiftRepo) AddCashMovement(ctx context.Context, m domain.CashMovement) (domain.CashMovement, error) {
+	var out domain.CashMovement
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		s, err := getShift(ctx, tx, m.ShiftID, "FOR SHARE")
+		if err != nil {
+			return err
+		}
+		if s.Status != domain.ShiftOpen {
+			return fmt.Errorf("%w: shift %d is closed", domain.ErrConflict, s.ID)
+		}
+
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO cash_movements (shift_id, kind, amount, reason, created_at)
+			VALUES ($1, $2, $3, $4, NOW())
+			RETURNING id, shift_id, kind, amount, reason, created_at
+		`, m.ShiftID, m.Kind, m.Amount, strings.TrimSpace(m.Reason)).Scan(
+			&out.ID,
+			&out.ShiftID,
+			&out.Kind,
+			&out.Amount,
+			&out.Reason,
+			&out.CreatedAt,
+		)
+	})
+	if err != nil {
+		return domain.CashMovement{}, err
+	}
+	return out, nil
+}
+
+func (r *ShiftRepo) CashMovements(ctx context.Context, shiftID int) ([]domain.CashMovement, error) {
+	if _, err := getShift(ctx, r.db, shiftID, ""); err != nil {
+		return nil, err
+	}
+	return cashMovements(ctx, r.db, shiftID)
+}
+
+func (r *ShiftRepo) Close(ctx context.Context, id int, countedCash int) (domain.Shift, error) {
+	var out domain.Shift
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		s, err := getShift(ctx, tx, id, "FOR UPDATE")
+		if err != nil {
+			return err
+		}
+		if s.Status != domain.ShiftOpen {
+			return fmt.Errorf("%w: shift %d is already closed", domain.ErrConflict, id)
+		}
+
+		sales, err := salesByShift(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		movements, err := cashMovements(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		expected, _, _ := domain.ExpectedCash(s.OpeningFloat, sales, movements)
+
+		row := tx.QueryRowContext(ctx, `
+			UPDATE shifts
+			SET status = $1, expected_cash = $2, counted_cash = $3, variance = $4, closed_at = NOW()
+			WHERE id = $5 AND tenant_id = current_tenant_id()
+			RETURNING `+shiftColumns,
+			domain.ShiftClosed, expected, countedCash, countedCash-expected, id)
+		return scanShift(row, &out)
+	})
+	if err != nil {
+		return domain.Shift{}, err
+	}
+	return out, nil
+}
+
+// getShift loads a shift, optionally taking a row lock such as FOR SHARE or
+// FOR UPDATE.
+func getShift(ctx context.Context, q querier, id int, lock string) (domain.Shift, error) {
+	query := `SELECT ` + shiftColumns + ` FROM shifts WHERE id = $1 AND tenant_id = current_tenant_id()`
+	if lock != "" {
+		query += ` ` + lock
+	}
+
+	var out domain.Shift
+	if err := scanShift(q.QueryRowContext(ctx, query, id), &out); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Shift{}, domain.ErrNotFound
+		}
+		return domain.Shift{}, err
+	}
+	return out, nil
+}
+
+func cashMovements(ctx context.Context, q querier, shiftID int) ([]domain.CashMovement, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, shift_id, kind, amount, reason, created_at
+		FROM cash_movements
+		WHERE shift_id = $1 AND tenant_id = current_tenant_id()
+		ORDER BY id
+	`, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.CashMovement, 0)
+	for rows.Next() {
+		var m domain.CashMovement
+		if err := rows.Scan(&m.ID, &m.ShiftID, &m.Kind, &m.Amount, &m.Reason, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
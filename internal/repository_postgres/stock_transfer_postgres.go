@@ -0,0 +1,212 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+)
+
+type StockTransferRepo struct {
+	db *sql.DB
+}
+
+func NewStockTransferRepo(db *sql.DB) *StockTransferRepo {
+	return &StockTransferRepo{db: db}
+}
+
+const stockTransferColumns = `id, from_location_id, to_location_id, status, notes, received_at, created_at, updated_at`
+
+func scanStockTransfer(row interface{ Scan(...any) error }, t *domain.StockTransfer) error {
+	return row.Scan(
+		&t.ID,
+		&t.FromLocationID,
+		&t.ToLocationID,
+		&t.Status,
+		&t.Notes,
+		&t.ReceivedAt,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+}
+
+func (r *StockTransferRepo) Create(ctx context.Context, t domain.StockTransfer) (domain.StockTransfer, error) {
+	var out domain.StockTransfer
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := applyStockChanges(ctx, tx, t.Ship(), false); err != nil {
+			return err
+		}
+
+		row := tx.QueryRowContext(ctx, `
+			INSERT INTO stock_transfers (from_location_id, to_location_id, status, notes, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, NOW(), NOW())
+			RETURNING `+stockTransferColumns,
+			t.FromLocationID, t.ToLocationID, domain.StockTransferInTransit, strings.TrimSpace(t.Notes))
+		if err := scanStockTransfer(row, &out); err != nil {
+			return err
+		}
+
+		out.Lines = make([]domain.StockTransferLine, 0, len(t.Lines))
+		for _, l := range t.Lines {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO stock_transfer_lines (stock_transfer_id, product_id, variant_id, quantity)
+				VALUES ($1, $2, $3, $4)
+			`, out.ID, l.ProductID, l.VariantID, l.Quantity); err != nil {
+				return err
+			}
+			out.Lines = append(out.Lines, l)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.StockTransfer{}, err
+	}
+	return out, nil
+}
+
+func (r *StockTransferRepo) GetByID(ctx context.Context, id int) (domain.StockTransfer, error) {
+	return getStockTransfer(ctx, r.db, id, false)
+}
+
+func (r *StockTransferRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.StockTransfer, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+stockTransferColumns+`
+		FROM stock_transfers
+		WHERE tenant_id = current_tenant_id()
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.StockTransfer, 0)
+	index := make(map[int]int)
+	ids := make([]int, 0)
+	for rows.Next() {
+		var t domain.StockTransfer
+		if err := scanStockTransfer(rows, &t); err != nil {
+			return nil, err
+		}
+		t.Lines = []domain.StockTransferLine{}
+		index[t.ID] = len(items)
+		ids = append(ids, t.ID)
+		items = append(items, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	lineRows, err := r.db.QueryContext(ctx, `
+		SELECT stock_transfer_id, product_id, variant_id, quantity
+		FROM stock_transfer_lines
+		WHERE stock_transfer_id = ANY($1) AND tenant_id = current_tenant_id()
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer lineRows.Close()
+
+	for lineRows.Next() {
+		var transferID int
+		var l domain.StockTransferLine
+		if err := lineRows.Scan(&transferID, &l.ProductID, &l.VariantID, &l.Quantity); err != nil {
+			return nil, err
+		}
+		i := index[transferID]
+		items[i].Lines = append(items[i].Lines, l)
+	}
+	if err := lineRows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *StockTransferRepo) Complete(ctx context.Context, id int, next domain.StockTransferStatus) (domain.StockTransfer, error) {
+	var out domain.StockTransfer
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		t, err := getStockTransfer(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		changes, err := t.Complete(next, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if _, err := applyStockChanges(ctx, tx, changes, false); err != nil {
+			return err
+		}
+
+		if err := tx.QueryRowContext(ctx, `
+			UPDATE stock_transfers
+			SET status = $1, received_at = $2, updated_at = NOW()
+			WHERE id = $3 AND tenant_id = current_tenant_id()
+			RETURNING updated_at
+		`, t.Status, t.ReceivedAt, t.ID).Scan(&t.UpdatedAt); err != nil {
+			return err
+		}
+		out = t
+		return nil
+	})
+	if err != nil {
+		return domain.StockTransfer{}, err
+	}
+	return out, nil
+}
+
+func getStockTransfer(ctx context.Context, q querier, id int, forUpdate bool) (domain.StockTransfer, error) {
+	query := `SELECT ` + stockTransferColumns + ` FROM stock_transfers WHERE id = $1 AND tenant_id = current_tenant_id()`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var out domain.StockTransfer
+	if err := scanStockTransfer(q.QueryRowContext(ctx, query, id), &out); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.StockTransfer{}, domain.ErrNotFound
+		}
+		return domain.StockTransfer{}, err
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT product_id, variant_id, quantity
+		FROM stock_transfer_lines
+		WHERE stock_transfer_id = $1 AND tenant_id = current_tenant_id()
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return domain.StockTransfer{}, err
+	}
+	defer rows.Close()
+
+	out.Lines = make([]domain.StockTransferLine, 0)
+	for rows.Next() {
+		var l domain.StockTransferLine
+		if err := rows.Scan(&l.ProductID, &l.VariantID, &l.Quantity); err != nil {
+			return domain.StockTransfer{}, err
+		}
+		out.Lines = append(out.Lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.StockTransfer{}, err
+	}
+	return out, nil
+}
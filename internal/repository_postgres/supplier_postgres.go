@@ -0,0 +1,169 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+)
+
+type SupplierRepo struct {
+	db *sql.DB
+}
+
+func NewSupplierRepo(db *sql.DB) *SupplierRepo {
+	return &SupplierRepo{db: db}
+}
+
+func (r *SupplierRepo) Create(ctx context.Context, s domain.Supplier) (domain.Supplier, error) {
+	s = trimSupplier(s)
+
+	var out domain.Supplier
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO suppliers (name, contact_name, phone, email, address, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, name, contact_name, phone, email, address, created_at, updated_at
+	`, s.Name, s.ContactName, s.Phone, s.Email, s.Address).Scan(
+		&out.ID,
+		&out.Name,
+		&out.ContactName,
+		&out.Phone,
+		&out.Email,
+		&out.Address,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+	)
+	if err != nil {
+		return domain.Supplier{}, err
+	}
+	return out, nil
+}
+
+func (r *SupplierRepo) GetByID(ctx context.Context, id int) (domain.Supplier, error) {
+	var out domain.Supplier
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, contact_name, phone, email, address, created_at, updated_at
+		FROM suppliers
+		WHERE id = $1 AND tenant_id = current_tenant_id()
+	`, id).Scan(
+		&out.ID,
+		&out.Name,
+		&out.ContactName,
+		&out.Phone,
+		&out.Email,
+		&out.Address,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Supplier{}, domain.ErrNotFound
+		}
+		return domain.Supplier{}, err
+	}
+	return out, nil
+}
+
+func (r *SupplierRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Supplier, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, contact_name, phone, email, address, created_at, updated_at
+		FROM suppliers
+		WHERE tenant_id = current_tenant_id()
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.Supplier, 0)
+	for rows.Next() {
+		var s domain.Supplier
+		if err := rows.Scan(
+			&s.ID,
+			&s.Name,
+			&s.ContactName,
+			&s.Phone,
+			&s.Email,
+			&s.Address,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *SupplierRepo) Update(ctx context.Context, id int, patch domain.Supplier) (domain.Supplier, error) {
+	patch = trimSupplier(patch)
+
+	var out domain.Supplier
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE suppliers
+		SET name = $1, contact_name = $2, phone = $3, email = $4, address = $5, updated_at = NOW()
+		WHERE id = $6 AND tenant_id = current_tenant_id()
+		RETURNING id, name, contact_name, phone, email, address, created_at, updated_at
+	`, patch.Name, patch.ContactName, patch.Phone, patch.Email, patch.Address, id).Scan(
+		&out.ID,
+		&out.Name,
+		&out.ContactName,
+		&out.Phone,
+		&out.Email,
+		&out.Address,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Supplier{}, domain.ErrNotFound
+		}
+		return domain.Supplier{}, err
+	}
+	return out, nil
+}
+
+func (r *SupplierRepo) Delete(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM suppliers
+		WHERE id = $1 AND tenant_id = current_tenant_id()
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func trimSupplier(s domain.Supplier) domain.Supplier {
+	s.Name = strings.TrimSpace(s.Name)
+	s.ContactName = strings.TrimSpace(s.ContactName)
+	s.Phone = strings.TrimSpace(s.Phone)
+	s.Email = strings.TrimSpace(s.Email)
+	s.Address = strings.TrimSpace(s.Address)
+	return s
+}
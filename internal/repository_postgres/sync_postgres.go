@@ -0,0 +1,176 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"pos-api/internal/domain"
+)
+
+type SyncRepo struct {
+	db *sql.DB
+}
+
+func NewSyncRepo(db *sql.DB) *SyncRepo {
+	return &SyncRepo{db: db}
+}
+
+func (r *SyncRepo) Changes(ctx context.Context, since, limit int) (domain.CatalogChanges, error) {
+	// One snapshot for the whole read keeps the rows loaded as they were
+	// listed. Rows changed again since are picked up by the next pull.
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return domain.CatalogChanges{}, err
+	}
+	defer tx.Rollback()
+
+	watermark, err := syncWatermark(ctx, tx)
+	if err != nil {
+		return domain.CatalogChanges{}, err
+	}
+
+	out := domain.CatalogChanges{
+		Since:      since,
+		Watermark:  max(watermark, since),
+		Products:   []domain.Product{},
+		Categories: []domain.Category{},
+		Deleted:    []domain.CatalogTombstone{},
+	}
+	if watermark <= since {
+		return out, tx.Commit()
+	}
+
+	changes, err := listChanges(ctx, tx, `change_xid >= $1::bigint::text::xid8 AND change_xid < $2::bigint::text::xid8`, since, watermark, limit+1)
+	if err != nil {
+		return domain.CatalogChanges{}, err
+	}
+	// A pull ends on a transaction boundary so the next one can start
+	// after it, which means finishing the transaction it stopped in.
+	if len(changes) > limit {
+		out.HasMore = true
+		last, next := changes[limit-1], changes[limit]
+		changes = changes[:limit]
+		if next.xid == last.xid {
+			rest, err := listChanges(ctx, tx, `change_xid = $1::bigint::text::xid8 AND change_seq > $2`, last.xid, last.ChangeSeq, nil)
+			if err != nil {
+				return domain.CatalogChanges{}, err
+			}
+			changes = append(changes, rest...)
+		}
+		out.Watermark = last.xid + 1
+	}
+
+	var productIDs, categoryIDs []int
+	for _, c := range changes {
+		switch {
+		case c.deleted:
+			out.Deleted = append(out.Deleted, c.CatalogTombstone)
+		case c.EntityType == domain.AuditProduct:
+			productIDs = append(productIDs, c.EntityID)
+		default:
+			categoryIDs = append(categoryIDs, c.EntityID)
+		}
+	}
+
+	if len(productIDs) > 0 {
+		if out.Products, err = changedProducts(ctx, tx, productIDs); err != nil {
+			return domain.CatalogChanges{}, err
+		}
+	}
+	if len(categoryIDs) > 0 {
+		if out.Categories, err = changedCategories(ctx, tx, categoryIDs); err != nil {
+			return domain.CatalogChanges{}, err
+		}
+	}
+	return out, tx.Commit()
+}
+
+// syncWatermark returns how far a pull on tx's snapshot can go: the oldest
+// transaction still running when it was taken. Every transaction below it
+// has ended, so its changes are all visible and no change can commit below
+// it later, without waiting on writers. A transaction left open holds the
+// watermark back until it ends.
+func syncWatermark(ctx context.Context, tx *sql.Tx) (int, error) {
+	var xid int
+	err := tx.QueryRowContext(ctx, `SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint`).Scan(&xid)
+	return xid, err
+}
+
+// catalogChange is a listed change and the transaction that made it.
+type catalogChange struct {
+	domain.CatalogTombstone
+	xid     int
+	deleted bool
+}
+
+// listChanges lists the product, category and tombstone changes matching
+// where, by transaction and then change number; a nil limit lists them all.
+func listChanges(ctx context.Context, tx *sql.Tx, where string, a, b, limit any) ([]catalogChange, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT change_xid::text::bigint, change_seq, 'product', id, NULL::timestamptz FROM products WHERE `+where+` AND tenant_id = current_tenant_id()
+		UNION ALL
+		SELECT change_xid::text::bigint, change_seq, 'category', id, NULL FROM categories WHERE `+where+` AND tenant_id = current_tenant_id()
+		UNION ALL
+		SELECT change_xid::text::bigint, change_seq, entity_type, entity_id, deleted_at FROM catalog_tombstones WHERE `+where+` AND tenant_id = current_tenant_id()
+		ORDER BY 1, 2
+		LIMIT $3
+	`, a, b, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]catalogChange, 0)
+	for rows.Next() {
+		var c catalogChange
+		var deletedAt sql.NullTime
+		if err := rows.Scan(&c.xid, &c.ChangeSeq, &c.EntityType, &c.EntityID, &deletedAt); err != nil {
+			return nil, err
+		}
+		c.deleted = deletedAt.Valid
+		c.DeletedAt = deletedAt.Time
+		items = append(items, c)
+	}
+	return items, rows.Err()
+}
+
+func changedProducts(ctx context.Context, q querier, ids []int) ([]domain.Product, error) {
+	rows, err := q.QueryContext(ctx, productSelect+` WHERE p.id = ANY($1) AND p.tenant_id = current_tenant_id() ORDER BY p.change_seq`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.Product, 0, len(ids))
+	for rows.Next() {
+		var p domain.Product
+		if err := scanProduct(rows, &p); err != nil {
+			return nil, err
+		}
+		items = append(items, p)
+	}
+	return items, rows.Err()
+}
+
+func changedCategories(ctx context.Context, q querier, ids []int) ([]domain.Category, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT `+categoryColumns+`
+		FROM categories
+		WHERE id = ANY($1) AND tenant_id = current_tenant_id()
+		ORDER BY change_seq
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.Category, 0, len(ids))
+	for rows.Next() {
+		var c domain.Category
+		if err := scanCategory(rows, &c); err != nil {
+			return nil, err
+		}
+		items = append(items, c)
+	}
+	return items, rows.Err()
+}
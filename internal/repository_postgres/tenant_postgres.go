@@ -0,0 +1,179 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+)
+
+// TenantRepo keeps the tenants table, which row-level security leaves
+// visible to every tenant.
+type TenantRepo struct {
+	db *sql.DB
+}
+
+func NewTenantRepo(db *sql.DB) *TenantRepo {
+	return &TenantRepo{db: db}
+}
+
+const tenantColumns = `id, slug, name, suspended, tax_rate, timezone, valuation_method,
+	store_name, store_address, store_phone, receipt_footer, created_at, updated_at`
+
+func scanTenant(row interface{ Scan(...any) error }, t *domain.Tenant) error {
+	var taxRate sql.NullFloat64
+	err := row.Scan(
+		&t.ID,
+		&t.Slug,
+		&t.Name,
+		&t.Suspended,
+		&taxRate,
+		&t.Settings.Timezone,
+		&t.Settings.ValuationMethod,
+		&t.Settings.StoreName,
+		&t.Settings.StoreAddress,
+		&t.Settings.StorePhone,
+		&t.Settings.ReceiptFooter,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	t.Settings.TaxRate = nil
+	if taxRate.Valid {
+		t.Settings.TaxRate = &taxRate.Float64
+	}
+	return nil
+}
+
+func (r *TenantRepo) Create(ctx context.Context, t domain.Tenant) (domain.Tenant, error) {
+	t = trimTenant(t)
+	s := t.Settings
+
+	var out domain.Tenant
+	err := scanTenant(r.db.QueryRowContext(ctx, `
+		INSERT INTO tenants (slug, name, suspended, tax_rate, timezone, valuation_method,
+			store_name, store_address, store_phone, receipt_footer, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		RETURNING `+tenantColumns,
+		t.Slug, t.Name, t.Suspended, s.TaxRate, s.Timezone, s.ValuationMethod,
+		s.StoreName, s.StoreAddress, s.StorePhone, s.ReceiptFooter,
+	), &out)
+	if err != nil {
+		return domain.Tenant{}, uniqueViolation(err)
+	}
+	return out, nil
+}
+
+func (r *TenantRepo) GetByID(ctx context.Context, id int) (domain.Tenant, error) {
+	return r.getOne(ctx, `SELECT `+tenantColumns+` FROM tenants WHERE id = $1`, id)
+}
+
+func (r *TenantRepo) GetBySlug(ctx context.Context, slug string) (domain.Tenant, error) {
+	return r.getOne(ctx, `SELECT `+tenantColumns+` FROM tenants WHERE slug = $1`, strings.TrimSpace(slug))
+}
+
+func (r *TenantRepo) getOne(ctx context.Context, query string, arg any) (domain.Tenant, error) {
+	var out domain.Tenant
+	if err := scanTenant(r.db.QueryRowContext(ctx, query, arg), &out); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Tenant{}, domain.ErrNotFound
+		}
+		return domain.Tenant{}, err
+	}
+	return out, nil
+}
+
+func (r *TenantRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.Tenant, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+tenantColumns+`
+		FROM tenants
+		ORDER BY id
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.Tenant, 0)
+	for rows.Next() {
+		var t domain.Tenant
+		if err := scanTenant(rows, &t); err != nil {
+			return nil, err
+		}
+		items = append(items, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *TenantRepo) ListActiveIDs(ctx context.Context) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM tenants WHERE NOT suspended ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *TenantRepo) Update(ctx context.Context, id int, patch domain.Tenant) (domain.Tenant, error) {
+	patch = trimTenant(patch)
+	s := patch.Settings
+
+	var out domain.Tenant
+	err := scanTenant(r.db.QueryRowContext(ctx, `
+		UPDATE tenants
+		SET slug = $1, name = $2, suspended = $3, tax_rate = $4, timezone = $5, valuation_method = $6,
+			store_name = $7, store_address = $8, store_phone = $9, receipt_footer = $10, updated_at = NOW()
+		WHERE id = $11
+		RETURNING `+tenantColumns,
+		patch.Slug, patch.Name, patch.Suspended, s.TaxRate, s.Timezone, s.ValuationMethod,
+		s.StoreName, s.StoreAddress, s.StorePhone, s.ReceiptFooter, id,
+	), &out)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Tenant{}, domain.ErrNotFound
+		}
+		return domain.Tenant{}, uniqueViolation(err)
+	}
+	return out, nil
+}
+
+func trimTenant(t domain.Tenant) domain.Tenant {
+	t.Slug = strings.TrimSpace(t.Slug)
+	t.Name = strings.TrimSpace(t.Name)
+	t.Settings.Timezone = strings.TrimSpace(t.Settings.Timezone)
+	t.Settings.StoreName = strings.TrimSpace(t.Settings.StoreName)
+	t.Settings.StoreAddress = strings.TrimSpace(t.Settings.StoreAddress)
+	t.Settings.StorePhone = strings.TrimSpace(t.Settings.StorePhone)
+	t.Settings.ReceiptFooter = strings.TrimSpace(t.Settings.ReceiptFooter)
+	return t
+}
@@ -0,0 +1,74 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// withTx runs fn inside a transaction, committing on success and rolling
+// back on error.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// cursorBatch is how many rows each FETCH from a cursor returns.
+const cursorBatch = 500
+
+// withCursor runs query through a server-side cursor in a read-only
+// transaction and calls fn for each row, fetching cursorBatch rows at a time
+// so that large results are never held in memory at once.
+func withCursor(ctx context.Context, db *sql.DB, query string, args []any, fn func(rows *sql.Rows) error) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DECLARE export_cursor NO SCROLL CURSOR FOR `+query, args...); err != nil {
+		return err
+	}
+	for {
+		n, err := fetchBatch(ctx, tx, fn)
+		if err != nil {
+			return err
+		}
+		if n < cursorBatch {
+			break
+		}
+	}
+	return tx.Commit()
+}
+
+func fetchBatch(ctx context.Context, tx *sql.Tx, fn func(rows *sql.Rows) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`FETCH %d FROM export_cursor`, cursorBatch))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		n++
+		if err := fn(rows); err != nil {
+			return 0, err
+		}
+	}
+	return n, rows.Err()
+}
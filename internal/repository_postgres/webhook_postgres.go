@@ -0,0 +1,282 @@
+package repository_postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+)
+
+type WebhookRepo struct {
+	db *sql.DB
+}
+
+func NewWebhookRepo(db *sql.DB) *WebhookRepo {
+	return &WebhookRepo{db: db}
+}
+
+const webhookColumns = `id, url, event_types, secret, disabled, created_at, updated_at`
+
+func scanWebhook(row interface{ Scan(...any) error }, s *domain.WebhookSubscription) error {
+	var types []byte
+	if err := row.Scan(
+		&s.ID,
+		&s.URL,
+		&types,
+		&s.Secret,
+		&s.Disabled,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	); err != nil {
+		return err
+	}
+	return json.Unmarshal(types, &s.EventTypes)
+}
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	last_status_code, last_error, COALESCE(redelivery_of, 0), created_at, delivered_at`
+
+func scanWebhookDelivery(row interface{ Scan(...any) error }, d *domain.WebhookDelivery) error {
+	var payload []byte
+	if err := row.Scan(
+		&d.ID,
+		&d.SubscriptionID,
+		&d.EventID,
+		&d.EventType,
+		&payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastStatusCode,
+		&d.LastError,
+		&d.RedeliveryOf,
+		&d.CreatedAt,
+		&d.DeliveredAt,
+	); err != nil {
+		return err
+	}
+	d.Payload = payload
+	return nil
+}
+
+func marshalEventTypes(types []domain.EventType) (string, error) {
+	if types == nil {
+		types = []domain.EventType{}
+	}
+	b, err := json.Marshal(types)
+	return string(b), err
+}
+
+func (r *WebhookRepo) Create(ctx context.Context, s domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	types, err := marshalEventTypes(s.EventTypes)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	var out domain.WebhookSubscription
+	err = scanWebhook(r.db.QueryRowContext(ctx, `
+		INSERT INTO webhook_subscriptions (url, event_types, secret, disabled, created_at, updated_at)
+		VALUES ($1, $2::jsonb, $3, $4, NOW(), NOW())
+		RETURNING `+webhookColumns,
+		s.URL, types, s.Secret, s.Disabled), &out)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	return out, nil
+}
+
+func (r *WebhookRepo) GetByID(ctx context.Context, id int) (domain.WebhookSubscription, error) {
+	var out domain.WebhookSubscription
+	err := scanWebhook(r.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhook_subscriptions WHERE id = $1 AND tenant_id = current_tenant_id()`, id), &out)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.WebhookSubscription{}, domain.ErrNotFound
+		}
+		return domain.WebhookSubscription{}, err
+	}
+	return out, nil
+}
+
+func (r *WebhookRepo) List(ctx context.Context, lp repository.ListParams) ([]domain.WebhookSubscription, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhook_subscriptions
+		WHERE tenant_id = current_tenant_id()
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.WebhookSubscription, 0)
+	for rows.Next() {
+		var s domain.WebhookSubscription
+		if err := scanWebhook(rows, &s); err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *WebhookRepo) Update(ctx context.Context, id int, s domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	types, err := marshalEventTypes(s.EventTypes)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	var out domain.WebhookSubscription
+	err = scanWebhook(r.db.QueryRowContext(ctx, `
+		UPDATE webhook_subscriptions
+		SET url = $1, event_types = $2::jsonb, secret = COALESCE(NULLIF($3, ''), secret), disabled = $4, updated_at = NOW()
+		WHERE id = $5 AND tenant_id = current_tenant_id()
+		RETURNING `+webhookColumns,
+		s.URL, types, s.Secret, s.Disabled, id), &out)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.WebhookSubscription{}, domain.ErrNotFound
+		}
+		return domain.WebhookSubscription{}, err
+	}
+	return out, nil
+}
+
+func (r *WebhookRepo) Delete(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1 AND tenant_id = current_tenant_id()`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepo) Enqueue(ctx context.Context, e domain.Event) (int, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, next_attempt_at, created_at)
+		SELECT id, $1, $2, $3::jsonb, NOW(), NOW()
+		FROM webhook_subscriptions
+		WHERE NOT disabled AND tenant_id = current_tenant_id()
+			AND (event_types = '[]' OR event_types @> jsonb_build_array($2::text))
+		ON CONFLICT (subscription_id, event_id) WHERE redelivery_of IS NULL DO NOTHING
+	`, e.ID, e.Type, string(payload))
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *WebhookRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $2
+		WHERE tenant_id = current_tenant_id() AND id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1 AND tenant_id = current_tenant_id()
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+webhookDeliveryColumns,
+		now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	return collectDeliveries(rows)
+}
+
+func collectDeliveries(rows *sql.Rows) ([]domain.WebhookDelivery, error) {
+	defer rows.Close()
+
+	items := make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		if err := scanWebhookDelivery(rows, &d); err != nil {
+			return nil, err
+		}
+		items = append(items, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *WebhookRepo) SaveAttempt(ctx context.Context, d domain.WebhookDelivery) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4, last_error = $5, delivered_at = $6
+		WHERE id = $7 AND tenant_id = current_tenant_id()
+	`, d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt, d.ID)
+	return err
+}
+
+func (r *WebhookRepo) Deliveries(ctx context.Context, lp repository.WebhookDeliveryListParams) ([]domain.WebhookDelivery, error) {
+	limit := lp.Limit
+	offset := lp.Offset
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE subscription_id = $1 AND ($2 = '' OR status = $2) AND tenant_id = current_tenant_id()
+		ORDER BY id DESC
+		LIMIT $3 OFFSET $4
+	`, lp.SubscriptionID, lp.Status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return collectDeliveries(rows)
+}
+
+func (r *WebhookRepo) Redeliver(ctx context.Context, subscriptionID, id int) (domain.WebhookDelivery, error) {
+	var out domain.WebhookDelivery
+	err := scanWebhookDelivery(r.db.QueryRowContext(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, next_attempt_at, redelivery_of, created_at)
+		SELECT subscription_id, event_id, event_type, payload, NOW(), id, NOW()
+		FROM webhook_deliveries
+		WHERE id = $1 AND subscription_id = $2 AND tenant_id = current_tenant_id()
+		RETURNING `+webhookDeliveryColumns,
+		id, subscriptionID), &out)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.WebhookDelivery{}, fmt.Errorf("%w: delivery %d of webhook %d", domain.ErrNotFound, id, subscriptionID)
+		}
+		return domain.WebhookDelivery{}, err
+	}
+	return out, nil
+}
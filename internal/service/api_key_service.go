@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"pos-api/internal/auth"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"pos-api/internal/tenant"
+)
+
+const (
+	// apiKeyPrefixLength is the length of the visible part of a key, pos_
+	// and eight hex digits; the key is that, an underscore and the secret.
+	apiKeyPrefixLength = 12
+	// apiKeyTouchEvery bounds how often a key's last use is written, so a
+	// busy key does not cost a write per request.
+	apiKeyTouchEvery = time.Minute
+)
+
+var errBadAPIKey = fmt.Errorf("%w: invalid API key", domain.ErrUnauthorized)
+
+// APIKeyService issues the API keys programs call the API with and checks
+// the keys requests present.
+type APIKeyService struct {
+	repo repository.APIKeyRepository
+}
+
+func NewAPIKeyService(r repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: r}
+}
+
+// Create issues a key for the tenant of ctx. A request made with a key can
+// only issue keys with the scopes it has itself, and the tenants scopes can
+// only be given by a key that has them.
+func (s *APIKeyService) Create(ctx context.Context, in domain.APIKey) (domain.NewAPIKey, error) {
+	in.TenantID = tenant.From(ctx)
+	if in.TenantID == 0 {
+		return domain.NewAPIKey{}, fmt.Errorf("%w: API keys belong to a tenant; name one", domain.ErrInvalid)
+	}
+	in, err := validateAPIKey(in)
+	if err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	caller, ok := auth.From(ctx)
+	for _, scope := range in.Scopes {
+		if strings.HasPrefix(scope, "tenants:") {
+			if err := auth.RequireKey(ctx, scope); err != nil {
+				return domain.NewAPIKey{}, err
+			}
+		}
+		if ok && !caller.Allows(scope) {
+			return domain.NewAPIKey{}, fmt.Errorf("%w: a key can only be given scopes the API key creating it has, and %s is not one", domain.ErrForbidden, scope)
+		}
+	}
+
+	b := make([]byte, 36)
+	if _, err := rand.Read(b); err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	in.Prefix = "pos_" + hex.EncodeToString(b[:4])
+	key := in.Prefix + "_" + hex.EncodeToString(b[4:])
+	in.Hash = hashAPIKey(key)
+
+	created, err := s.repo.Create(ctx, in)
+	if err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	return domain.NewAPIKey{APIKey: created, Key: key}, nil
+}
+
+func (s *APIKeyService) Get(ctx context.Context, id int) (domain.APIKey, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *APIKeyService) List(ctx context.Context, limit, offset int) ([]domain.APIKey, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, repository.ListParams{Limit: limit, Offset: offset})
+}
+
+// Revoke stops a key from working, at once. Revoking a revoked key keeps
+// the time it was first revoked.
+func (s *APIKeyService) Revoke(ctx context.Context, id int) (domain.APIKey, error) {
+	return s.repo.Revoke(ctx, id, time.Now().UTC())
+}
+
+// Authenticate returns the key a request presented, failing with
+// ErrUnauthorized when it is unknown, revoked or expired.
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (domain.APIKey, error) {
+	key = strings.TrimSpace(key)
+	if len(key) <= apiKeyPrefixLength || !strings.HasPrefix(key, "pos_") || key[apiKeyPrefixLength] != '_' {
+		return domain.APIKey{}, errBadAPIKey
+	}
+	k, err := s.repo.GetByPrefix(ctx, key[:apiKeyPrefixLength])
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.APIKey{}, errBadAPIKey
+	}
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(key)), []byte(k.Hash)) != 1 {
+		return domain.APIKey{}, errBadAPIKey
+	}
+
+	now := time.Now().UTC()
+	if err := k.Usable(now); err != nil {
+		return domain.APIKey{}, err
+	}
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= apiKeyTouchEvery {
+		// Failing to record the use is no reason to refuse the request.
+		if err := s.repo.Touch(ctx, k.ID, now); err != nil {
+			log.Printf("api keys: recording use of %s: %v", k.Prefix, err)
+		}
+		k.LastUsedAt = &now
+	}
+	return k, nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func validateAPIKey(in domain.APIKey) (domain.APIKey, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		return in, fmt.Errorf("%w: name is required", domain.ErrInvalid)
+	}
+	if len(in.Name) > 100 {
+		return in, fmt.Errorf("%w: name must be at most 100 characters", domain.ErrInvalid)
+	}
+
+	if len(in.Scopes) == 0 {
+		return in, fmt.Errorf("%w: at least one scope is required", domain.ErrInvalid)
+	}
+	seen := make(map[string]bool, len(in.Scopes))
+	scopes := make([]string, 0, len(in.Scopes))
+	for _, raw := range in.Scopes {
+		scope, err := domain.ParseScope(raw)
+		if err != nil {
+			return in, err
+		}
+		// Provisioning tenants is for the operator of the deployment, whose
+		// keys belong to the default tenant.
+		if strings.HasPrefix(scope, "tenants:") && in.TenantID != tenant.Default {
+			return in, fmt.Errorf("%w: only keys of the default tenant can have the %s scope", domain.ErrInvalid, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+	in.Scopes = scopes
+
+	if in.ExpiresAt != nil {
+		if !in.ExpiresAt.After(time.Now()) {
+			return in, fmt.Errorf("%w: expires_at must be in the future", domain.ErrInvalid)
+		}
+		t := in.ExpiresAt.UTC()
+		in.ExpiresAt = &t
+	}
+	return in, nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"strings"
+)
+
+type AuditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(r repository.AuditRepository) *AuditService {
+	return &AuditService{repo: r}
+}
+
+// List returns audit entries, newest first. entityType, entityID and actor
+// narrow the results when set.
+func (s *AuditService) List(ctx context.Context, entityType string, entityID int, actor string, limit, offset int) ([]domain.AuditEntry, error) {
+	switch entityType {
+	case "", domain.AuditProduct, domain.AuditCategory:
+	default:
+		return nil, fmt.Errorf("%w: entity must be %s or %s", domain.ErrInvalid, domain.AuditProduct, domain.AuditCategory)
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repo.List(ctx, repository.AuditListParams{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Actor:      strings.TrimSpace(actor),
+		Limit:      limit,
+		Offset:     offset,
+	})
+}
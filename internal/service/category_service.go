@@ -35,6 +35,24 @@ func (s *CategoryService) Get(ctx context.Context, id int) (domain.Category, err
 	return p, nil
 }
 
+// GetMany returns the categories with the given IDs by ID. IDs that match no
+// category are missing from the map.
+func (s *CategoryService) GetMany(ctx context.Context, ids []int) (map[int]domain.Category, error) {
+	out := make(map[int]domain.Category, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+
+	items, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range items {
+		out[c.ID] = c
+	}
+	return out, nil
+}
+
 func (s *CategoryService) List(ctx context.Context, limit, offset int) ([]domain.Category, error) {
 	if limit <= 0 || limit > 200 {
 		limit = 50
@@ -50,6 +68,11 @@ func (s *CategoryService) List(ctx context.Context, limit, offset int) ([]domain
 	return items, nil
 }
 
+// Export calls fn for every category, without paging.
+func (s *CategoryService) Export(ctx context.Context, fn func(domain.Category) error) error {
+	return s.repo.Export(ctx, fn)
+}
+
 func (s *CategoryService) Update(ctx context.Context, id int, in domain.Category) (domain.Category, error) {
 	updated, err := s.repo.Update(ctx, id, domain.Category{
 		ID:          id,
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"pos-api/internal/domain"
+)
+
+// referenceError reports a missing related entity in a request body as
+// invalid input rather than as a missing resource.
+func referenceError(entity string, id int, err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("%w: %s %d does not exist", domain.ErrInvalid, entity, id)
+	}
+	return err
+}
+
+// checkVariant requires a variant for products that have them and rejects
+// variants that belong to another product.
+func checkVariant(p domain.Product, variantID int) error {
+	if variantID == 0 {
+		if len(p.Variants) > 0 {
+			return fmt.Errorf("%w: product %d has variants, variant_id is required", domain.ErrInvalid, p.ID)
+		}
+		return nil
+	}
+	if _, ok := p.Variant(variantID); !ok {
+		return fmt.Errorf("%w: variant %d does not belong to product %d", domain.ErrInvalid, variantID, p.ID)
+	}
+	return nil
+}
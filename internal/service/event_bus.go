@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"pos-api/internal/tenant"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EventHandler reacts to a domain event. Events are delivered at least once,
+// so handlers should tolerate seeing the same event ID twice.
+type EventHandler func(ctx context.Context, e domain.Event) error
+
+// EventBus passes domain events to the handlers subscribed to their type.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers []subscription
+}
+
+type subscription struct {
+	types []domain.EventType
+	fn    EventHandler
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe registers fn for events of the given types, or of every type
+// when none are given.
+func (b *EventBus) Subscribe(fn EventHandler, types ...domain.EventType) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, subscription{types: types, fn: fn})
+}
+
+// Publish calls every matching handler in the order they subscribed and
+// returns their errors joined. A panicking handler counts as failed.
+func (b *EventBus) Publish(ctx context.Context, e domain.Event) error {
+	b.mu.RLock()
+	handlers := slices.Clone(b.handlers)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, h := range handlers {
+		if len(h.types) > 0 && !slices.Contains(h.types, e.Type) {
+			continue
+		}
+		if err := callHandler(ctx, h.fn, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func callHandler(ctx context.Context, fn EventHandler, e domain.Event) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("handler panicked: %v", p)
+		}
+	}()
+	return fn(ctx, e)
+}
+
+const (
+	// relayInterval is how often the relay looks for new events.
+	relayInterval = time.Second
+	// relayBatch bounds how many events one pass of the relay publishes.
+	relayBatch = 100
+)
+
+// EventRelay publishes the events in the outbox to the bus. An event whose
+// handlers fail stays in the outbox and is published again later, to every
+// handler.
+type EventRelay struct {
+	outbox  repository.OutboxRepository
+	bus     *EventBus
+	tenants TenantLister
+}
+
+func NewEventRelay(outbox repository.OutboxRepository, bus *EventBus) *EventRelay {
+	return &EventRelay{outbox: outbox, bus: bus}
+}
+
+// SetTenants makes Run relay the events of each tenant t lists, publishing
+// them with a context scoped to their tenant. It must be called before Run.
+func (r *EventRelay) SetTenants(t TenantLister) {
+	r.tenants = t
+}
+
+// Run relays events until ctx is cancelled.
+func (r *EventRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(relayInterval)
+	defer ticker.Stop()
+
+	for {
+		err := eachTenant(ctx, r.tenants, func(ctx context.Context) error {
+			for {
+				n, err := r.outbox.Relay(ctx, relayBatch, func(e domain.Event) error {
+					if err := r.bus.Publish(ctx, e); err != nil {
+						log.Printf("event relay: tenant=%d %s %d: %v", tenant.From(ctx), e.Type, e.ID, err)
+						return err
+					}
+					return nil
+				})
+				if err != nil || n < relayBatch {
+					return err
+				}
+			}
+		})
+		if err != nil {
+			log.Printf("event relay: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
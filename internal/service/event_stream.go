@@ -0,0 +1,199 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"pos-api/internal/tenant"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// StreamTopics are the topics a stream can be filtered by. The topic of an
+// event is its type up to the dot, so stock.changed is in "stock".
+var StreamTopics = []string{"product", "category", "stock"}
+
+const (
+	// streamReplayMax bounds the events replayed to a resuming client; one
+	// that missed more is told to reload instead.
+	streamReplayMax = 1000
+	// streamBuffer is how many events may wait for a slow client before it
+	// is dropped, and how many recent events of each tenant are kept to
+	// replay along with the outbox.
+	streamBuffer = 256
+)
+
+// EventStream fans published events out to connected clients. It is fed by
+// the event bus, so clients see only committed changes, and replays missed
+// events from the outbox.
+type EventStream struct {
+	outbox repository.OutboxRepository
+
+	mu   sync.Mutex
+	subs map[*StreamSubscription]struct{}
+	// recent holds the latest events of each tenant. An event is published
+	// before the relay marks it delivered, so a client resuming in between
+	// finds it here rather than in the outbox.
+	recent map[int][]domain.Event
+}
+
+func NewEventStream(outbox repository.OutboxRepository) *EventStream {
+	return &EventStream{
+		outbox: outbox,
+		subs:   make(map[*StreamSubscription]struct{}),
+		recent: make(map[int][]domain.Event),
+	}
+}
+
+// StreamSubscription receives the events of some topics.
+type StreamSubscription struct {
+	// Replay holds the events missed since the Seq the client resumed from,
+	// in Seq order; send them before the live ones.
+	Replay []domain.Event
+	// Reset is set when the client missed too much to replay and should
+	// reload what it shows.
+	Reset bool
+
+	stream  *EventStream
+	tenant  int
+	topics  []string
+	events  chan domain.Event
+	dropped chan struct{}
+
+	mu        sync.Mutex
+	replaying bool
+	pending   []domain.Event
+	replayed  map[int]bool
+}
+
+// Events delivers live events.
+func (s *StreamSubscription) Events() <-chan domain.Event {
+	return s.events
+}
+
+// Dropped is closed when the client fell too far behind and was dropped; it
+// should reconnect and resume.
+func (s *StreamSubscription) Dropped() <-chan struct{} {
+	return s.dropped
+}
+
+// Close stops the subscription.
+func (s *StreamSubscription) Close() {
+	s.stream.mu.Lock()
+	defer s.stream.mu.Unlock()
+	delete(s.stream.subs, s)
+}
+
+// ParseTopics reads a comma-separated list of topics; an empty list means
+// every topic.
+func ParseTopics(v string) ([]string, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	var topics []string
+	for _, t := range strings.Split(v, ",") {
+		t = strings.TrimSpace(t)
+		if !slices.Contains(StreamTopics, t) {
+			return nil, fmt.Errorf("%w: unknown topic %q, want one of %s", domain.ErrInvalid, t, strings.Join(StreamTopics, ", "))
+		}
+		topics = append(topics, t)
+	}
+	return topics, nil
+}
+
+// Subscribe starts a subscription to the events of the tenant of ctx in
+// topics, or in every topic when there are none. A lastSeq above zero
+// resumes after the event with that Seq.
+func (s *EventStream) Subscribe(ctx context.Context, topics []string, lastSeq int) (*StreamSubscription, error) {
+	sub := &StreamSubscription{
+		stream:    s,
+		tenant:    tenant.From(ctx),
+		topics:    topics,
+		events:    make(chan domain.Event, streamBuffer),
+		dropped:   make(chan struct{}),
+		replaying: lastSeq > 0,
+	}
+
+	// Register before reading the outbox so nothing published in between is
+	// missed; events that arrive meanwhile are held back as pending.
+	s.mu.Lock()
+	s.subs[sub] = struct{}{}
+	recent := slices.Clone(s.recent[sub.tenant])
+	s.mu.Unlock()
+	if lastSeq <= 0 {
+		return sub, nil
+	}
+
+	missed, err := s.outbox.Delivered(ctx, lastSeq, streamReplayMax+1)
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	sub.replayed = make(map[int]bool)
+	if len(missed) > streamReplayMax {
+		sub.Reset = true
+		missed, recent = nil, nil
+	}
+	for _, e := range slices.Concat(missed, recent, sub.pending) {
+		if e.Seq > lastSeq && !sub.replayed[e.ID] && sub.wants(e) {
+			sub.Replay = append(sub.Replay, e)
+		}
+		sub.replayed[e.ID] = true
+	}
+	slices.SortStableFunc(sub.Replay, func(a, b domain.Event) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	sub.pending = nil
+	sub.replaying = false
+	return sub, nil
+}
+
+func (s *StreamSubscription) wants(e domain.Event) bool {
+	if len(s.topics) == 0 {
+		return true
+	}
+	topic, _, _ := strings.Cut(string(e.Type), ".")
+	return slices.Contains(s.topics, topic)
+}
+
+// HandleEvent passes e, an event of the tenant of ctx, to the subscriptions
+// that want it. It is meant to be subscribed to the event bus, and never
+// blocks on a slow client.
+func (s *EventStream) HandleEvent(ctx context.Context, e domain.Event) error {
+	t := tenant.From(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recent := append(s.recent[t], e)
+	if len(recent) > streamBuffer {
+		recent = slices.Delete(recent, 0, len(recent)-streamBuffer)
+	}
+	s.recent[t] = recent
+
+	for sub := range s.subs {
+		if sub.tenant != t || !sub.wants(e) {
+			continue
+		}
+		sub.mu.Lock()
+		switch {
+		case sub.replaying:
+			sub.pending = append(sub.pending, e)
+		case sub.replayed[e.ID]:
+		default:
+			select {
+			case sub.events <- e:
+			default:
+				delete(s.subs, sub)
+				close(sub.dropped)
+			}
+		}
+		sub.mu.Unlock()
+	}
+	return nil
+}
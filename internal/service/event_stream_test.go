@@ -0,0 +1,118 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"testing"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/tenant"
+)
+
+// deliveredOutbox serves Delivered from a fixed list of delivered events.
+type deliveredOutbox struct {
+	events []domain.Event
+}
+
+func (o *deliveredOutbox) Relay(ctx context.Context, limit int, fn func(domain.Event) error) (int, error) {
+	return 0, nil
+}
+
+func (o *deliveredOutbox) Delivered(ctx context.Context, afterSeq, limit int) ([]domain.Event, error) {
+	out := make([]domain.Event, 0)
+	for _, e := range o.events {
+		if e.Seq > afterSeq && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Event) int { return cmp.Compare(a.Seq, b.Seq) })
+	return out, nil
+}
+
+func replayedIDs(sub *StreamSubscription) []int {
+	ids := make([]int, 0, len(sub.Replay))
+	for _, e := range sub.Replay {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestEventStreamResumesInDeliveryOrder(t *testing.T) {
+	ctx := tenant.With(context.Background(), tenant.Default)
+	// Event 3 was recorded before event 5 but committed after it, and event
+	// 4 was retried after failing once.
+	outbox := &deliveredOutbox{events: []domain.Event{
+		{ID: 5, Seq: 1, Type: domain.EventProductUpdated},
+		{ID: 3, Seq: 2, Type: domain.EventProductCreated},
+		{ID: 4, Seq: 4, Type: domain.EventStockChanged},
+	}}
+	s := NewEventStream(outbox)
+
+	sub, err := s.Subscribe(ctx, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if got := replayedIDs(sub); !slices.Equal(got, []int{3, 4}) {
+		t.Errorf("replayed events %v, want [3 4]", got)
+	}
+
+	topics, _ := ParseTopics("stock")
+	stock, err := s.Subscribe(ctx, topics, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stock.Close()
+	if got := replayedIDs(stock); !slices.Equal(got, []int{4}) {
+		t.Errorf("replayed stock events %v, want [4]", got)
+	}
+}
+
+func TestEventStreamReplaysEventsNotYetMarkedDelivered(t *testing.T) {
+	ctx := tenant.With(context.Background(), tenant.Default)
+	other := tenant.With(context.Background(), 2)
+	outbox := &deliveredOutbox{events: []domain.Event{
+		{ID: 1, Seq: 1, Type: domain.EventProductCreated},
+	}}
+	s := NewEventStream(outbox)
+
+	// Published by the relay, whose transaction has not committed yet.
+	s.HandleEvent(ctx, domain.Event{ID: 2, Seq: 2, Type: domain.EventProductUpdated})
+	s.HandleEvent(other, domain.Event{ID: 9, Seq: 3, Type: domain.EventProductUpdated})
+
+	sub, err := s.Subscribe(ctx, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if got := replayedIDs(sub); !slices.Equal(got, []int{2}) {
+		t.Errorf("replayed events %v, want [2]", got)
+	}
+
+	// Once delivered, the event is not sent again live.
+	outbox.events = append(outbox.events, domain.Event{ID: 2, Seq: 2, Type: domain.EventProductUpdated})
+	s.HandleEvent(ctx, domain.Event{ID: 2, Seq: 5, Type: domain.EventProductUpdated})
+	s.HandleEvent(ctx, domain.Event{ID: 6, Seq: 6, Type: domain.EventCategoryCreated})
+	if e := <-sub.Events(); e.ID != 6 {
+		t.Errorf("live event %d, want 6", e.ID)
+	}
+}
+
+func TestEventStreamResetsAfterTooManyMissed(t *testing.T) {
+	ctx := tenant.With(context.Background(), tenant.Default)
+	outbox := &deliveredOutbox{}
+	for i := 1; i <= streamReplayMax+2; i++ {
+		outbox.events = append(outbox.events, domain.Event{ID: i, Seq: i, Type: domain.EventProductUpdated})
+	}
+	s := NewEventStream(outbox)
+
+	sub, err := s.Subscribe(ctx, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if !sub.Reset || len(sub.Replay) != 0 {
+		t.Errorf("Reset = %v with %d replayed, want a reset and no replay", sub.Reset, len(sub.Replay))
+	}
+}
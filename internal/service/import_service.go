@@ -0,0 +1,554 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"pos-api/internal/tenant"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ImportAsyncRows is the number of rows above which an import runs as a
+	// background job.
+	ImportAsyncRows = 500
+	// importMaxErrors bounds the row errors reported for one import.
+	importMaxErrors = 1000
+	// importJobsKept bounds how many finished jobs are remembered.
+	importJobsKept = 100
+)
+
+// importFields lists the columns each kind of import understands.
+var importFields = map[domain.ImportKind][]string{
+	domain.ImportProducts:   {"name", "sku", "barcodes", "price", "quantity", "category", "category_id", "reorder_point", "reorder_quantity"},
+	domain.ImportCategories: {"name", "description"},
+}
+
+// ImportService loads products and categories from spreadsheets. Rows are
+// matched to existing records and either all of them are saved or, when any
+// row is invalid, none are. Background jobs live in memory only and are lost
+// on restart.
+type ImportService struct {
+	products   repository.ProductRepository
+	categories repository.CategoryRepository
+	stock      StockNotifier
+
+	mu     sync.Mutex
+	nextID int
+	jobs   map[int]*domain.ImportJob
+	order  []int
+	// owners holds the tenant each job was started for.
+	owners map[int]int
+}
+
+func NewImportService(products repository.ProductRepository, categories repository.CategoryRepository) *ImportService {
+	return &ImportService{
+		products:   products,
+		categories: categories,
+		nextID:     1,
+		jobs:       make(map[int]*domain.ImportJob),
+		owners:     make(map[int]int),
+	}
+}
+
+// SetStockNotifier registers n to hear about products whose quantity changed.
+func (s *ImportService) SetStockNotifier(n StockNotifier) {
+	s.stock = n
+}
+
+// Run imports rows, the first of which is the header, and waits for the
+// result. mapping maps import fields to header names; fields it leaves out
+// are read from the column named after them.
+func (s *ImportService) Run(ctx context.Context, kind domain.ImportKind, rows [][]string, mapping map[string]string, dryRun bool) (domain.ImportResult, error) {
+	plan, err := planImport(kind, rows, mapping)
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+	return s.execute(ctx, plan, dryRun, func(int) {})
+}
+
+// Start checks the header and runs the import in the background, returning
+// the job to poll for progress.
+func (s *ImportService) Start(ctx context.Context, kind domain.ImportKind, rows [][]string, mapping map[string]string, dryRun bool) (domain.ImportJob, error) {
+	plan, err := planImport(kind, rows, mapping)
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+
+	s.mu.Lock()
+	job := &domain.ImportJob{
+		ID:        s.nextID,
+		Kind:      kind,
+		Status:    domain.ImportJobQueued,
+		DryRun:    dryRun,
+		Total:     len(plan.rows),
+		CreatedAt: time.Now().UTC(),
+	}
+	s.nextID++
+	s.jobs[job.ID] = job
+	s.owners[job.ID] = tenant.From(ctx)
+	s.order = append(s.order, job.ID)
+	s.forgetOldJobs()
+	queued := *job
+	s.mu.Unlock()
+
+	// The job outlives the request that started it.
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		s.updateJob(job.ID, func(j *domain.ImportJob) { j.Status = domain.ImportJobRunning })
+
+		res, err := s.execute(ctx, plan, dryRun, func(n int) {
+			s.updateJob(job.ID, func(j *domain.ImportJob) { j.Processed = n })
+		})
+
+		s.updateJob(job.ID, func(j *domain.ImportJob) {
+			now := time.Now().UTC()
+			j.FinishedAt = &now
+			if err != nil {
+				j.Status = domain.ImportJobFailed
+				j.Error = err.Error()
+				return
+			}
+			j.Status = domain.ImportJobSucceeded
+			if !res.Applied && !res.DryRun {
+				j.Status = domain.ImportJobFailed
+				j.Error = "the file has invalid rows; nothing was imported"
+			}
+			j.Result = &res
+		})
+	}()
+	return queued, nil
+}
+
+// Job reports the progress of a background import started for the tenant
+// of ctx.
+func (s *ImportService) Job(ctx context.Context, id int) (domain.ImportJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok || s.owners[id] != tenant.From(ctx) {
+		return domain.ImportJob{}, domain.ErrNotFound
+	}
+	out := *job
+	if job.Result != nil {
+		res := *job.Result
+		out.Result = &res
+	}
+	return out, nil
+}
+
+func (s *ImportService) updateJob(id int, fn func(j *domain.ImportJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+// forgetOldJobs drops the oldest finished jobs beyond importJobsKept.
+func (s *ImportService) forgetOldJobs() {
+	excess := len(s.order) - importJobsKept
+	kept := s.order[:0]
+	for _, id := range s.order {
+		if excess > 0 && s.jobs[id].FinishedAt != nil {
+			delete(s.jobs, id)
+			delete(s.owners, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	s.order = kept
+}
+
+func (s *ImportService) execute(ctx context.Context, plan importPlan, dryRun bool, progress func(int)) (domain.ImportResult, error) {
+	res := domain.ImportResult{
+		DryRun: dryRun,
+		Total:  len(plan.rows),
+		Errors: []domain.ImportRowError{},
+	}
+
+	var err error
+	if plan.kind == domain.ImportCategories {
+		err = s.importCategories(ctx, plan, &res, progress)
+	} else {
+		err = s.importProducts(ctx, plan, &res, progress)
+	}
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+	return res, nil
+}
+
+func (s *ImportService) importProducts(ctx context.Context, plan importPlan, res *domain.ImportResult, progress func(int)) error {
+	categories := make(map[string]int)
+	seen := make(map[string]int)
+	batch := make([]domain.Product, 0, len(plan.rows))
+	changed := make(map[int]bool)
+
+	for i, row := range plan.rows {
+		errs := rowErrors{row: row.num}
+		p, existing, err := s.productRow(ctx, plan, row, categories, &errs)
+		if err != nil {
+			return err
+		}
+
+		// A product may appear only once per file, however it is matched.
+		keys := []string{"name:" + strings.ToLower(p.Name)}
+		if p.SKU != "" {
+			keys = []string{"sku:" + p.SKU}
+		}
+		if existing.ID != 0 {
+			keys = append(keys, "id:"+strconv.Itoa(existing.ID))
+		}
+		for _, code := range p.Barcodes {
+			keys = append(keys, "barcode:"+code)
+		}
+		for _, key := range keys {
+			if first, ok := seen[key]; ok && len(errs.errs) == 0 {
+				errs.add("", fmt.Sprintf("duplicates row %d", first))
+			}
+			seen[key] = row.num
+		}
+
+		if !recordRow(res, errs, existing.ID != 0) {
+			batch = append(batch, p)
+			if existing.ID == 0 || p.Quantity != existing.Quantity || p.ReorderPoint != existing.ReorderPoint {
+				changed[len(batch)-1] = true
+			}
+		}
+		progress(i + 1)
+	}
+
+	if res.Failed > 0 || res.DryRun || len(batch) == 0 {
+		res.Applied = res.Failed == 0 && !res.DryRun
+		return nil
+	}
+	saved, err := s.products.SaveAll(ctx, batch)
+	if err != nil {
+		return err
+	}
+	res.Applied = true
+
+	ids := make([]int, 0, len(changed))
+	for i, p := range saved {
+		if changed[i] {
+			ids = append(ids, p.ID)
+		}
+	}
+	notifyStock(ctx, s.stock, ids...)
+	return nil
+}
+
+// productRow turns a row into the product to save, starting from the
+// product it matches: by SKU when the row has one, otherwise by name. Empty
+// cells keep the current values.
+func (s *ImportService) productRow(ctx context.Context, plan importPlan, row importRow, categories map[string]int, errs *rowErrors) (domain.Product, domain.Product, error) {
+	name, _ := plan.cell(row, "name")
+	sku, _ := plan.cell(row, "sku")
+	if name == "" {
+		errs.add("name", "name is required")
+	}
+
+	var existing domain.Product
+	if sku != "" {
+		p, err := s.products.GetBySKU(ctx, sku)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return domain.Product{}, domain.Product{}, err
+		}
+		existing = p
+	} else if name != "" {
+		matches, err := s.products.FindByName(ctx, name)
+		if err != nil {
+			return domain.Product{}, domain.Product{}, err
+		}
+		if len(matches) > 1 {
+			errs.add("name", fmt.Sprintf("name %q matches %d products; add a sku to pick one", name, len(matches)))
+		} else if len(matches) == 1 {
+			existing = matches[0]
+		}
+	}
+
+	p := existing
+	p.Name = name
+	if sku != "" {
+		p.SKU = sku
+	}
+	if v, ok := plan.cell(row, "barcodes"); ok {
+		p.Barcodes = strings.FieldsFunc(v, func(r rune) bool { return r == '|' || r == ';' || r == ',' })
+	}
+	if n, ok := plan.amount(row, "price", errs); ok {
+		p.Price = n
+	}
+	if n, ok := plan.amount(row, "quantity", errs); ok {
+		if len(existing.Variants) > 0 {
+			errs.add("quantity", "product has variants; its quantity is the sum of their quantities")
+		}
+		p.Quantity = n
+	}
+	if n, ok := plan.amount(row, "reorder_point", errs); ok {
+		p.ReorderPoint = n
+	}
+	if n, ok := plan.amount(row, "reorder_quantity", errs); ok {
+		p.ReorderQuantity = n
+	}
+
+	// category_id wins over a category name in the same row.
+	if id, ok := plan.amount(row, "category_id", errs); ok {
+		if _, err := s.categories.GetByID(ctx, id); err != nil {
+			if !errors.Is(err, domain.ErrNotFound) {
+				return domain.Product{}, domain.Product{}, err
+			}
+			errs.add("category_id", fmt.Sprintf("category %d does not exist", id))
+		}
+		p.CategoryID = id
+	} else if v, ok := plan.cell(row, "category"); ok {
+		id, err := s.categoryID(ctx, v, categories)
+		if err != nil {
+			return domain.Product{}, domain.Product{}, err
+		}
+		if id == 0 {
+			errs.add("category", fmt.Sprintf("category %q does not exist or is ambiguous", v))
+		}
+		p.CategoryID = id
+	}
+
+	if len(errs.errs) > 0 {
+		return p, existing, nil
+	}
+	p = normalizeCodes(p)
+	for _, check := range []error{validateReorder(p), validateCodes(ctx, s.products, existing.ID, p)} {
+		if err := errs.fromError(check); err != nil {
+			return domain.Product{}, domain.Product{}, err
+		}
+	}
+	return p, existing, nil
+}
+
+// categoryID resolves a category name, caching the answer for the rest of
+// the file. It returns zero when no single category has the name.
+func (s *ImportService) categoryID(ctx context.Context, name string, cache map[string]int) (int, error) {
+	key := strings.ToLower(name)
+	if id, ok := cache[key]; ok {
+		return id, nil
+	}
+	matches, err := s.categories.FindByName(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	id := 0
+	if len(matches) == 1 {
+		id = matches[0].ID
+	}
+	cache[key] = id
+	return id, nil
+}
+
+func (s *ImportService) importCategories(ctx context.Context, plan importPlan, res *domain.ImportResult, progress func(int)) error {
+	seen := make(map[string]int)
+	batch := make([]domain.Category, 0, len(plan.rows))
+
+	for i, row := range plan.rows {
+		errs := rowErrors{row: row.num}
+		name, _ := plan.cell(row, "name")
+		if name == "" {
+			errs.add("name", "name is required")
+		}
+
+		var existing domain.Category
+		if name != "" {
+			matches, err := s.categories.FindByName(ctx, name)
+			if err != nil {
+				return err
+			}
+			if len(matches) > 1 {
+				errs.add("name", fmt.Sprintf("name %q matches %d categories", name, len(matches)))
+			} else if len(matches) == 1 {
+				existing = matches[0]
+			}
+
+			key := strings.ToLower(name)
+			if first, ok := seen[key]; ok {
+				errs.add("name", fmt.Sprintf("duplicates row %d", first))
+			}
+			seen[key] = row.num
+		}
+
+		c := existing
+		c.Name = name
+		if v, ok := plan.cell(row, "description"); ok {
+			c.Description = v
+		}
+
+		if !recordRow(res, errs, existing.ID != 0) {
+			batch = append(batch, c)
+		}
+		progress(i + 1)
+	}
+
+	if res.Failed > 0 || res.DryRun || len(batch) == 0 {
+		res.Applied = res.Failed == 0 && !res.DryRun
+		return nil
+	}
+	if _, err := s.categories.SaveAll(ctx, batch); err != nil {
+		return err
+	}
+	res.Applied = true
+	return nil
+}
+
+// recordRow counts a row in the result and reports whether it failed.
+func recordRow(res *domain.ImportResult, errs rowErrors, update bool) bool {
+	if len(errs.errs) > 0 {
+		res.Failed++
+		room := importMaxErrors - len(res.Errors)
+		res.Errors = append(res.Errors, errs.errs[:min(room, len(errs.errs))]...)
+		return true
+	}
+	if update {
+		res.Updated++
+	} else {
+		res.Created++
+	}
+	return false
+}
+
+// rowErrors collects the problems found in one row.
+type rowErrors struct {
+	row  int
+	errs []domain.ImportRowError
+}
+
+func (e *rowErrors) add(column, msg string) {
+	e.errs = append(e.errs, domain.ImportRowError{Row: e.row, Column: column, Message: msg})
+}
+
+// fromError records a validation error against the row. Errors that are not
+// about the input are returned instead.
+func (e *rowErrors) fromError(err error) error {
+	for _, kind := range []error{domain.ErrInvalid, domain.ErrConflict} {
+		if errors.Is(err, kind) {
+			e.add("", strings.TrimPrefix(err.Error(), kind.Error()+": "))
+			return nil
+		}
+	}
+	return err
+}
+
+type importRow struct {
+	num   int
+	cells []string
+}
+
+// importPlan is a file whose header has been matched to import fields.
+type importPlan struct {
+	kind    domain.ImportKind
+	columns map[string]int
+	rows    []importRow
+}
+
+func planImport(kind domain.ImportKind, rows [][]string, mapping map[string]string) (importPlan, error) {
+	fields, ok := importFields[kind]
+	if !ok {
+		return importPlan{}, fmt.Errorf("%w: unknown import kind %q", domain.ErrInvalid, kind)
+	}
+	if len(rows) == 0 {
+		return importPlan{}, fmt.Errorf("%w: the file is empty", domain.ErrInvalid)
+	}
+
+	header := rows[0]
+	find := func(name string) int {
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(name)) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	plan := importPlan{kind: kind, columns: make(map[string]int)}
+	for field, name := range mapping {
+		if !containsString(fields, field) {
+			return importPlan{}, fmt.Errorf("%w: unknown import field %q, expected one of %s", domain.ErrInvalid, field, strings.Join(fields, ", "))
+		}
+		i := find(name)
+		if i < 0 {
+			return importPlan{}, fmt.Errorf("%w: column %q for %s is not in the header", domain.ErrInvalid, name, field)
+		}
+		plan.columns[field] = i
+	}
+	for _, field := range fields {
+		if _, ok := plan.columns[field]; ok {
+			continue
+		}
+		for i, h := range header {
+			if headerField(h) == field {
+				plan.columns[field] = i
+				break
+			}
+		}
+	}
+	if _, ok := plan.columns["name"]; !ok {
+		return importPlan{}, fmt.Errorf("%w: the header has no name column", domain.ErrInvalid)
+	}
+
+	for i, cells := range rows[1:] {
+		if strings.TrimSpace(strings.Join(cells, "")) == "" {
+			continue
+		}
+		plan.rows = append(plan.rows, importRow{num: i + 2, cells: cells})
+	}
+	return plan, nil
+}
+
+// headerField normalises a header such as "Reorder Point" to reorder_point.
+func headerField(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(h)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// cell returns the trimmed value of field in row and whether it is set.
+func (p importPlan) cell(row importRow, field string) (string, bool) {
+	i, ok := p.columns[field]
+	if !ok || i >= len(row.cells) {
+		return "", false
+	}
+	v := strings.TrimSpace(row.cells[i])
+	return v, v != ""
+}
+
+// amount reads a non-negative whole number. Spreadsheets store numbers as
+// floats, so "1500.0" is accepted.
+func (p importPlan) amount(row importRow, field string, errs *rowErrors) (int, bool) {
+	v, ok := p.cell(row, field)
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f != math.Trunc(f) || math.Abs(f) > math.MaxInt32 {
+		errs.add(field, fmt.Sprintf("%q is not a whole number", v))
+		return 0, false
+	}
+	if f < 0 {
+		errs.add(field, fmt.Sprintf("%s must not be negative", field))
+		return 0, false
+	}
+	return int(f), true
+}
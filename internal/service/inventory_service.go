@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+)
+
+type InventoryService struct {
+	products       repository.ProductRepository
+	locations      repository.LocationRepository
+	transfers      repository.StockTransferRepository
+	purchaseOrders repository.PurchaseOrderRepository
+	suppliers      repository.SupplierRepository
+	alerts         *StockAlertEvaluator
+}
+
+func NewInventoryService(products repository.ProductRepository, locations repository.LocationRepository, transfers repository.StockTransferRepository, purchaseOrders repository.PurchaseOrderRepository, suppliers repository.SupplierRepository) *InventoryService {
+	return &InventoryService{
+		products:       products,
+		locations:      locations,
+		transfers:      transfers,
+		purchaseOrders: purchaseOrders,
+		suppliers:      suppliers,
+	}
+}
+
+// SetStockAlerts connects the evaluator that is notified of stock changes and
+// keeps the recent alerts.
+func (s *InventoryService) SetStockAlerts(e *StockAlertEvaluator) {
+	s.alerts = e
+}
+
+func (s *InventoryService) ListStock(ctx context.Context, locationID, productID, limit, offset int) ([]domain.StockLevel, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := s.products.ListStock(ctx, repository.StockListParams{
+		LocationID: locationID,
+		ProductID:  productID,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ProductStock returns the per-location breakdown of a product's quantity.
+func (s *InventoryService) ProductStock(ctx context.Context, productID int) ([]domain.StockLevel, error) {
+	return s.products.StockByLocation(ctx, productID)
+}
+
+// CostLayers lists the receipts a product's stock on hand is costed from.
+func (s *InventoryService) CostLayers(ctx context.Context, productID int) ([]domain.CostLayer, error) {
+	return s.products.CostLayers(ctx, productID)
+}
+
+// Adjust changes the stock of one product at a location, for example after a
+// count or a write-off. The product total changes by the same amount.
+func (s *InventoryService) Adjust(ctx context.Context, c domain.StockChange) (domain.Product, error) {
+	if c.LocationID == 0 {
+		return domain.Product{}, fmt.Errorf("%w: location_id is required", domain.ErrInvalid)
+	}
+	if c.Delta == 0 {
+		return domain.Product{}, fmt.Errorf("%w: delta must not be zero", domain.ErrInvalid)
+	}
+	if c.UnitCost < 0 {
+		return domain.Product{}, fmt.Errorf("%w: unit_cost must not be negative", domain.ErrInvalid)
+	}
+	if _, err := s.locations.GetByID(ctx, c.LocationID); err != nil {
+		return domain.Product{}, referenceError("location", c.LocationID, err)
+	}
+	p, err := s.products.GetByID(ctx, c.ProductID)
+	if err != nil {
+		return domain.Product{}, referenceError("product", c.ProductID, err)
+	}
+	if err := checkVariant(p, c.VariantID); err != nil {
+		return domain.Product{}, err
+	}
+
+	if err := s.products.AdjustStock(ctx, []domain.StockChange{c}); err != nil {
+		return domain.Product{}, err
+	}
+	if s.alerts != nil {
+		s.alerts.Notify(ctx, c.ProductID)
+	}
+	return s.products.GetByID(ctx, c.ProductID)
+}
+
+func (s *InventoryService) CreateTransfer(ctx context.Context, in domain.StockTransfer) (domain.StockTransfer, error) {
+	if in.FromLocationID == in.ToLocationID {
+		return domain.StockTransfer{}, fmt.Errorf("%w: source and destination must differ", domain.ErrInvalid)
+	}
+	for _, id := range []int{in.FromLocationID, in.ToLocationID} {
+		if _, err := s.locations.GetByID(ctx, id); err != nil {
+			return domain.StockTransfer{}, referenceError("location", id, err)
+		}
+	}
+	if len(in.Lines) == 0 {
+		return domain.StockTransfer{}, fmt.Errorf("%w: at least one line is required", domain.ErrInvalid)
+	}
+	for i, l := range in.Lines {
+		if l.Quantity <= 0 {
+			return domain.StockTransfer{}, fmt.Errorf("%w: line %d quantity must be positive", domain.ErrInvalid, i+1)
+		}
+		p, err := s.products.GetByID(ctx, l.ProductID)
+		if err != nil {
+			return domain.StockTransfer{}, referenceError("product", l.ProductID, err)
+		}
+		if err := checkVariant(p, l.VariantID); err != nil {
+			return domain.StockTransfer{}, err
+		}
+	}
+
+	created, err := s.transfers.Create(ctx, in)
+	if err != nil {
+		return domain.StockTransfer{}, err
+	}
+	return created, nil
+}
+
+func (s *InventoryService) GetTransfer(ctx context.Context, id int) (domain.StockTransfer, error) {
+	return s.transfers.GetByID(ctx, id)
+}
+
+func (s *InventoryService) ListTransfers(ctx context.Context, limit, offset int) ([]domain.StockTransfer, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := s.transfers.List(ctx, repository.ListParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *InventoryService) ReceiveTransfer(ctx context.Context, id int) (domain.StockTransfer, error) {
+	return s.transfers.Complete(ctx, id, domain.StockTransferReceived)
+}
+
+func (s *InventoryService) CancelTransfer(ctx context.Context, id int) (domain.StockTransfer, error) {
+	return s.transfers.Complete(ctx, id, domain.StockTransferCancelled)
+}
+
+func (s *InventoryService) LowStock(ctx context.Context, limit, offset int) ([]domain.Product, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := s.products.ListLowStock(ctx, repository.ListParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Alerts returns the most recent low-stock and restock alerts of the tenant
+// of ctx.
+func (s *InventoryService) Alerts(ctx context.Context) []domain.StockAlert {
+	if s.alerts == nil {
+		return []domain.StockAlert{}
+	}
+	return s.alerts.Recent(ctx)
+}
+
+// ReorderSuggestions builds a purchase list for every low product, grouped by
+// the supplier it was last bought from. Quantities already on open purchase
+// orders are taken into account.
+func (s *InventoryService) ReorderSuggestions(ctx context.Context) ([]domain.ReorderSuggestion, error) {
+	low := make([]domain.Product, 0)
+	for offset := 0; ; offset += 200 {
+		items, err := s.products.ListLowStock(ctx, repository.ListParams{Limit: 200, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		low = append(low, items...)
+		if len(items) < 200 {
+			break
+		}
+	}
+	if len(low) == 0 {
+		return []domain.ReorderSuggestion{}, nil
+	}
+
+	ids := make([]int, 0, len(low))
+	for _, p := range low {
+		ids = append(ids, p.ID)
+	}
+	purchasing, err := s.purchaseOrders.Purchasing(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[int]*domain.ReorderSuggestion)
+	order := make([]int, 0)
+	for _, p := range low {
+		info := purchasing[p.ID]
+		qty := domain.SuggestedReorder(p, info.OnOrder)
+		if qty == 0 {
+			continue
+		}
+
+		g, ok := groups[info.SupplierID]
+		if !ok {
+			g = &domain.ReorderSuggestion{SupplierID: info.SupplierID, Items: []domain.ReorderSuggestionItem{}}
+			if info.SupplierID != 0 {
+				sup, err := s.suppliers.GetByID(ctx, info.SupplierID)
+				if err != nil && !errors.Is(err, domain.ErrNotFound) {
+					return nil, err
+				}
+				g.SupplierName = sup.Name
+			}
+			groups[info.SupplierID] = g
+			order = append(order, info.SupplierID)
+		}
+
+		g.Items = append(g.Items, domain.ReorderSuggestionItem{
+			ProductID:         p.ID,
+			Name:              p.Name,
+			Quantity:          p.Quantity,
+			ReorderPoint:      p.ReorderPoint,
+			OnOrder:           info.OnOrder,
+			SuggestedQuantity: qty,
+			UnitCost:          info.UnitCost,
+			LineCost:          qty * info.UnitCost,
+		})
+		g.TotalCost += qty * info.UnitCost
+	}
+
+	out := make([]domain.ReorderSuggestion, 0, len(order))
+	for _, id := range order {
+		out = append(out, *groups[id])
+	}
+	return out, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"strings"
+)
+
+type LocationService struct {
+	repo repository.LocationRepository
+}
+
+func NewLocationService(r repository.LocationRepository) *LocationService {
+	return &LocationService{repo: r}
+}
+
+func (s *LocationService) Create(ctx context.Context, in domain.Location) (domain.Location, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		return domain.Location{}, fmt.Errorf("%w: name is required", domain.ErrInvalid)
+	}
+
+	created, err := s.repo.Create(ctx, in)
+	if err != nil {
+		return domain.Location{}, err
+	}
+	return created, nil
+}
+
+func (s *LocationService) Get(ctx context.Context, id int) (domain.Location, error) {
+	l, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Location{}, err
+	}
+	return l, nil
+}
+
+func (s *LocationService) List(ctx context.Context, limit, offset int) ([]domain.Location, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := s.repo.List(ctx, repository.ListParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *LocationService) Update(ctx context.Context, id int, in domain.Location) (domain.Location, error) {
+	in.ID = id
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		return domain.Location{}, fmt.Errorf("%w: name is required", domain.ErrInvalid)
+	}
+
+	updated, err := s.repo.Update(ctx, id, in)
+	if err != nil {
+		return domain.Location{}, err
+	}
+	return updated, nil
+}
+
+func (s *LocationService) Delete(ctx context.Context, id int) error {
+	err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
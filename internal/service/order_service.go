@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"strings"
+	"time"
+)
+
+type OrderService struct {
+	repo      repository.OrderRepository
+	products  repository.ProductRepository
+	shifts    repository.ShiftRepository
+	locations repository.LocationRepository
+	settings  SettingsProvider
+	stock     StockNotifier
+}
+
+// NewOrderService prices orders with the tax rate of the tenant's settings,
+// a percentage added on top of the item prices.
+func NewOrderService(r repository.OrderRepository, products repository.ProductRepository, shifts repository.ShiftRepository, locations repository.LocationRepository, settings SettingsProvider) *OrderService {
+	return &OrderService{repo: r, products: products, shifts: shifts, locations: locations, settings: settings}
+}
+
+// SetStockNotifier registers n to hear about products whose quantity changed.
+func (s *OrderService) SetStockNotifier(n StockNotifier) {
+	s.stock = n
+}
+
+// Settings returns the settings in effect for the tenant of ctx, which
+// receipts are printed with.
+func (s *OrderService) Settings(ctx context.Context) (domain.Settings, error) {
+	return s.settings.Settings(ctx)
+}
+
+// Offline sales are accepted with the time the terminal recorded, as long as
+// it is no older than OfflineSaleMaxAge and no further ahead of the server's
+// clock than OfflineClockSkew.
+const (
+	OfflineSaleMaxAge = 30 * 24 * time.Hour
+	OfflineClockSkew  = 5 * time.Minute
+)
+
+// offlineAttempts bounds how often an offline sale is retried when stock
+// changes between working out its shortfalls and recording it.
+const offlineAttempts = 3
+
+// Create rings up a sale. Item names and prices come from the catalog, not
+// the request, and the stock is taken out in the same step.
+func (s *OrderService) Create(ctx context.Context, in domain.Order) (domain.Order, error) {
+	in.CreatedAt = time.Time{}
+	if err := s.prepare(ctx, &in, false); err != nil {
+		return domain.Order{}, err
+	}
+
+	created, err := s.repo.Create(ctx, in)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	notifyStock(ctx, s.stock, productIDs(in)...)
+	return created, nil
+}
+
+// CreateOffline records a sale a terminal rang up while offline. The sale
+// keeps the time and unit prices the terminal gave it, and its shift need
+// only have been open at that time. Units no longer in stock do not stop
+// the sale: they are recorded as the item's shortfall and the rest is taken
+// out of stock.
+func (s *OrderService) CreateOffline(ctx context.Context, in domain.Order) (domain.Order, error) {
+	if in.CreatedAt.IsZero() {
+		return domain.Order{}, fmt.Errorf("%w: created_at is required", domain.ErrInvalid)
+	}
+	now := time.Now()
+	if in.CreatedAt.After(now.Add(OfflineClockSkew)) {
+		return domain.Order{}, fmt.Errorf("%w: created_at is in the future", domain.ErrInvalid)
+	}
+	if in.CreatedAt.Before(now.Add(-OfflineSaleMaxAge)) {
+		return domain.Order{}, fmt.Errorf("%w: created_at is more than %d days ago", domain.ErrInvalid, int(OfflineSaleMaxAge/(24*time.Hour)))
+	}
+	if err := s.prepare(ctx, &in, true); err != nil {
+		return domain.Order{}, err
+	}
+
+	var err error
+	for attempt := 1; attempt <= offlineAttempts; attempt++ {
+		changed, shortErr := s.fillShortfalls(ctx, &in)
+		if shortErr != nil {
+			return domain.Order{}, shortErr
+		}
+		if attempt > 1 && !changed {
+			// The stock is as it was, so the conflict was about something
+			// else.
+			break
+		}
+		var created domain.Order
+		created, err = s.repo.Create(ctx, in)
+		if err == nil {
+			notifyStock(ctx, s.stock, productIDs(in)...)
+			return created, nil
+		}
+		if !errors.Is(err, domain.ErrConflict) {
+			break
+		}
+	}
+	return domain.Order{}, err
+}
+
+// prepare checks a sale and fills in its shift details, item names and
+// totals. Unit prices come from the catalog unless keepPrices is set.
+func (s *OrderService) prepare(ctx context.Context, in *domain.Order, keepPrices bool) error {
+	in.ClientID = strings.TrimSpace(in.ClientID)
+	if len(in.ClientID) > 100 {
+		return fmt.Errorf("%w: client_id must be at most 100 characters", domain.ErrInvalid)
+	}
+	if in.ShiftID != 0 {
+		shift, err := s.shifts.GetByID(ctx, in.ShiftID)
+		if err != nil {
+			return referenceError("shift", in.ShiftID, err)
+		}
+		if err := shift.CheckSale(in.CreatedAt); err != nil {
+			return err
+		}
+		in.RegisterID = shift.RegisterID
+		in.UserID = shift.UserID
+	}
+	if in.LocationID != 0 {
+		if _, err := s.locations.GetByID(ctx, in.LocationID); err != nil {
+			return referenceError("location", in.LocationID, err)
+		}
+	}
+	if len(in.Items) == 0 {
+		return fmt.Errorf("%w: at least one item is required", domain.ErrInvalid)
+	}
+
+	for i, it := range in.Items {
+		if it.Quantity <= 0 {
+			return fmt.Errorf("%w: item %d quantity must be positive", domain.ErrInvalid, i+1)
+		}
+		if keepPrices && it.UnitPrice < 0 {
+			return fmt.Errorf("%w: item %d unit_price must not be negative", domain.ErrInvalid, i+1)
+		}
+		p, err := s.products.GetByID(ctx, it.ProductID)
+		if err != nil {
+			return referenceError("product", it.ProductID, err)
+		}
+		if err := checkVariant(p, it.VariantID); err != nil {
+			return err
+		}
+
+		in.Items[i].Name = p.Name
+		in.Items[i].SKU = p.SKU
+		in.Items[i].Shortfall = 0
+		price := p.Price
+		if v, ok := p.Variant(it.VariantID); ok {
+			if v.SKU != "" {
+				in.Items[i].SKU = v.SKU
+			}
+			price = v.EffectivePrice(p.Price)
+		}
+		if !keepPrices {
+			in.Items[i].UnitPrice = price
+		}
+	}
+	set, err := s.settings.Settings(ctx)
+	if err != nil {
+		return err
+	}
+	return in.Price(set.TaxRate)
+}
+
+func productIDs(o domain.Order) []int {
+	ids := make([]int, 0, len(o.Items))
+	for _, it := range o.Items {
+		ids = append(ids, it.ProductID)
+	}
+	return ids
+}
+
+// fillShortfalls sets the shortfall of each item from the stock now on hand,
+// at the sale's location if it has one, and reports whether any changed.
+func (s *OrderService) fillShortfalls(ctx context.Context, in *domain.Order) (bool, error) {
+	type stockKey struct{ productID, variantID int }
+	onHand := make(map[stockKey]int)
+	for _, it := range in.Items {
+		k := stockKey{it.ProductID, it.VariantID}
+		if _, ok := onHand[k]; ok {
+			continue
+		}
+		p, err := s.products.GetByID(ctx, it.ProductID)
+		if err != nil {
+			return false, referenceError("product", it.ProductID, err)
+		}
+		qty := p.Quantity
+		if v, ok := p.Variant(it.VariantID); ok {
+			qty = v.Quantity
+		}
+		if in.LocationID != 0 {
+			levels, err := s.products.StockByLocation(ctx, it.ProductID)
+			if err != nil {
+				return false, err
+			}
+			atLocation := 0
+			for _, l := range levels {
+				if l.LocationID == in.LocationID && l.VariantID == it.VariantID {
+					atLocation = l.Quantity
+				}
+			}
+			qty = min(qty, atLocation)
+		}
+		onHand[k] = max(qty, 0)
+	}
+
+	changed := false
+	for i, it := range in.Items {
+		k := stockKey{it.ProductID, it.VariantID}
+		taken := min(it.Quantity, onHand[k])
+		onHand[k] -= taken
+		if short := it.Quantity - taken; short != it.Shortfall {
+			in.Items[i].Shortfall = short
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+func (s *OrderService) Get(ctx context.Context, id int) (domain.Order, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *OrderService) GetByClientID(ctx context.Context, clientID string) (domain.Order, error) {
+	return s.repo.GetByClientID(ctx, clientID)
+}
+
+func (s *OrderService) List(ctx context.Context, limit, offset int) ([]domain.Order, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := s.repo.List(ctx, repository.ListParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
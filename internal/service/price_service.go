@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"pos-api/internal/actor"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"pos-api/internal/tenant"
+	"strings"
+	"time"
+)
+
+// priceCheckInterval is the longest the scheduler sleeps, so prices
+// scheduled through another instance are still applied on time.
+const priceCheckInterval = time.Minute
+
+type PriceService struct {
+	prices   repository.PriceRepository
+	products repository.ProductRepository
+	tenants  TenantLister
+	wake     chan struct{}
+}
+
+func NewPriceService(prices repository.PriceRepository, products repository.ProductRepository) *PriceService {
+	return &PriceService{
+		prices:   prices,
+		products: products,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Prices returns the current price of a product with its history and
+// upcoming changes.
+func (s *PriceService) Prices(ctx context.Context, productID int) (domain.ProductPrices, error) {
+	p, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return domain.ProductPrices{}, err
+	}
+	history, err := s.prices.History(ctx, productID)
+	if err != nil {
+		return domain.ProductPrices{}, err
+	}
+	upcoming, err := s.prices.Upcoming(ctx, productID)
+	if err != nil {
+		return domain.ProductPrices{}, err
+	}
+	return domain.ProductPrices{
+		ProductID: p.ID,
+		Price:     p.Price,
+		History:   history,
+		Upcoming:  upcoming,
+	}, nil
+}
+
+// Schedule sets a price for a product to take from in.EffectiveFrom. The
+// reason defaults to the one given with the request.
+func (s *PriceService) Schedule(ctx context.Context, productID int, in domain.ScheduledPrice) (domain.ScheduledPrice, error) {
+	if in.Price < 0 {
+		return domain.ScheduledPrice{}, fmt.Errorf("%w: price must not be negative", domain.ErrInvalid)
+	}
+	if in.EffectiveFrom.IsZero() {
+		return domain.ScheduledPrice{}, fmt.Errorf("%w: effective_from is required", domain.ErrInvalid)
+	}
+	if !in.EffectiveFrom.After(time.Now()) {
+		return domain.ScheduledPrice{}, fmt.Errorf("%w: effective_from must be in the future", domain.ErrInvalid)
+	}
+
+	by := actor.From(ctx)
+	reason := strings.TrimSpace(in.Reason)
+	if reason == "" {
+		reason = by.Reason
+	}
+	created, err := s.prices.Schedule(ctx, domain.ScheduledPrice{
+		ProductID:     productID,
+		Price:         in.Price,
+		EffectiveFrom: in.EffectiveFrom.UTC(),
+		Reason:        reason,
+		Actor:         by.Name,
+	})
+	if err != nil {
+		return domain.ScheduledPrice{}, err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return created, nil
+}
+
+// SetTenants makes Run apply the prices of each tenant t lists. It must be
+// called before Run.
+func (s *PriceService) SetTenants(t TenantLister) {
+	s.tenants = t
+}
+
+func (s *PriceService) Cancel(ctx context.Context, productID, id int) (domain.ScheduledPrice, error) {
+	return s.prices.Cancel(ctx, productID, id)
+}
+
+// Run applies scheduled prices as they fall due until ctx is cancelled. It
+// sleeps until the next one, waking early when a price is scheduled.
+func (s *PriceService) Run(ctx context.Context) error {
+	for {
+		// A tenant whose prices failed does not shorten the wait, so it is
+		// retried after the full interval rather than at once.
+		wait := priceCheckInterval
+		err := eachTenant(ctx, s.tenants, func(ctx context.Context) error {
+			if err := s.applyDue(ctx); err != nil {
+				return err
+			}
+			next, ok, err := s.prices.NextDue(ctx)
+			if err != nil {
+				return err
+			}
+			if ok {
+				wait = min(wait, max(time.Until(next), 0))
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("price scheduler: %v", err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *PriceService) applyDue(ctx context.Context) error {
+	for {
+		applied, err := s.prices.ApplyDue(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+		for _, sp := range applied {
+			log.Printf("price scheduler: tenant=%d product=%d price=%d scheduled_price=%d", tenant.From(ctx), sp.ProductID, sp.Price, sp.ID)
+		}
+		if len(applied) == 0 {
+			return nil
+		}
+	}
+}
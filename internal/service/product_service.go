@@ -2,28 +2,39 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"pos-api/internal/domain"
 	"pos-api/internal/repository"
 	"strings"
 )
 
 type ProductService struct {
-	repo repository.ProductRepository
+	repo       repository.ProductRepository
+	categories repository.CategoryRepository
+	stock      StockNotifier
 }
 
-func NewProductService(r repository.ProductRepository) *ProductService {
-	return &ProductService{repo: r}
+func NewProductService(r repository.ProductRepository, categories repository.CategoryRepository) *ProductService {
+	return &ProductService{repo: r, categories: categories}
+}
+
+// SetStockNotifier registers n to hear about products whose quantity changed.
+func (s *ProductService) SetStockNotifier(n StockNotifier) {
+	s.stock = n
 }
 
 func (s *ProductService) Create(ctx context.Context, in domain.Product) (domain.Product, error) {
-	created, err := s.repo.Create(ctx, domain.Product{
-		Name:     strings.TrimSpace(in.Name),
-		Price:    in.Price,
-		Quantity: in.Quantity,
-	})
+	p, _, err := s.prepare(ctx, s.repo, 0, in)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	created, err := s.repo.Create(ctx, p)
 	if err != nil {
 		return domain.Product{}, err
 	}
+	notifyStock(ctx, s.stock, created.ID)
 	return created, nil
 }
 
@@ -36,38 +47,378 @@ func (s *ProductService) Get(ctx context.Context, id int) (domain.Product, error
 	return p, nil
 }
 
-func (s *ProductService) List(ctx context.Context, limit, offset int) ([]domain.Product, error) {
+// Lookup finds the product a scanned barcode belongs to.
+func (s *ProductService) Lookup(ctx context.Context, barcode string) (domain.Product, error) {
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return domain.Product{}, fmt.Errorf("%w: barcode is required", domain.ErrInvalid)
+	}
+
+	p, err := s.repo.GetByBarcode(ctx, barcode)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return p, nil
+}
+
+// List pages through the products, optionally narrowed to those whose name
+// or SKU contains query and to one category.
+func (s *ProductService) List(ctx context.Context, query string, categoryID, limit, offset int) ([]domain.Product, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := s.repo.List(ctx, repository.ProductListParams{
+		Query:      query,
+		CategoryID: categoryID,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListByCategories pages through the products of each of the categories
+// separately, in the order List returns them. Categories without products
+// are missing from the map.
+func (s *ProductService) ListByCategories(ctx context.Context, categoryIDs []int, limit, offset int) (map[int][]domain.Product, error) {
 	if limit <= 0 || limit > 200 {
 		limit = 50
 	}
 	if offset < 0 {
 		offset = 0
 	}
+	if len(categoryIDs) == 0 {
+		return map[int][]domain.Product{}, nil
+	}
 
-	items, err := s.repo.List(ctx, repository.ListParams{Limit: limit, Offset: offset})
+	items, err := s.repo.ListByCategories(ctx, categoryIDs, repository.ListParams{Limit: limit, Offset: offset})
 	if err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
+// Export calls fn for every product that List would return with the same
+// filters, without paging.
+func (s *ProductService) Export(ctx context.Context, query string, categoryID int, fn func(domain.Product) error) error {
+	return s.repo.Export(ctx, repository.ProductListParams{Query: query, CategoryID: categoryID}, fn)
+}
+
 func (s *ProductService) Update(ctx context.Context, id int, in domain.Product) (domain.Product, error) {
-	updated, err := s.repo.Update(ctx, id, domain.Product{
-		ID:       id,
-		Name:     strings.TrimSpace(in.Name),
-		Price:    in.Price,
-		Quantity: in.Quantity,
-	})
+	p, existing, err := s.prepare(ctx, s.repo, id, in)
 	if err != nil {
 		return domain.Product{}, err
 	}
+
+	updated, err := s.repo.Update(ctx, id, p)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	if updated.Quantity != existing.Quantity || updated.ReorderPoint != existing.ReorderPoint {
+		notifyStock(ctx, s.stock, updated.ID)
+	}
 	return updated, nil
 }
 
+// prepare validates in against the products read through products and builds
+// the product to save as product id, or as a new product when id is zero. It
+// also returns the product as it stands.
+func (s *ProductService) prepare(ctx context.Context, products repository.ProductReader, id int, in domain.Product) (domain.Product, domain.Product, error) {
+	in = normalizeCodes(in)
+	if err := validateReorder(in); err != nil {
+		return domain.Product{}, domain.Product{}, err
+	}
+	if in.CategoryID != 0 {
+		if _, err := s.categories.GetByID(ctx, in.CategoryID); err != nil {
+			return domain.Product{}, domain.Product{}, referenceError("category", in.CategoryID, err)
+		}
+	}
+	if err := validateCodes(ctx, products, id, in); err != nil {
+		return domain.Product{}, domain.Product{}, err
+	}
+
+	var existing domain.Product
+	if id != 0 {
+		var err error
+		existing, err = products.GetByID(ctx, id)
+		if err != nil {
+			return domain.Product{}, domain.Product{}, err
+		}
+	}
+	variants, err := domain.GenerateVariants(in.Options, in.Variants, existing.Variants)
+	if err != nil {
+		return domain.Product{}, domain.Product{}, err
+	}
+	if id != 0 {
+		keepStock(&in, variants, existing)
+	}
+	if len(variants) > 0 {
+		in.Quantity = domain.TotalQuantity(variants)
+	}
+
+	return domain.Product{
+		ID:              id,
+		Name:            strings.TrimSpace(in.Name),
+		SKU:             in.SKU,
+		Barcodes:        in.Barcodes,
+		CategoryID:      in.CategoryID,
+		Price:           in.Price,
+		Quantity:        in.Quantity,
+		ReorderPoint:    in.ReorderPoint,
+		ReorderQuantity: in.ReorderQuantity,
+		Options:         in.Options,
+		Variants:        variants,
+	}, existing, nil
+}
+
 func (s *ProductService) Delete(ctx context.Context, id int) error {
 	err := s.repo.Delete(ctx, id)
 	if err != nil {
 		return err
 	}
+	notifyStock(ctx, s.stock, id)
 	return nil
 }
+
+// maxBatchOps bounds the operations in one batch.
+const maxBatchOps = 1000
+
+// Batch creates, updates and deletes products in one go. Atomic batches are
+// applied only if every operation succeeds; otherwise the valid operations
+// are applied and the rest reported as failed. A product can be changed by
+// one operation of a batch only, and the operations are checked inside the
+// batch's transaction, against the products as they stood when it began.
+func (s *ProductService) Batch(ctx context.Context, ops []domain.ProductBatchOp, atomic bool) (domain.BatchResult, error) {
+	if len(ops) == 0 || len(ops) > maxBatchOps {
+		return domain.BatchResult{}, fmt.Errorf("%w: a batch needs between 1 and %d operations", domain.ErrInvalid, maxBatchOps)
+	}
+
+	res := domain.BatchResult{Atomic: atomic, Results: make([]domain.BatchItemResult, len(ops))}
+	valid := make([]domain.ProductBatchOp, 0, len(ops))
+	index := make([]int, 0, len(ops))
+	targets := make(map[int]bool)
+	for i, op := range ops {
+		res.Results[i] = domain.BatchItemResult{Index: i, Action: op.Action, ID: op.ID}
+		var err error
+		switch op.Action {
+		case domain.BatchCreate:
+			op.ID = 0
+		case domain.BatchUpdate, domain.BatchDelete:
+			switch {
+			case op.ID <= 0 && op.Action == domain.BatchDelete:
+				err = fmt.Errorf("%w: id is required", domain.ErrInvalid)
+			case op.ID > 0 && targets[op.ID]:
+				err = fmt.Errorf("%w: product %d is already changed by another operation of the batch", domain.ErrInvalid, op.ID)
+			}
+			targets[op.ID] = true
+		default:
+			err = fmt.Errorf("%w: action must be create, update or delete", domain.ErrInvalid)
+		}
+		if err != nil {
+			if err := batchError(&res.Results[i], op, err); err != nil {
+				return domain.BatchResult{}, err
+			}
+			continue
+		}
+		valid = append(valid, op)
+		index = append(index, i)
+	}
+
+	if len(valid) < len(ops) && atomic {
+		res.Failed = len(ops) - len(valid)
+		return res, nil
+	}
+
+	before := make(map[int]domain.Product)
+	claimed := make(map[string]bool)
+	check := func(ctx context.Context, products repository.ProductReader, op domain.ProductBatchOp) (domain.ProductBatchOp, error) {
+		if op.Action == domain.BatchDelete {
+			return op, nil
+		}
+		p, existing, err := s.prepare(ctx, products, op.ID, op.Product)
+		if err != nil {
+			return op, err
+		}
+		if err := claimCodes(claimed, p); err != nil {
+			return op, err
+		}
+		if op.Action == domain.BatchUpdate {
+			before[op.ID] = existing
+		}
+		op.Product = p
+		return op, nil
+	}
+
+	saved, errs, err := s.repo.ApplyBatch(ctx, valid, atomic, check)
+	if err != nil {
+		return domain.BatchResult{}, err
+	}
+	failed := false
+	for j, err := range errs {
+		if err != nil {
+			failed = true
+			// The rest of the batch may be committed by now, so even an
+			// unexpected error is reported against its operation.
+			if err := batchError(&res.Results[index[j]], valid[j], err); err != nil {
+				res.Results[index[j]].Error = err.Error()
+			}
+		}
+	}
+	for _, r := range res.Results {
+		if r.Error != "" {
+			res.Failed++
+		}
+	}
+	if failed && atomic {
+		return res, nil
+	}
+
+	res.Applied = true
+	changed := make([]int, 0, len(valid))
+	for j, op := range valid {
+		if errs[j] != nil {
+			continue
+		}
+		r := &res.Results[index[j]]
+		r.OK = true
+		res.Succeeded++
+		switch op.Action {
+		case domain.BatchDelete:
+			changed = append(changed, op.ID)
+		default:
+			p := saved[j]
+			r.ID, r.Product = p.ID, &p
+			old, ok := before[op.ID]
+			if !ok || p.Quantity != old.Quantity || p.ReorderPoint != old.ReorderPoint {
+				changed = append(changed, p.ID)
+			}
+		}
+	}
+	notifyStock(ctx, s.stock, changed...)
+	return res, nil
+}
+
+// claimCodes records the SKUs, barcodes and variant SKUs p uses, rejecting
+// any that an operation checked before it already uses: the operations are
+// all checked before any is applied, so the lookups cannot tell.
+func claimCodes(claimed map[string]bool, p domain.Product) error {
+	codes := make([]string, 0, 1+len(p.Barcodes)+len(p.Variants))
+	if p.SKU != "" {
+		codes = append(codes, fmt.Sprintf("sku %q", p.SKU))
+	}
+	for _, code := range p.Barcodes {
+		codes = append(codes, fmt.Sprintf("barcode %q", code))
+	}
+	for _, v := range p.Variants {
+		if v.SKU != "" {
+			codes = append(codes, fmt.Sprintf("variant sku %q", v.SKU))
+		}
+	}
+	for _, code := range codes {
+		if claimed[code] {
+			return fmt.Errorf("%w: %s is also used by another operation of the batch", domain.ErrDuplicate, code)
+		}
+	}
+	for _, code := range codes {
+		claimed[code] = true
+	}
+	return nil
+}
+
+// batchError records the failure of an operation in its result. Errors that
+// are not about the operation itself are returned instead.
+func batchError(r *domain.BatchItemResult, op domain.ProductBatchOp, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		r.Error = fmt.Sprintf("product %d not found", op.ID)
+	case errors.Is(err, domain.ErrInvalid), errors.Is(err, domain.ErrConflict):
+		r.Error = err.Error()
+	default:
+		return err
+	}
+	return nil
+}
+
+func normalizeCodes(in domain.Product) domain.Product {
+	in.SKU = strings.TrimSpace(in.SKU)
+	barcodes := make([]string, 0, len(in.Barcodes))
+	for _, code := range in.Barcodes {
+		barcodes = append(barcodes, strings.TrimSpace(code))
+	}
+	in.Barcodes = barcodes
+	return in
+}
+
+func validateReorder(in domain.Product) error {
+	if in.ReorderPoint < 0 || in.ReorderQuantity < 0 {
+		return fmt.Errorf("%w: reorder_point and reorder_quantity must not be negative", domain.ErrInvalid)
+	}
+	return nil
+}
+
+// keepStock gives an update the stock the product and its variants already
+// have, whatever quantities it was sent: stock is kept per location and
+// costed in layers, so it only changes through adjustments, transfers,
+// receipts and sales. Variants the update adds start with the quantity given.
+func keepStock(in *domain.Product, variants []domain.ProductVariant, existing domain.Product) {
+	in.Quantity = existing.Quantity
+	for i, v := range variants {
+		if old, ok := existing.Variant(v.ID); ok {
+			variants[i].Quantity = old.Quantity
+		}
+	}
+}
+
+// validateCodes checks barcode checksums and rejects a SKU or barcode that is
+// repeated in the request or already owned by a product other than id.
+func validateCodes(ctx context.Context, products repository.ProductReader, id int, in domain.Product) error {
+	if in.SKU != "" {
+		owner, err := products.GetBySKU(ctx, in.SKU)
+		taken, err := takenByOther(id, owner, err)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("%w: sku %q is already in use", domain.ErrDuplicate, in.SKU)
+		}
+	}
+
+	seen := make(map[string]bool, len(in.Barcodes))
+	for _, code := range in.Barcodes {
+		if err := domain.ValidateBarcode(code); err != nil {
+			return err
+		}
+		if seen[code] {
+			return fmt.Errorf("%w: barcode %q is listed twice", domain.ErrInvalid, code)
+		}
+		seen[code] = true
+
+		owner, err := products.GetByBarcode(ctx, code)
+		taken, err := takenByOther(id, owner, err)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("%w: barcode %q is already in use", domain.ErrDuplicate, code)
+		}
+	}
+	return nil
+}
+
+// takenByOther interprets a code lookup: the code is taken when it resolves
+// to a product other than id.
+func takenByOther(id int, owner domain.Product, err error) (bool, error) {
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return owner.ID != id, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository_memory"
+	"pos-api/internal/tenant"
+)
+
+func newBatchTest(t *testing.T) (context.Context, *ProductService, domain.Product) {
+	t.Helper()
+	ctx := tenant.With(context.Background(), tenant.Default)
+	outbox := repository_memory.NewOutboxRepo()
+	changes := repository_memory.NewChangeLog()
+	audit := repository_memory.NewAuditRepo()
+	products := repository_memory.NewProductRepo(audit, outbox, changes)
+	s := NewProductService(products, repository_memory.NewCategoryRepo(audit, outbox, changes))
+
+	tea, err := s.Create(ctx, domain.Product{Name: "Tea", SKU: "TEA", Price: 12000})
+	if err != nil {
+		t.Fatalf("creating product: %v", err)
+	}
+	return ctx, s, tea
+}
+
+// TestBatchChecksOperationsTogether runs a best-effort batch whose operations
+// only clash with each other: each must be caught before anything is applied.
+func TestBatchChecksOperationsTogether(t *testing.T) {
+	ctx, s, tea := newBatchTest(t)
+
+	res, err := s.Batch(ctx, []domain.ProductBatchOp{
+		{Action: domain.BatchUpdate, ID: tea.ID, Product: domain.Product{Name: "Green tea", SKU: "TEA", Price: 13000}},
+		{Action: domain.BatchDelete, ID: tea.ID},
+		{Action: domain.BatchCreate, Product: domain.Product{Name: "Coffee", SKU: "COF", Price: 15000}},
+		{Action: domain.BatchCreate, Product: domain.Product{Name: "Espresso", SKU: "COF", Price: 18000}},
+		{Action: domain.BatchCreate, Product: domain.Product{Name: "Black tea", SKU: "TEA", Price: 11000}},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantOK := []bool{true, false, true, false, false}
+	for i, r := range res.Results {
+		if r.OK != wantOK[i] {
+			t.Errorf("operation %d: ok = %v (%q), want %v", i, r.OK, r.Error, wantOK[i])
+		}
+	}
+	if !res.Applied || res.Succeeded != 2 || res.Failed != 3 {
+		t.Errorf("applied = %v, succeeded = %d, failed = %d; want true, 2, 3", res.Applied, res.Succeeded, res.Failed)
+	}
+	if p, err := s.Get(ctx, tea.ID); err != nil || p.Name != "Green tea" {
+		t.Errorf("product %d = %q, %v; want Green tea", tea.ID, p.Name, err)
+	}
+}
+
+func TestAtomicBatchAppliesNothingOnFailure(t *testing.T) {
+	ctx, s, tea := newBatchTest(t)
+
+	res, err := s.Batch(ctx, []domain.ProductBatchOp{
+		{Action: domain.BatchCreate, Product: domain.Product{Name: "Coffee", SKU: "COF", Price: 15000}},
+		{Action: domain.BatchUpdate, ID: tea.ID, Product: domain.Product{Name: "Coffee", SKU: "COF", Price: 15000}},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Applied || res.Failed != 1 {
+		t.Errorf("applied = %v, failed = %d; want false, 1", res.Applied, res.Failed)
+	}
+	if _, err := s.repo.GetBySKU(ctx, "COF"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetBySKU(COF) = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+)
+
+type PurchaseOrderService struct {
+	repo      repository.PurchaseOrderRepository
+	suppliers repository.SupplierRepository
+	products  repository.ProductRepository
+	locations repository.LocationRepository
+	stock     StockNotifier
+}
+
+func NewPurchaseOrderService(r repository.PurchaseOrderRepository, suppliers repository.SupplierRepository, products repository.ProductRepository, locations repository.LocationRepository) *PurchaseOrderService {
+	return &PurchaseOrderService{repo: r, suppliers: suppliers, products: products, locations: locations}
+}
+
+// SetStockNotifier registers n to hear about products whose quantity changed.
+func (s *PurchaseOrderService) SetStockNotifier(n StockNotifier) {
+	s.stock = n
+}
+
+func (s *PurchaseOrderService) Create(ctx context.Context, in domain.PurchaseOrder) (domain.PurchaseOrder, error) {
+	if err := s.validate(ctx, in); err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+
+	created, err := s.repo.Create(ctx, in)
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	return created, nil
+}
+
+func (s *PurchaseOrderService) Get(ctx context.Context, id int) (domain.PurchaseOrder, error) {
+	po, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	return po, nil
+}
+
+func (s *PurchaseOrderService) List(ctx context.Context, limit, offset int) ([]domain.PurchaseOrder, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := s.repo.List(ctx, repository.ListParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *PurchaseOrderService) Update(ctx context.Context, id int, in domain.PurchaseOrder) (domain.PurchaseOrder, error) {
+	if err := s.validate(ctx, in); err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+
+	updated, err := s.repo.Update(ctx, id, in)
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	return updated, nil
+}
+
+func (s *PurchaseOrderService) Submit(ctx context.Context, id int) (domain.PurchaseOrder, error) {
+	return s.repo.Transition(ctx, id, domain.PurchaseOrderSubmitted)
+}
+
+func (s *PurchaseOrderService) Cancel(ctx context.Context, id int) (domain.PurchaseOrder, error) {
+	return s.repo.Transition(ctx, id, domain.PurchaseOrderCancelled)
+}
+
+func (s *PurchaseOrderService) Receive(ctx context.Context, id int, lines []domain.ReceiveLine) (domain.PurchaseOrder, error) {
+	po, err := s.repo.Receive(ctx, id, lines)
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+
+	ids := make([]int, 0, len(po.Lines))
+	for _, l := range po.Lines {
+		ids = append(ids, l.ProductID)
+	}
+	notifyStock(ctx, s.stock, ids...)
+	return po, nil
+}
+
+// Margins compares each line's unit cost against the product's current price.
+func (s *PurchaseOrderService) Margins(ctx context.Context, id int) ([]domain.PurchaseOrderLineMargin, error) {
+	po, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]domain.PurchaseOrderLineMargin, 0, len(po.Lines))
+	for _, l := range po.Lines {
+		p, err := s.products.GetByID(ctx, l.ProductID)
+		if err != nil {
+			return nil, err
+		}
+
+		price := p.Price
+		if v, ok := p.Variant(l.VariantID); ok {
+			price = v.EffectivePrice(p.Price)
+		}
+
+		m := domain.PurchaseOrderLineMargin{
+			LineID:    l.ID,
+			ProductID: l.ProductID,
+			VariantID: l.VariantID,
+			UnitCost:  l.UnitCost,
+			Price:     price,
+			Margin:    price - l.UnitCost,
+		}
+		if price > 0 {
+			m.MarginPercent = float64(m.Margin) * 100 / float64(price)
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+func (s *PurchaseOrderService) validate(ctx context.Context, in domain.PurchaseOrder) error {
+	if _, err := s.suppliers.GetByID(ctx, in.SupplierID); err != nil {
+		return referenceError("supplier", in.SupplierID, err)
+	}
+	if in.LocationID != 0 {
+		if _, err := s.locations.GetByID(ctx, in.LocationID); err != nil {
+			return referenceError("location", in.LocationID, err)
+		}
+	}
+	if len(in.Lines) == 0 {
+		return fmt.Errorf("%w: at least one line is required", domain.ErrInvalid)
+	}
+	for i, l := range in.Lines {
+		if l.Quantity <= 0 {
+			return fmt.Errorf("%w: line %d quantity must be positive", domain.ErrInvalid, i+1)
+		}
+		if l.UnitCost < 0 {
+			return fmt.Errorf("%w: line %d unit_cost must not be negative", domain.ErrInvalid, i+1)
+		}
+		p, err := s.products.GetByID(ctx, l.ProductID)
+		if err != nil {
+			return referenceError("product", l.ProductID, err)
+		}
+		if err := checkVariant(p, l.VariantID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
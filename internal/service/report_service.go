@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxReportPeriods bounds how many rows a sales report can have, e.g. a
+// little under three years by day.
+const maxReportPeriods = 1000
+
+type ReportService struct {
+	reports    repository.ReportRepository
+	categories repository.CategoryRepository
+	settings   SettingsProvider
+}
+
+// NewReportService cuts periods in the time zone of the tenant's settings
+// and costs stock with its valuation method, unless a request asks for
+// something else.
+func NewReportService(reports repository.ReportRepository, categories repository.CategoryRepository, settings SettingsProvider) *ReportService {
+	return &ReportService{reports: reports, categories: categories, settings: settings}
+}
+
+// Sales reports revenue between from and to, which are dates (YYYY-MM-DD,
+// both inclusive) or RFC 3339 timestamps. It defaults to the last 30 days.
+func (s *ReportService) Sales(ctx context.Context, from, to, groupBy, tz string, top int) (domain.SalesReport, error) {
+	p, err := s.params(ctx, from, to, groupBy, tz)
+	if err != nil {
+		return domain.SalesReport{}, err
+	}
+	p.Top = top
+	if p.Top <= 0 || p.Top > 100 {
+		p.Top = 10
+	}
+	loc := p.Location
+
+	periods, err := s.periods(ctx, p)
+	if err != nil {
+		return domain.SalesReport{}, err
+	}
+	byRevenue, err := s.reports.TopProducts(ctx, p, false)
+	if err != nil {
+		return domain.SalesReport{}, err
+	}
+	byQuantity, err := s.reports.TopProducts(ctx, p, true)
+	if err != nil {
+		return domain.SalesReport{}, err
+	}
+	categories, err := s.reports.SalesByCategory(ctx, p)
+	if err != nil {
+		return domain.SalesReport{}, err
+	}
+
+	totals := domain.SalesPeriod{Start: p.From.In(loc)}
+	for _, period := range periods {
+		totals.OrderCount += period.OrderCount
+		totals.ItemsSold += period.ItemsSold
+		totals.Subtotal += period.Subtotal
+		totals.Tax += period.Tax
+		totals.Total += period.Total
+	}
+
+	revenue := 0
+	for _, c := range categories {
+		revenue += c.Revenue
+	}
+	for i := range categories {
+		if categories[i].CategoryID == 0 {
+			categories[i].Name = "Uncategorized"
+		}
+		if revenue != 0 {
+			categories[i].Share = math.Round(float64(categories[i].Revenue)*10000/float64(revenue)) / 100
+		}
+	}
+
+	return domain.SalesReport{
+		From:          p.From.In(loc),
+		To:            p.To.In(loc),
+		Timezone:      loc.String(),
+		GroupBy:       p.GroupBy,
+		Totals:        totals,
+		Periods:       periods,
+		TopByRevenue:  byRevenue,
+		TopByQuantity: byQuantity,
+		Categories:    categories,
+	}, nil
+}
+
+// Valuation values the stock on hand with the given method, or the default
+// one when method is empty. A non-zero categoryID limits it to one category.
+func (s *ReportService) Valuation(ctx context.Context, method string, categoryID int) (domain.InventoryValuation, error) {
+	m, err := s.valuationMethod(ctx, method)
+	if err != nil {
+		return domain.InventoryValuation{}, err
+	}
+
+	items, err := s.reports.InventoryValuation(ctx)
+	if err != nil {
+		return domain.InventoryValuation{}, err
+	}
+
+	out := domain.InventoryValuation{Method: m, Items: make([]domain.ProductValuation, 0, len(items))}
+	byCategory := make(map[int]*domain.CategoryValuation)
+	var order []int
+	for _, it := range items {
+		if categoryID != 0 && it.CategoryID != categoryID {
+			continue
+		}
+		it.Total = it.Value.Of(m)
+		if it.Quantity > 0 {
+			it.UnitCost = it.Total / it.Quantity
+		}
+		out.Items = append(out.Items, it)
+		out.Quantity += max(it.Quantity, 0)
+		out.Value += it.Total
+
+		c, ok := byCategory[it.CategoryID]
+		if !ok {
+			c = &domain.CategoryValuation{CategoryID: it.CategoryID, Name: s.categoryName(ctx, it.CategoryID)}
+			byCategory[it.CategoryID] = c
+			order = append(order, it.CategoryID)
+		}
+		c.Quantity += max(it.Quantity, 0)
+		c.Value += it.Total
+	}
+
+	out.Categories = make([]domain.CategoryValuation, 0, len(order))
+	for _, id := range order {
+		out.Categories = append(out.Categories, *byCategory[id])
+	}
+	sort.Slice(out.Categories, func(i, j int) bool { return out.Categories[i].Value > out.Categories[j].Value })
+	return out, nil
+}
+
+// COGS reports cost of goods sold and gross margin per period and per
+// category. Ranges work as in Sales.
+func (s *ReportService) COGS(ctx context.Context, from, to, groupBy, tz, method string) (domain.COGSReport, error) {
+	m, err := s.valuationMethod(ctx, method)
+	if err != nil {
+		return domain.COGSReport{}, err
+	}
+	p, err := s.params(ctx, from, to, groupBy, tz)
+	if err != nil {
+		return domain.COGSReport{}, err
+	}
+
+	starts, err := periodStarts(p)
+	if err != nil {
+		return domain.COGSReport{}, err
+	}
+	byPeriod, err := s.reports.CostOfSalesByPeriod(ctx, p)
+	if err != nil {
+		return domain.COGSReport{}, err
+	}
+	byCategory, err := s.reports.CostOfSalesByCategory(ctx, p)
+	if err != nil {
+		return domain.COGSReport{}, err
+	}
+
+	sales := make(map[int64]domain.SalesCost, len(byPeriod))
+	for _, sc := range byPeriod {
+		sales[sc.Start.Unix()] = sc
+	}
+
+	var totals domain.SalesCost
+	periods := make([]domain.Margin, 0, len(starts))
+	for _, start := range starts {
+		sc := sales[start.Unix()]
+		totals.Revenue += sc.Revenue
+		totals.Cost = totals.Cost.Add(sc.Cost)
+
+		margin := newMargin(sc.Revenue, sc.Cost.Of(m))
+		margin.Start = &start
+		periods = append(periods, margin)
+	}
+
+	categories := make([]domain.Margin, 0, len(byCategory))
+	for _, sc := range byCategory {
+		margin := newMargin(sc.Revenue, sc.Cost.Of(m))
+		id := sc.CategoryID
+		margin.CategoryID = &id
+		margin.Name = sc.Name
+		if id == 0 {
+			margin.Name = "Uncategorized"
+		}
+		categories = append(categories, margin)
+	}
+
+	return domain.COGSReport{
+		From:       p.From.In(p.Location),
+		To:         p.To.In(p.Location),
+		Timezone:   p.Location.String(),
+		GroupBy:    p.GroupBy,
+		Method:     m,
+		Totals:     newMargin(totals.Revenue, totals.Cost.Of(m)),
+		Periods:    periods,
+		Categories: categories,
+	}, nil
+}
+
+func newMargin(revenue, cogs int) domain.Margin {
+	m := domain.Margin{Revenue: revenue, COGS: cogs, GrossMargin: revenue - cogs}
+	if revenue != 0 {
+		m.MarginPercent = math.Round(float64(m.GrossMargin)*10000/float64(revenue)) / 100
+	}
+	return m
+}
+
+func (s *ReportService) valuationMethod(ctx context.Context, method string) (domain.ValuationMethod, error) {
+	if strings.TrimSpace(method) == "" {
+		set, err := s.settings.Settings(ctx)
+		if err != nil {
+			return "", err
+		}
+		return set.ValuationMethod, nil
+	}
+	return domain.ParseValuationMethod(method)
+}
+
+func (s *ReportService) categoryName(ctx context.Context, id int) string {
+	if id == 0 {
+		return "Uncategorized"
+	}
+	c, err := s.categories.GetByID(ctx, id)
+	if err != nil {
+		return "Uncategorized"
+	}
+	return c.Name
+}
+
+// params reads the range, grouping and time zone shared by the sales and
+// COGS reports.
+func (s *ReportService) params(ctx context.Context, from, to, groupBy, tz string) (domain.SalesReportParams, error) {
+	set, err := s.settings.Settings(ctx)
+	if err != nil {
+		return domain.SalesReportParams{}, err
+	}
+	loc := set.Location
+	if tz = strings.TrimSpace(tz); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return domain.SalesReportParams{}, fmt.Errorf("%w: unknown time zone %q", domain.ErrInvalid, tz)
+		}
+		loc = l
+	}
+
+	p := domain.SalesReportParams{GroupBy: domain.SalesGrouping(groupBy), Location: loc}
+	if p.GroupBy == "" {
+		p.GroupBy = domain.GroupByDay
+	}
+
+	if p.To, err = parseReportTime(to, loc, true); err != nil {
+		return domain.SalesReportParams{}, err
+	}
+	if p.To.IsZero() {
+		p.To = domain.GroupByDay.NextPeriod(domain.GroupByDay.PeriodStart(time.Now(), loc))
+	}
+	if p.From, err = parseReportTime(from, loc, false); err != nil {
+		return domain.SalesReportParams{}, err
+	}
+	if p.From.IsZero() {
+		p.From = p.To.AddDate(0, 0, -30)
+	}
+	if err := p.Validate(); err != nil {
+		return domain.SalesReportParams{}, err
+	}
+	return p, nil
+}
+
+// periods lists every period in the range, including those without sales,
+// so charts have no gaps.
+func (s *ReportService) periods(ctx context.Context, p domain.SalesReportParams) ([]domain.SalesPeriod, error) {
+	starts, err := periodStarts(p)
+	if err != nil {
+		return nil, err
+	}
+
+	sales, err := s.reports.SalesByPeriod(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	byStart := make(map[int64]domain.SalesPeriod, len(sales))
+	for _, period := range sales {
+		byStart[period.Start.Unix()] = period
+	}
+
+	out := make([]domain.SalesPeriod, 0, len(starts))
+	for _, start := range starts {
+		period := byStart[start.Unix()]
+		period.Start = start
+		out = append(out, period)
+	}
+	return out, nil
+}
+
+func periodStarts(p domain.SalesReportParams) ([]time.Time, error) {
+	var starts []time.Time
+	for start := p.GroupBy.PeriodStart(p.From, p.Location); start.Before(p.To); start = p.GroupBy.NextPeriod(start) {
+		if len(starts) == maxReportPeriods {
+			return nil, fmt.Errorf("%w: range has more than %d periods, use a larger group_by", domain.ErrInvalid, maxReportPeriods)
+		}
+		starts = append(starts, start)
+	}
+	return starts, nil
+}
+
+// parseReportTime reads a date or an RFC 3339 timestamp. A date used as the
+// end of a range includes the whole day.
+func parseReportTime(s string, loc *time.Location, end bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q is not a date (YYYY-MM-DD) or RFC 3339 time", domain.ErrInvalid, s)
+	}
+	return t, nil
+}
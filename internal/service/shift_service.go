@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"strings"
+	"time"
+)
+
+type ShiftService struct {
+	repo   repository.ShiftRepository
+	orders repository.OrderRepository
+}
+
+func NewShiftService(r repository.ShiftRepository, orders repository.OrderRepository) *ShiftService {
+	return &ShiftService{repo: r, orders: orders}
+}
+
+func (s *ShiftService) Open(ctx context.Context, in domain.Shift) (domain.Shift, error) {
+	if strings.TrimSpace(in.RegisterID) == "" {
+		return domain.Shift{}, fmt.Errorf("%w: register_id is required", domain.ErrInvalid)
+	}
+	if strings.TrimSpace(in.UserID) == "" {
+		return domain.Shift{}, fmt.Errorf("%w: user_id is required", domain.ErrInvalid)
+	}
+	if in.OpeningFloat < 0 {
+		return domain.Shift{}, fmt.Errorf("%w: opening_float must not be negative", domain.ErrInvalid)
+	}
+
+	created, err := s.repo.Open(ctx, in)
+	if err != nil {
+		return domain.Shift{}, err
+	}
+	return created, nil
+}
+
+func (s *ShiftService) Get(ctx context.Context, id int) (domain.Shift, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ShiftService) List(ctx context.Context, limit, offset int) ([]domain.Shift, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := s.repo.List(ctx, repository.ListParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *ShiftService) AddCashMovement(ctx context.Context, in domain.CashMovement) (domain.CashMovement, error) {
+	if in.Kind != domain.CashIn && in.Kind != domain.CashOut {
+		return domain.CashMovement{}, fmt.Errorf("%w: kind must be cash_in or cash_out", domain.ErrInvalid)
+	}
+	if in.Amount <= 0 {
+		return domain.CashMovement{}, fmt.Errorf("%w: amount must be positive", domain.ErrInvalid)
+	}
+
+	created, err := s.repo.AddCashMovement(ctx, in)
+	if err != nil {
+		return domain.CashMovement{}, err
+	}
+	return created, nil
+}
+
+// Close counts the drawer and ends the shift; the Z-report has the details.
+func (s *ShiftService) Close(ctx context.Context, id int, countedCash int) (domain.ShiftReport, error) {
+	if countedCash < 0 {
+		return domain.ShiftReport{}, fmt.Errorf("%w: counted_cash must not be negative", domain.ErrInvalid)
+	}
+	if _, err := s.repo.Close(ctx, id, countedCash); err != nil {
+		return domain.ShiftReport{}, err
+	}
+	return s.ZReport(ctx, id)
+}
+
+// XReport is a running total for a shift that is still open. It does not
+// close or otherwise change the shift.
+func (s *ShiftService) XReport(ctx context.Context, id int) (domain.ShiftReport, error) {
+	shift, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.ShiftReport{}, err
+	}
+	if shift.Status != domain.ShiftOpen {
+		return domain.ShiftReport{}, fmt.Errorf("%w: shift %d is closed, use the Z-report", domain.ErrConflict, id)
+	}
+	return s.report(ctx, domain.XReport, shift)
+}
+
+// ZReport is the end-of-shift report with the counted cash and variance.
+func (s *ShiftService) ZReport(ctx context.Context, id int) (domain.ShiftReport, error) {
+	shift, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.ShiftReport{}, err
+	}
+	if shift.Status != domain.ShiftClosed {
+		return domain.ShiftReport{}, fmt.Errorf("%w: shift %d is still open, close it first", domain.ErrConflict, id)
+	}
+	return s.report(ctx, domain.ZReport, shift)
+}
+
+func (s *ShiftService) report(ctx context.Context, kind domain.ShiftReportType, shift domain.Shift) (domain.ShiftReport, error) {
+	sales, err := s.orders.SalesByShift(ctx, shift.ID)
+	if err != nil {
+		return domain.ShiftReport{}, err
+	}
+	movements, err := s.repo.CashMovements(ctx, shift.ID)
+	if err != nil {
+		return domain.ShiftReport{}, err
+	}
+	expected, cashIn, cashOut := domain.ExpectedCash(shift.OpeningFloat, sales, movements)
+
+	// A closed shift reports the expected cash fixed at closing time.
+	if shift.ExpectedCash != nil {
+		expected = *shift.ExpectedCash
+	}
+
+	return domain.ShiftReport{
+		Type:         kind,
+		Shift:        shift,
+		Sales:        sales,
+		CashSales:    sales.CashSales(),
+		CashIn:       cashIn,
+		CashOut:      cashOut,
+		ExpectedCash: expected,
+		CountedCash:  shift.CountedCash,
+		Variance:     shift.Variance,
+		GeneratedAt:  time.Now().UTC(),
+	}, nil
+}
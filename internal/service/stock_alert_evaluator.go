@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"pos-api/internal/tenant"
+	"sync"
+	"time"
+)
+
+// StockNotifier is told which products of the tenant of ctx just had their
+// quantity changed.
+type StockNotifier interface {
+	Notify(ctx context.Context, productIDs ...int)
+}
+
+func notifyStock(ctx context.Context, n StockNotifier, productIDs ...int) {
+	if n != nil && len(productIDs) > 0 {
+		n.Notify(ctx, productIDs...)
+	}
+}
+
+// recentAlertLimit bounds the alerts kept per tenant for
+// GET /api/inventory/alerts.
+const recentAlertLimit = 100
+
+// StockAlertEvaluator re-checks products in the background after their
+// quantity changes and emits an alert whenever one crosses its reorder point.
+type StockAlertEvaluator struct {
+	products repository.ProductRepository
+	tenants  TenantLister
+
+	mu       sync.Mutex
+	pending  map[stockProduct]struct{}
+	low      map[stockProduct]bool
+	handlers []func(domain.StockAlert)
+	recent   map[int][]domain.StockAlert
+	wake     chan struct{}
+}
+
+// stockProduct is a product of a tenant; tenant is zero for contexts
+// without one.
+type stockProduct struct {
+	tenant, id int
+}
+
+func NewStockAlertEvaluator(products repository.ProductRepository) *StockAlertEvaluator {
+	return &StockAlertEvaluator{
+		products: products,
+		pending:  make(map[stockProduct]struct{}),
+		low:      make(map[stockProduct]bool),
+		recent:   make(map[int][]domain.StockAlert),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// SetTenants makes Run load the products already low for each tenant t
+// lists. It must be called before Run.
+func (e *StockAlertEvaluator) SetTenants(t TenantLister) {
+	e.tenants = t
+}
+
+// Subscribe registers fn to receive every alert. It must be called before Run.
+func (e *StockAlertEvaluator) Subscribe(fn func(domain.StockAlert)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers = append(e.handlers, fn)
+}
+
+// Notify queues products for evaluation. It never blocks; repeated
+// notifications for the same product are coalesced.
+func (e *StockAlertEvaluator) Notify(ctx context.Context, productIDs ...int) {
+	t := tenant.From(ctx)
+	e.mu.Lock()
+	for _, id := range productIDs {
+		e.pending[stockProduct{tenant: t, id: id}] = struct{}{}
+	}
+	e.mu.Unlock()
+
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Recent returns the latest alerts of the tenant of ctx, newest first.
+func (e *StockAlertEvaluator) Recent(ctx context.Context) []domain.StockAlert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	recent := e.recent[tenant.From(ctx)]
+	out := make([]domain.StockAlert, 0, len(recent))
+	for i := len(recent) - 1; i >= 0; i-- {
+		out = append(out, recent[i])
+	}
+	return out
+}
+
+// Run loads the products that are already low, so restarts do not re-alert,
+// then evaluates notified products until ctx is cancelled.
+func (e *StockAlertEvaluator) Run(ctx context.Context) error {
+	err := eachTenant(ctx, e.tenants, func(ctx context.Context) error {
+		t := tenant.From(ctx)
+		for offset := 0; ; offset += 200 {
+			items, err := e.products.ListLowStock(ctx, repository.ListParams{Limit: 200, Offset: offset})
+			if err != nil {
+				return err
+			}
+			e.mu.Lock()
+			for _, p := range items {
+				e.low[stockProduct{tenant: t, id: p.ID}] = true
+			}
+			e.mu.Unlock()
+			if len(items) < 200 {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.wake:
+		}
+
+		e.mu.Lock()
+		pending := make([]stockProduct, 0, len(e.pending))
+		for key := range e.pending {
+			pending = append(pending, key)
+		}
+		e.pending = make(map[stockProduct]struct{})
+		e.mu.Unlock()
+
+		for _, key := range pending {
+			e.evaluate(ctx, key)
+		}
+	}
+}
+
+func (e *StockAlertEvaluator) evaluate(ctx context.Context, key stockProduct) {
+	if key.tenant != 0 {
+		ctx = tenant.With(ctx, key.tenant)
+	}
+	p, err := e.products.GetByID(ctx, key.id)
+	if errors.Is(err, domain.ErrNotFound) {
+		e.mu.Lock()
+		delete(e.low, key)
+		e.mu.Unlock()
+		return
+	}
+	if err != nil {
+		log.Printf("stock alerts: load product %d: %v", key.id, err)
+		return
+	}
+
+	isLow := p.IsLowStock()
+	e.mu.Lock()
+	wasLow := e.low[key]
+	e.low[key] = isLow
+	if isLow == wasLow {
+		e.mu.Unlock()
+		return
+	}
+
+	alert := domain.StockAlert{
+		Kind:         domain.StockAlertRestocked,
+		ProductID:    p.ID,
+		Name:         p.Name,
+		Quantity:     p.Quantity,
+		ReorderPoint: p.ReorderPoint,
+		At:           time.Now().UTC(),
+	}
+	if isLow {
+		alert.Kind = domain.StockAlertLow
+	}
+	recent := append(e.recent[key.tenant], alert)
+	if len(recent) > recentAlertLimit {
+		recent = recent[len(recent)-recentAlertLimit:]
+	}
+	e.recent[key.tenant] = recent
+	handlers := append([]func(domain.StockAlert){}, e.handlers...)
+	e.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(alert)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"strings"
+)
+
+type SupplierService struct {
+	repo repository.SupplierRepository
+}
+
+func NewSupplierService(r repository.SupplierRepository) *SupplierService {
+	return &SupplierService{repo: r}
+}
+
+func (s *SupplierService) Create(ctx context.Context, in domain.Supplier) (domain.Supplier, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		return domain.Supplier{}, fmt.Errorf("%w: name is required", domain.ErrInvalid)
+	}
+
+	created, err := s.repo.Create(ctx, in)
+	if err != nil {
+		return domain.Supplier{}, err
+	}
+	return created, nil
+}
+
+func (s *SupplierService) Get(ctx context.Context, id int) (domain.Supplier, error) {
+	sup, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Supplier{}, err
+	}
+	return sup, nil
+}
+
+func (s *SupplierService) List(ctx context.Context, limit, offset int) ([]domain.Supplier, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := s.repo.List(ctx, repository.ListParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *SupplierService) Update(ctx context.Context, id int, in domain.Supplier) (domain.Supplier, error) {
+	in.ID = id
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		return domain.Supplier{}, fmt.Errorf("%w: name is required", domain.ErrInvalid)
+	}
+
+	updated, err := s.repo.Update(ctx, id, in)
+	if err != nil {
+		return domain.Supplier{}, err
+	}
+	return updated, nil
+}
+
+func (s *SupplierService) Delete(ctx context.Context, id int) error {
+	err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"strings"
+)
+
+// SyncMaxOrders is the most offline sales accepted in one push.
+const SyncMaxOrders = 500
+
+type SyncService struct {
+	repo   repository.SyncRepository
+	orders *OrderService
+}
+
+func NewSyncService(r repository.SyncRepository, orders *OrderService) *SyncService {
+	return &SyncService{repo: r, orders: orders}
+}
+
+// Changes returns the catalog changes after the since watermark, up to
+// limit of them; zero since pulls the whole catalog.
+func (s *SyncService) Changes(ctx context.Context, since, limit int) (domain.CatalogChanges, error) {
+	if since < 0 {
+		return domain.CatalogChanges{}, fmt.Errorf("%w: since must not be negative", domain.ErrInvalid)
+	}
+	if limit <= 0 || limit > 1000 {
+		limit = 500
+	}
+	return s.repo.Changes(ctx, since, limit)
+}
+
+// PushOrders records sales rung up while a terminal was offline, in the
+// order given, with the times and prices the terminal recorded. Each needs a
+// client_id; a sale whose client_id is already recorded is reported as a
+// duplicate rather than recorded again, so a terminal can safely push the
+// same queue twice. Sales that fail are
+// reported and skipped; only an unexpected error stops the push, and the
+// terminal should then push the whole queue again.
+func (s *SyncService) PushOrders(ctx context.Context, orders []domain.Order) ([]domain.SyncOrderResult, error) {
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("%w: at least one order is required", domain.ErrInvalid)
+	}
+	if len(orders) > SyncMaxOrders {
+		return nil, fmt.Errorf("%w: at most %d orders can be pushed at once", domain.ErrInvalid, SyncMaxOrders)
+	}
+	for i, o := range orders {
+		if strings.TrimSpace(o.ClientID) == "" {
+			return nil, fmt.Errorf("%w: order %d: client_id is required", domain.ErrInvalid, i+1)
+		}
+	}
+
+	results := make([]domain.SyncOrderResult, 0, len(orders))
+	for _, o := range orders {
+		res, err := s.pushOrder(ctx, o)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func (s *SyncService) pushOrder(ctx context.Context, o domain.Order) (domain.SyncOrderResult, error) {
+	res := domain.SyncOrderResult{ClientID: strings.TrimSpace(o.ClientID)}
+	existing, err := s.orders.GetByClientID(ctx, res.ClientID)
+	if err == nil {
+		res.Status, res.Order = domain.SyncDuplicate, &existing
+		return res, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return domain.SyncOrderResult{}, err
+	}
+
+	created, err := s.orders.CreateOffline(ctx, o)
+	switch {
+	case err == nil:
+		res.Status, res.Order = domain.SyncApplied, &created
+		if short := shortfalls(created); short != "" {
+			res.Status, res.Error = domain.SyncConflict, short
+		}
+	case errors.Is(err, domain.ErrConflict):
+		// Another push of the same sale may have won the race.
+		if existing, lookupErr := s.orders.GetByClientID(ctx, res.ClientID); lookupErr == nil {
+			res.Status, res.Order = domain.SyncDuplicate, &existing
+			return res, nil
+		}
+		res.Status, res.Error = domain.SyncConflict, err.Error()
+	case errors.Is(err, domain.ErrInvalid), errors.Is(err, domain.ErrNotFound):
+		res.Status, res.Error = domain.SyncRejected, err.Error()
+	default:
+		return domain.SyncOrderResult{}, err
+	}
+	return res, nil
+}
+
+// shortfalls describes the items of o sold beyond the stock on hand, or
+// returns "" if there were none.
+func shortfalls(o domain.Order) string {
+	var parts []string
+	for _, it := range o.Items {
+		if it.Shortfall > 0 {
+			parts = append(parts, fmt.Sprintf("%d of %s", it.Shortfall, it.Name))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "recorded with more sold than in stock: " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository_memory"
+	"pos-api/internal/tenant"
+)
+
+type fixedSettings domain.Settings
+
+func (s fixedSettings) Settings(ctx context.Context) (domain.Settings, error) {
+	return domain.Settings(s), nil
+}
+
+type syncTest struct {
+	ctx      context.Context
+	sync     *SyncService
+	shifts   *ShiftService
+	products *repository_memory.ProductRepo
+	product  domain.Product
+}
+
+// newSyncTest stocks two of one product, priced at 12000.
+func newSyncTest(t *testing.T) *syncTest {
+	t.Helper()
+	ctx := tenant.With(context.Background(), tenant.Default)
+	outbox := repository_memory.NewOutboxRepo()
+	changes := repository_memory.NewChangeLog()
+	products := repository_memory.NewProductRepo(repository_memory.NewAuditRepo(), outbox, changes)
+	shifts := repository_memory.NewShiftRepo()
+	orders := repository_memory.NewOrderRepo(products, shifts)
+	orderService := NewOrderService(orders, products, shifts, repository_memory.NewLocationRepo(), fixedSettings{})
+
+	p, err := products.Create(ctx, domain.Product{Name: "Tea", SKU: "TEA", Price: 12000, Quantity: 2})
+	if err != nil {
+		t.Fatalf("creating product: %v", err)
+	}
+	return &syncTest{
+		ctx:      ctx,
+		sync:     NewSyncService(nil, orderService),
+		shifts:   NewShiftService(shifts, orders),
+		products: products,
+		product:  p,
+	}
+}
+
+func (st *syncTest) push(t *testing.T, o domain.Order) domain.SyncOrderResult {
+	t.Helper()
+	results, err := st.sync.PushOrders(st.ctx, []domain.Order{o})
+	if err != nil {
+		t.Fatalf("PushOrders: %v", err)
+	}
+	return results[0]
+}
+
+func (st *syncTest) sale(clientID string, at time.Time, quantity, unitPrice int) domain.Order {
+	return domain.Order{
+		ClientID:  clientID,
+		CreatedAt: at,
+		Items:     []domain.OrderItem{{ProductID: st.product.ID, Quantity: quantity, UnitPrice: unitPrice}},
+		Tenders:   []domain.Tender{{Method: domain.TenderCash, Amount: quantity * unitPrice}},
+	}
+}
+
+func TestPushOrdersKeepsTimeAndPrices(t *testing.T) {
+	st := newSyncTest(t)
+	at := time.Now().Add(-2 * time.Hour).Truncate(time.Second).UTC()
+
+	res := st.push(t, st.sale("t1-1", at, 2, 10000))
+	if res.Status != domain.SyncApplied || res.Order == nil {
+		t.Fatalf("result = %+v, want applied", res)
+	}
+	o := res.Order
+	if !o.CreatedAt.Equal(at) {
+		t.Errorf("created_at = %v, want %v", o.CreatedAt, at)
+	}
+	if it := o.Items[0]; it.UnitPrice != 10000 || it.Name != "Tea" || o.Total != 20000 {
+		t.Errorf("item %+v with total %d, want the terminal's price of 10000 and a total of 20000", it, o.Total)
+	}
+}
+
+func TestPushOrdersRecordsShortfallsAsConflicts(t *testing.T) {
+	st := newSyncTest(t)
+
+	res := st.push(t, st.sale("t1-1", time.Now().Add(-time.Hour), 3, 12000))
+	if res.Status != domain.SyncConflict || res.Order == nil || res.Error == "" {
+		t.Fatalf("result = %+v, want a recorded conflict", res)
+	}
+	if short := res.Order.Items[0].Shortfall; short != 1 {
+		t.Errorf("shortfall = %d, want 1", short)
+	}
+	p, err := st.products.GetByID(st.ctx, st.product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Quantity != 0 {
+		t.Errorf("quantity = %d, want 0", p.Quantity)
+	}
+
+	if again := st.push(t, st.sale("t1-1", time.Now(), 3, 12000)); again.Status != domain.SyncDuplicate {
+		t.Errorf("pushing again: status = %s, want duplicate", again.Status)
+	}
+}
+
+func TestPushOrdersToClosedShift(t *testing.T) {
+	st := newSyncTest(t)
+	shift, err := st.shifts.Open(st.ctx, domain.Shift{RegisterID: "R1", UserID: "kasir"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	during := time.Now()
+	if _, err := st.shifts.Close(st.ctx, shift.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	sale := st.sale("t1-1", during, 1, 12000)
+	sale.ShiftID = shift.ID
+	if res := st.push(t, sale); res.Status != domain.SyncApplied || res.Order.RegisterID != "R1" {
+		t.Errorf("sale during the shift: result = %+v, want applied to register R1", res)
+	}
+
+	before := st.sale("t1-2", shift.OpenedAt.Add(-time.Minute), 1, 12000)
+	before.ShiftID = shift.ID
+	if res := st.push(t, before); res.Status != domain.SyncConflict || res.Order != nil {
+		t.Errorf("sale before the shift: result = %+v, want an unrecorded conflict", res)
+	}
+}
+
+func TestPushOrdersRejectsTimesOutsideWindow(t *testing.T) {
+	st := newSyncTest(t)
+	tests := map[string]time.Time{
+		"missing": {},
+		"future":  time.Now().Add(OfflineClockSkew + time.Minute),
+		"too old": time.Now().Add(-OfflineSaleMaxAge - time.Hour),
+	}
+	for name, at := range tests {
+		t.Run(name, func(t *testing.T) {
+			if res := st.push(t, st.sale("t1-"+name, at, 1, 12000)); res.Status != domain.SyncRejected {
+				t.Errorf("result = %+v, want rejected", res)
+			}
+		})
+	}
+}
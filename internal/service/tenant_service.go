@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pos-api/internal/auth"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"pos-api/internal/tenant"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tenantCacheTTL is how long a tenant looked up for a request is reused. A
+// change made through another instance of the API takes up to this long to
+// be seen here.
+const tenantCacheTTL = 30 * time.Second
+
+var tenantSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// TenantService provisions tenants, resolves the tenant a request names and
+// gives the settings in effect for it.
+type TenantService struct {
+	repo     repository.TenantRepository
+	defaults domain.Settings
+
+	mu    sync.Mutex
+	byID  map[int]cachedTenant
+	slugs map[string]int
+}
+
+type cachedTenant struct {
+	t       domain.Tenant
+	expires time.Time
+}
+
+// NewTenantService returns a service whose tenants fall back to defaults,
+// the deployment's configuration, for the settings they leave unset.
+func NewTenantService(r repository.TenantRepository, defaults domain.Settings) *TenantService {
+	return &TenantService{
+		repo:     r,
+		defaults: defaults,
+		byID:     make(map[int]cachedTenant),
+		slugs:    make(map[string]int),
+	}
+}
+
+// Create provisions a tenant. Provisioning is for the operator of the
+// deployment, so Create, Get, List and Update need an API key with the
+// tenants scopes even when the server lets requests without a key through.
+func (s *TenantService) Create(ctx context.Context, in domain.Tenant) (domain.Tenant, error) {
+	if err := auth.RequireKey(ctx, "tenants:write"); err != nil {
+		return domain.Tenant{}, err
+	}
+	in, err := validateTenant(in)
+	if err != nil {
+		return domain.Tenant{}, err
+	}
+	created, err := s.repo.Create(ctx, in)
+	if err != nil {
+		return domain.Tenant{}, err
+	}
+	s.forget()
+	return created, nil
+}
+
+func (s *TenantService) Get(ctx context.Context, id int) (domain.Tenant, error) {
+	if err := auth.RequireKey(ctx, "tenants:read"); err != nil {
+		return domain.Tenant{}, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *TenantService) List(ctx context.Context, limit, offset int) ([]domain.Tenant, error) {
+	if err := auth.RequireKey(ctx, "tenants:read"); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, repository.ListParams{Limit: limit, Offset: offset})
+}
+
+func (s *TenantService) Update(ctx context.Context, id int, in domain.Tenant) (domain.Tenant, error) {
+	if err := auth.RequireKey(ctx, "tenants:write"); err != nil {
+		return domain.Tenant{}, err
+	}
+	in, err := validateTenant(in)
+	if err != nil {
+		return domain.Tenant{}, err
+	}
+	updated, err := s.repo.Update(ctx, id, in)
+	if err != nil {
+		return domain.Tenant{}, err
+	}
+	s.forget()
+	return updated, nil
+}
+
+// Resolve returns the ID of the tenant ref names, by ID or by slug. It
+// fails with ErrNotFound for a tenant that does not exist and ErrForbidden
+// for one that is suspended.
+func (s *TenantService) Resolve(ctx context.Context, ref string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	var t domain.Tenant
+	var err error
+	if id, convErr := strconv.Atoi(ref); convErr == nil {
+		t, err = s.lookup(ctx, id, "")
+	} else {
+		t, err = s.lookup(ctx, 0, strings.ToLower(ref))
+	}
+	if errors.Is(err, domain.ErrNotFound) {
+		return 0, fmt.Errorf("%w: tenant %q does not exist", domain.ErrNotFound, ref)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if t.Suspended {
+		return 0, fmt.Errorf("%w: tenant %q is suspended", domain.ErrForbidden, ref)
+	}
+	return t.ID, nil
+}
+
+// Settings returns the settings in effect for the tenant of ctx: its own,
+// over the deployment's. A context without a tenant gets the deployment's.
+func (s *TenantService) Settings(ctx context.Context) (domain.Settings, error) {
+	id := tenant.From(ctx)
+	if id == 0 {
+		return s.defaults, nil
+	}
+	t, err := s.lookup(ctx, id, "")
+	if err != nil {
+		return domain.Settings{}, err
+	}
+	return t.Settings.Over(s.defaults)
+}
+
+// ActiveIDs returns the IDs of the tenants that are not suspended.
+func (s *TenantService) ActiveIDs(ctx context.Context) ([]int, error) {
+	return s.repo.ListActiveIDs(ctx)
+}
+
+// lookup returns the tenant with the given slug or, when slug is empty, ID,
+// from the cache while it is fresh.
+func (s *TenantService) lookup(ctx context.Context, id int, slug string) (domain.Tenant, error) {
+	now := time.Now()
+	s.mu.Lock()
+	cached := id
+	if slug != "" {
+		cached = s.slugs[slug]
+	}
+	c, ok := s.byID[cached]
+	s.mu.Unlock()
+	if ok && now.Before(c.expires) && (slug == "" || c.t.Slug == slug) {
+		return c.t, nil
+	}
+
+	var t domain.Tenant
+	var err error
+	if slug != "" {
+		t, err = s.repo.GetBySlug(ctx, slug)
+	} else {
+		t, err = s.repo.GetByID(ctx, id)
+	}
+	if err != nil {
+		return domain.Tenant{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byID[t.ID] = cachedTenant{t: t, expires: now.Add(tenantCacheTTL)}
+	s.slugs[t.Slug] = t.ID
+	return t, nil
+}
+
+// forget empties the cache after a change, so this instance sees it at once.
+func (s *TenantService) forget() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byID = make(map[int]cachedTenant)
+	s.slugs = make(map[string]int)
+}
+
+func validateTenant(in domain.Tenant) (domain.Tenant, error) {
+	in.Slug = strings.ToLower(strings.TrimSpace(in.Slug))
+	in.Name = strings.TrimSpace(in.Name)
+	if !tenantSlugPattern.MatchString(in.Slug) {
+		return in, fmt.Errorf("%w: slug must be 1 to 63 lower-case letters, digits and hyphens, starting with a letter or digit", domain.ErrInvalid)
+	}
+	if _, err := strconv.Atoi(in.Slug); err == nil {
+		return in, fmt.Errorf("%w: slug must not be a number, as tenants are also named by ID", domain.ErrInvalid)
+	}
+	if in.Name == "" {
+		return in, fmt.Errorf("%w: name is required", domain.ErrInvalid)
+	}
+
+	set := &in.Settings
+	if set.TaxRate != nil && (*set.TaxRate < 0 || *set.TaxRate > 100) {
+		return in, fmt.Errorf("%w: tax_rate must be between 0 and 100", domain.ErrInvalid)
+	}
+	set.Timezone = strings.TrimSpace(set.Timezone)
+	if set.Timezone != "" {
+		if _, err := time.LoadLocation(set.Timezone); err != nil {
+			return in, fmt.Errorf("%w: unknown timezone %q", domain.ErrInvalid, set.Timezone)
+		}
+	}
+	if set.ValuationMethod != "" {
+		m, err := domain.ParseValuationMethod(string(set.ValuationMethod))
+		if err != nil {
+			return in, fmt.Errorf("%w: valuation_method must be fifo, average or last", domain.ErrInvalid)
+		}
+		set.ValuationMethod = m
+	}
+	return in, nil
+}
+
+// SettingsProvider gives the settings in effect for the tenant of a context.
+type SettingsProvider interface {
+	Settings(ctx context.Context) (domain.Settings, error)
+}
+
+// TenantLister lists the tenants that background work is done for.
+type TenantLister interface {
+	ActiveIDs(ctx context.Context) ([]int, error)
+}
+
+// eachTenant calls fn with ctx scoped to each tenant tenants lists, or once
+// with ctx as it is when tenants is nil. A tenant whose fn fails does not
+// stop the others; the errors are returned joined.
+func eachTenant(ctx context.Context, tenants TenantLister, fn func(ctx context.Context) error) error {
+	if tenants == nil {
+		return fn(ctx)
+	}
+	ids, err := tenants.ActiveIDs(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := fn(tenant.With(ctx, id)); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %d: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
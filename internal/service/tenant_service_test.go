@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pos-api/internal/auth"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository_memory"
+	"pos-api/internal/tenant"
+)
+
+func TestTenantProvisioningNeedsAKey(t *testing.T) {
+	s := NewTenantService(repository_memory.NewTenantRepo(), domain.Settings{})
+	anonymous := context.Background()
+	reader := auth.With(anonymous, domain.APIKey{ID: 1, TenantID: tenant.Default, Scopes: []string{"tenants:read"}})
+	operator := auth.With(anonymous, domain.APIKey{ID: 2, TenantID: tenant.Default, Scopes: []string{"tenants:write"}})
+
+	if _, err := s.List(anonymous, 0, 0); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("List without a key = %v, want ErrUnauthorized", err)
+	}
+	if _, err := s.Create(anonymous, domain.Tenant{Slug: "acme", Name: "Acme"}); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("Create without a key = %v, want ErrUnauthorized", err)
+	}
+	if _, err := s.Update(anonymous, tenant.Default, domain.Tenant{Slug: "default", Name: "Default", Suspended: true}); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("Update without a key = %v, want ErrUnauthorized", err)
+	}
+	if _, err := s.Create(reader, domain.Tenant{Slug: "acme", Name: "Acme"}); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Create with tenants:read = %v, want ErrForbidden", err)
+	}
+
+	created, err := s.Create(operator, domain.Tenant{Slug: "acme", Name: "Acme"})
+	if err != nil {
+		t.Fatalf("Create with tenants:write: %v", err)
+	}
+	if _, err := s.Get(reader, created.ID); err != nil {
+		t.Errorf("Get with tenants:read: %v", err)
+	}
+
+	// Resolving the tenant of a request is not provisioning.
+	if id, err := s.Resolve(anonymous, "acme"); err != nil || id != created.ID {
+		t.Errorf("Resolve = %d, %v; want %d", id, err, created.ID)
+	}
+}
+
+func TestTenantScopesNeedAKeyToGive(t *testing.T) {
+	s := NewAPIKeyService(repository_memory.NewAPIKeyRepo())
+	anonymous := tenant.With(context.Background(), tenant.Default)
+	operator := auth.With(anonymous, domain.APIKey{ID: 1, TenantID: tenant.Default, Scopes: []string{"tenants:write", "api-keys:write"}})
+
+	if _, err := s.Create(anonymous, domain.APIKey{Name: "ops", Scopes: []string{"tenants:read"}}); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("creating a tenants key without a key = %v, want ErrUnauthorized", err)
+	}
+	if _, err := s.Create(anonymous, domain.APIKey{Name: "sync", Scopes: []string{"products:read"}}); err != nil {
+		t.Errorf("creating a products key without a key: %v", err)
+	}
+	if _, err := s.Create(operator, domain.APIKey{Name: "ops", Scopes: []string{"tenants:read"}}); err != nil {
+		t.Errorf("creating a tenants key with tenants:write: %v", err)
+	}
+}
@@ -0,0 +1,415 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Headers sent with every webhook delivery.
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookEventHeader     = "X-Webhook-Event"
+)
+
+const (
+	// webhookMaxAttempts is how many times a delivery is tried before it is
+	// dead-lettered.
+	webhookMaxAttempts = 8
+	// webhookRetryBase is the wait after the first failure; it doubles after
+	// each further one, up to webhookRetryMax.
+	webhookRetryBase = 10 * time.Second
+	webhookRetryMax  = time.Hour
+	// webhookLease is how long a claimed delivery is hidden from other
+	// dispatchers. It must outlast the client timeout.
+	webhookLease = time.Minute
+	// webhookPollInterval is the longest the dispatcher sleeps.
+	webhookPollInterval = 5 * time.Second
+	// webhookBatch bounds the deliveries sent at once.
+	webhookBatch = 20
+)
+
+// nonPublicPrefixes are the ranges, besides loopback, private, link-local,
+// multicast and unspecified addresses, that webhooks may not reach: this
+// network, shared address space (where some clouds serve metadata), IETF
+// protocol assignments, benchmarking and reserved.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// publicAddress reports whether ip is an internet address rather than one
+// of this host, its network or the cloud metadata service.
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// newWebhookClient returns a client that only connects to public addresses.
+// The check runs on the address being dialled, after the name is resolved,
+// so a host that resolves differently at delivery time cannot get past it.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddress(ap.Addr()) {
+				return fmt.Errorf("webhook address %s is not public", ap.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+type WebhookService struct {
+	repo    repository.WebhookRepository
+	client  *http.Client
+	tenants TenantLister
+	wake    chan struct{}
+	// publicOnly makes subscriptions resolve to public addresses.
+	publicOnly bool
+}
+
+// NewWebhookService sends deliveries with client, or, when it is nil, with
+// a client that times out after 10 seconds and only connects to public
+// addresses; subscription URLs must then resolve to public addresses too.
+// Redirects are never followed: a 3xx response is a failed delivery.
+func NewWebhookService(r repository.WebhookRepository, client *http.Client) *WebhookService {
+	publicOnly := client == nil
+	if client == nil {
+		client = newWebhookClient()
+	}
+	c := *client
+	c.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &WebhookService{
+		repo:       r,
+		client:     &c,
+		wake:       make(chan struct{}, 1),
+		publicOnly: publicOnly,
+	}
+}
+
+// WebhookSignature is the value of the signature header for a delivery of
+// body at timestamp: "sha256=" and the hex HMAC-SHA256, keyed with the
+// subscription secret, of the timestamp, a dot and the body.
+func WebhookSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetTenants makes Run send the deliveries of each tenant t lists. It must
+// be called before Run.
+func (s *WebhookService) SetTenants(t TenantLister) {
+	s.tenants = t
+}
+
+// Create adds a subscription. A random secret is generated when none is
+// given; the result is the only place it is returned.
+func (s *WebhookService) Create(ctx context.Context, in domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	in, err := s.validateWebhook(ctx, in)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	if in.Secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return domain.WebhookSubscription{}, err
+		}
+		in.Secret = hex.EncodeToString(b)
+	}
+	return s.repo.Create(ctx, in)
+}
+
+func (s *WebhookService) Get(ctx context.Context, id int) (domain.WebhookSubscription, error) {
+	sub, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+func (s *WebhookService) List(ctx context.Context, limit, offset int) ([]domain.WebhookSubscription, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := s.repo.List(ctx, repository.ListParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Secret = ""
+	}
+	return items, nil
+}
+
+// Update replaces a subscription; an empty secret keeps the current one.
+func (s *WebhookService) Update(ctx context.Context, id int, in domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	in, err := s.validateWebhook(ctx, in)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	updated, err := s.repo.Update(ctx, id, in)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	updated.Secret = ""
+	return updated, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *WebhookService) validateWebhook(ctx context.Context, in domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	in.URL = strings.TrimSpace(in.URL)
+	u, err := url.Parse(in.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return in, fmt.Errorf("%w: url must be an absolute http or https URL", domain.ErrInvalid)
+	}
+	if s.publicOnly {
+		if err := checkWebhookHost(ctx, u.Hostname()); err != nil {
+			return in, err
+		}
+	}
+
+	types := make([]domain.EventType, 0, len(in.EventTypes))
+	for _, t := range in.EventTypes {
+		if !slices.Contains(domain.EventTypes, t) {
+			return in, fmt.Errorf("%w: unknown event type %q", domain.ErrInvalid, t)
+		}
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	in.EventTypes = types
+	in.Secret = strings.TrimSpace(in.Secret)
+	return in, nil
+}
+
+// checkWebhookHost resolves host and rejects it unless every address it has
+// is public. Deliveries check the address again when they connect.
+func checkWebhookHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: url host %q does not resolve", domain.ErrInvalid, host)
+	}
+	for _, ip := range addrs {
+		if !publicAddress(ip) {
+			return fmt.Errorf("%w: url host %q is not a public address", domain.ErrInvalid, host)
+		}
+	}
+	return nil
+}
+
+// Deliveries lists the deliveries of a subscription, newest first,
+// optionally only those with the given status.
+func (s *WebhookService) Deliveries(ctx context.Context, subscriptionID int, status string, limit, offset int) ([]domain.WebhookDelivery, error) {
+	switch domain.WebhookDeliveryStatus(status) {
+	case "", domain.WebhookPending, domain.WebhookSucceeded, domain.WebhookDead:
+	default:
+		return nil, fmt.Errorf("%w: status must be pending, succeeded or dead", domain.ErrInvalid)
+	}
+	if _, err := s.repo.GetByID(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repo.Deliveries(ctx, repository.WebhookDeliveryListParams{
+		SubscriptionID: subscriptionID,
+		Status:         status,
+		Limit:          limit,
+		Offset:         offset,
+	})
+}
+
+// Redeliver queues a delivery to be sent again, as a new delivery.
+func (s *WebhookService) Redeliver(ctx context.Context, subscriptionID, id int) (domain.WebhookDelivery, error) {
+	d, err := s.repo.Redeliver(ctx, subscriptionID, id)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	s.notify()
+	return d, nil
+}
+
+// HandleEvent queues deliveries of e for the subscriptions that want it. It
+// is meant to be subscribed to the event bus.
+func (s *WebhookService) HandleEvent(ctx context.Context, e domain.Event) error {
+	n, err := s.repo.Enqueue(ctx, e)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		s.notify()
+	}
+	return nil
+}
+
+func (s *WebhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run sends due deliveries until ctx is cancelled.
+func (s *WebhookService) Run(ctx context.Context) error {
+	for {
+		err := eachTenant(ctx, s.tenants, func(ctx context.Context) error {
+			for {
+				due, err := s.repo.ClaimDue(ctx, time.Now(), webhookLease, webhookBatch)
+				if err != nil {
+					return err
+				}
+
+				var wg sync.WaitGroup
+				for _, d := range due {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						s.deliver(ctx, d)
+					}()
+				}
+				wg.Wait()
+
+				if len(due) < webhookBatch {
+					return nil
+				}
+			}
+		})
+		if err != nil {
+			log.Printf("webhooks: %v", err)
+		}
+
+		timer := time.NewTimer(webhookPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// deliver sends d once and records the outcome: succeeded on a 2xx response,
+// otherwise pending with a backoff, or dead after the last attempt.
+func (s *WebhookService) deliver(ctx context.Context, d domain.WebhookDelivery) {
+	sub, err := s.repo.GetByID(ctx, d.SubscriptionID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		log.Printf("webhooks: delivery %d: %v", d.ID, err)
+		return
+	}
+
+	d.Attempts++
+	d.LastStatusCode = 0
+	if sub.Disabled {
+		err = errors.New("webhook is disabled")
+		d.Attempts = webhookMaxAttempts
+	} else {
+		d.LastStatusCode, err = s.send(ctx, sub, d)
+	}
+
+	now := time.Now().UTC()
+	switch {
+	case err == nil:
+		d.Status = domain.WebhookSucceeded
+		d.LastError = ""
+		d.DeliveredAt = &now
+	case d.Attempts >= webhookMaxAttempts:
+		d.Status = domain.WebhookDead
+		d.LastError = err.Error()
+	default:
+		d.LastError = err.Error()
+		d.NextAttemptAt = now.Add(min(webhookRetryMax, webhookRetryBase<<(d.Attempts-1)))
+	}
+	if err := s.repo.SaveAttempt(ctx, d); err != nil {
+		log.Printf("webhooks: delivery %d: %v", d.ID, err)
+	}
+}
+
+func (s *WebhookService) send(ctx context.Context, sub domain.WebhookSubscription, d domain.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pos-api-webhooks")
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(d.ID))
+	req.Header.Set(WebhookEventHeader, string(d.EventType))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, WebhookSignature(sub.Secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Only the status is kept: the body is the receiver's and may echo
+	// whatever it holds.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, errors.New(resp.Status)
+	}
+	return resp.StatusCode, nil
+}
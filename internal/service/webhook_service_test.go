@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"pos-api/internal/domain"
+	"pos-api/internal/repository"
+	"pos-api/internal/repository_memory"
+	"pos-api/internal/tenant"
+)
+
+// receiver is a webhook endpoint that answers with the given status codes in
+// turn, repeating the last one, and keeps the requests it got.
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	rc := &receiver{statuses: statuses}
+	rc.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rc.mu.Lock()
+		rc.requests = append(rc.requests, receivedWebhook{header: r.Header.Clone(), body: body})
+		status := rc.statuses[min(len(rc.requests), len(rc.statuses))-1]
+		rc.mu.Unlock()
+		w.WriteHeader(status)
+		io.WriteString(w, http.StatusText(status))
+	}))
+	t.Cleanup(rc.Close)
+	return rc
+}
+
+func (rc *receiver) received() []receivedWebhook {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]receivedWebhook{}, rc.requests...)
+}
+
+// newWebhookTest subscribes rc to product updates and queues one delivery.
+func newWebhookTest(t *testing.T, rc *receiver) (context.Context, *WebhookService, *repository_memory.WebhookRepo) {
+	t.Helper()
+	ctx := tenant.With(context.Background(), tenant.Default)
+	repo := repository_memory.NewWebhookRepo()
+	s := NewWebhookService(repo, rc.Client())
+
+	_, err := s.Create(ctx, domain.WebhookSubscription{
+		URL:        rc.URL,
+		EventTypes: []domain.EventType{domain.EventProductUpdated},
+		Secret:     "s3cret",
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	data, _ := json.Marshal(domain.ProductEvent{Product: domain.Product{ID: 7, Name: "Tea", Price: 12000}})
+	err = s.HandleEvent(ctx, domain.Event{ID: 1, Type: domain.EventProductUpdated, EntityID: 7, Data: data})
+	if err != nil {
+		t.Fatalf("HandleEvent: %v", err)
+	}
+	return ctx, s, repo
+}
+
+// attempt claims the one due delivery, whenever it is due, and sends it.
+func attempt(t *testing.T, ctx context.Context, s *WebhookService, repo *repository_memory.WebhookRepo) domain.WebhookDelivery {
+	t.Helper()
+	due, err := repo.ClaimDue(ctx, time.Now().Add(48*time.Hour), webhookLease, webhookBatch)
+	if err != nil {
+		t.Fatalf("ClaimDue: %v", err)
+	}
+	if len(due) != 1 {
+		t.Fatalf("got %d due deliveries, want 1", len(due))
+	}
+	s.deliver(ctx, due[0])
+	return lastDelivery(t, ctx, repo)
+}
+
+func lastDelivery(t *testing.T, ctx context.Context, repo *repository_memory.WebhookRepo) domain.WebhookDelivery {
+	t.Helper()
+	items, err := repo.Deliveries(ctx, repository.WebhookDeliveryListParams{SubscriptionID: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("Deliveries: %v", err)
+	}
+	if len(items) == 0 {
+		t.Fatal("no deliveries logged")
+	}
+	return items[0]
+}
+
+func TestWebhookDeliveryIsSigned(t *testing.T) {
+	rc := newReceiver(t, http.StatusNoContent)
+	ctx, s, repo := newWebhookTest(t, rc)
+
+	d := attempt(t, ctx, s, repo)
+	if d.Status != domain.WebhookSucceeded || d.Attempts != 1 || d.DeliveredAt == nil || d.LastStatusCode != http.StatusNoContent {
+		t.Fatalf("delivery = %+v, want succeeded on the first attempt", d)
+	}
+
+	got := rc.received()
+	if len(got) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(got))
+	}
+	req := got[0]
+	if ct := req.header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if e := req.header.Get(WebhookEventHeader); e != string(domain.EventProductUpdated) {
+		t.Errorf("%s = %q", WebhookEventHeader, e)
+	}
+	if id := req.header.Get(WebhookDeliveryHeader); id != "1" {
+		t.Errorf("%s = %q, want 1", WebhookDeliveryHeader, id)
+	}
+
+	ts := req.header.Get(WebhookTimestampHeader)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(ts + "." + string(req.body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if sig := req.header.Get(WebhookSignatureHeader); sig != want {
+		t.Errorf("%s = %q, want %q", WebhookSignatureHeader, sig, want)
+	}
+
+	var e domain.Event
+	if err := json.Unmarshal(req.body, &e); err != nil || e.ID != 1 || e.EntityID != 7 {
+		t.Errorf("body = %s (%v), want event 1 for product 7", req.body, err)
+	}
+}
+
+func TestWebhookDeliveryRetriesWithBackoff(t *testing.T) {
+	rc := newReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK)
+	ctx, s, repo := newWebhookTest(t, rc)
+
+	for i := 1; i <= 2; i++ {
+		before := time.Now()
+		d := attempt(t, ctx, s, repo)
+		if d.Status != domain.WebhookPending || d.Attempts != i || d.LastError == "" {
+			t.Fatalf("attempt %d: delivery = %+v, want pending with an error", i, d)
+		}
+		wait := webhookRetryBase << (i - 1)
+		if next := d.NextAttemptAt.Sub(before); next < wait || next > wait+time.Second {
+			t.Errorf("attempt %d: next attempt in %v, want %v", i, next, wait)
+		}
+	}
+	if d := lastDelivery(t, ctx, repo); d.LastStatusCode != http.StatusServiceUnavailable || d.LastError != "503 Service Unavailable" {
+		t.Errorf("last status code = %d, error = %q; want 503 and no response body", d.LastStatusCode, d.LastError)
+	}
+
+	d := attempt(t, ctx, s, repo)
+	if d.Status != domain.WebhookSucceeded || d.Attempts != 3 || d.LastError != "" {
+		t.Fatalf("delivery = %+v, want succeeded on the third attempt", d)
+	}
+	if n := len(rc.received()); n != 3 {
+		t.Errorf("receiver got %d requests, want 3", n)
+	}
+}
+
+func TestWebhookDeliveryIsDeadLettered(t *testing.T) {
+	rc := newReceiver(t, http.StatusBadGateway)
+	ctx, s, repo := newWebhookTest(t, rc)
+
+	var d domain.WebhookDelivery
+	for i := 1; i <= webhookMaxAttempts; i++ {
+		d = attempt(t, ctx, s, repo)
+		if i < webhookMaxAttempts && d.Status != domain.WebhookPending {
+			t.Fatalf("attempt %d: status = %s, want pending", i, d.Status)
+		}
+	}
+	if d.Status != domain.WebhookDead || d.Attempts != webhookMaxAttempts || d.LastStatusCode != http.StatusBadGateway {
+		t.Fatalf("delivery = %+v, want dead after %d attempts", d, webhookMaxAttempts)
+	}
+
+	due, err := repo.ClaimDue(ctx, time.Now().Add(48*time.Hour), webhookLease, webhookBatch)
+	if err != nil || len(due) != 0 {
+		t.Fatalf("ClaimDue after dead-lettering = %v, %v; want nothing due", due, err)
+	}
+	dead, err := s.Deliveries(ctx, 1, string(domain.WebhookDead), 0, 0)
+	if err != nil || len(dead) != 1 {
+		t.Fatalf("dead deliveries = %v, %v; want 1", dead, err)
+	}
+
+	redelivered, err := s.Redeliver(ctx, 1, d.ID)
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if redelivered.Status != domain.WebhookPending || redelivered.RedeliveryOf != d.ID {
+		t.Errorf("redelivery = %+v, want pending redelivery of %d", redelivered, d.ID)
+	}
+	if n := len(rc.received()); n != webhookMaxAttempts {
+		t.Errorf("receiver got %d requests, want %d", n, webhookMaxAttempts)
+	}
+}
+
+func TestWebhookRedirectIsNotFollowed(t *testing.T) {
+	rc := newReceiver(t, http.StatusOK)
+	redirect := httptest.NewServer(http.RedirectHandler(rc.URL, http.StatusFound))
+	t.Cleanup(redirect.Close)
+	ctx, s, repo := newWebhookTest(t, &receiver{Server: redirect})
+
+	d := attempt(t, ctx, s, repo)
+	if d.Status != domain.WebhookPending || d.LastStatusCode != http.StatusFound {
+		t.Fatalf("delivery = %+v, want pending after a 302", d)
+	}
+	if n := len(rc.received()); n != 0 {
+		t.Errorf("redirect target got %d requests, want 0", n)
+	}
+}
+
+func TestWebhookEventIsQueuedOnce(t *testing.T) {
+	rc := newReceiver(t, http.StatusOK)
+	ctx, s, repo := newWebhookTest(t, rc)
+
+	n, err := repo.Enqueue(ctx, domain.Event{ID: 1, Type: domain.EventProductUpdated, EntityID: 7})
+	if err != nil || n != 0 {
+		t.Fatalf("Enqueue of a queued event = %d, %v; want 0", n, err)
+	}
+	attempt(t, ctx, s, repo)
+}
+
+func TestWebhookURLMustBePublic(t *testing.T) {
+	ctx := tenant.With(context.Background(), tenant.Default)
+	s := NewWebhookService(repository_memory.NewWebhookRepo(), nil)
+
+	for _, u := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.1.2.3/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/hook",
+		"http://[fd00:ec2::254]/hook",
+		"http://[::ffff:192.168.0.1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		_, err := s.Create(ctx, domain.WebhookSubscription{URL: u})
+		if !errors.Is(err, domain.ErrInvalid) {
+			t.Errorf("Create(%s) = %v, want ErrInvalid", u, err)
+		}
+	}
+}
+
+func TestWebhookClientDialsPublicAddressesOnly(t *testing.T) {
+	rc := newReceiver(t, http.StatusOK)
+
+	resp, err := newWebhookClient().Get(rc.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("GET %s succeeded, want the loopback address refused", rc.URL)
+	}
+	if n := len(rc.received()); n != 0 {
+		t.Errorf("receiver got %d requests, want 0", n)
+	}
+}
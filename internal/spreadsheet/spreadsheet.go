@@ -0,0 +1,126 @@
+// Package spreadsheet reads and writes the tabular files used for bulk
+// import and export: CSV and XLSX. XLSX support covers plain cell values
+// only and needs nothing beyond the standard library.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	"pos-api/internal/domain"
+)
+
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+const (
+	// maxRows and maxCells bound the files Read takes, counting the cells
+	// that pad short rows, as a file far smaller than the upload limit can
+	// describe a sheet too big to hold in memory.
+	maxRows  = 100_000
+	maxCells = 2_000_000
+)
+
+// ContentTypes maps each format to its media type.
+var ContentTypes = map[Format]string{
+	CSV:  "text/csv",
+	XLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// DetectFormat picks the format from an explicit name, then the file name,
+// then the media type and finally the content itself: XLSX files are zip
+// archives starting with "PK".
+func DetectFormat(name, filename, contentType string, data []byte) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(name))); f {
+	case CSV, XLSX:
+		return f, nil
+	case "":
+	default:
+		return "", fmt.Errorf("%w: format must be csv or xlsx", domain.ErrInvalid)
+	}
+
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return CSV, nil
+	case ".xlsx":
+		return XLSX, nil
+	}
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		for f, t := range ContentTypes {
+			if mt == t {
+				return f, nil
+			}
+		}
+	}
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return XLSX, nil
+	}
+	return CSV, nil
+}
+
+// Read returns every row of the file, header included. Short rows are
+// padded so every row has as many cells as the widest one.
+func Read(data []byte, f Format) ([][]string, error) {
+	var rows [][]string
+	var err error
+	switch f {
+	case XLSX:
+		rows, err = readXLSX(data)
+	default:
+		rows, err = readCSV(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalid, err)
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	if len(rows)*width > maxCells {
+		return nil, fmt.Errorf("%w: the file has more than %d cells", domain.ErrInvalid, maxCells)
+	}
+	for i := range rows {
+		for len(rows[i]) < width {
+			rows[i] = append(rows[i], "")
+		}
+	}
+	return rows, nil
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // Excel's UTF-8 BOM
+
+	// Spreadsheets exported with a European locale use semicolons.
+	r := csv.NewReader(bytes.NewReader(data))
+	if first, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var rows [][]string
+	cells := 0
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if cells += len(rec); len(rows) >= maxRows || cells > maxCells {
+			return nil, fmt.Errorf("more than %d rows or %d cells", maxRows, maxCells)
+		}
+		rows = append(rows, rec)
+	}
+}
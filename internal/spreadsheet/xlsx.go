@@ -0,0 +1,293 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxXLSXPart bounds each decompressed part of a workbook.
+	maxXLSXPart = 64 << 20
+	// maxXLSXRows and maxXLSXColumns are the size of a worksheet in Excel:
+	// rows 1 to 1048576 and columns A to XFD.
+	maxXLSXRows    = 1 << 20
+	maxXLSXColumns = 1 << 14
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxRichText is a string that may be split into formatted runs.
+type xlsxRichText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxCell struct {
+	Ref    string       `xml:"r,attr"`
+	Type   string       `xml:"t,attr"`
+	Value  string       `xml:"v"`
+	Inline xlsxRichText `xml:"is"`
+}
+
+// readXLSX reads the first worksheet of a workbook.
+func readXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not an xlsx file: %v", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("worksheet %s is missing", sheetPath)
+	}
+	return readSheet(f, shared)
+}
+
+// readSharedStrings reads the strings cells refer to by index.
+func readSharedStrings(f *zip.File) ([]string, error) {
+	var items []string
+	err := eachElement(f, func(d *xml.Decoder, se xml.StartElement) error {
+		if se.Name.Local != "si" {
+			return nil
+		}
+		if len(items) >= maxCells {
+			return fmt.Errorf("more than %d shared strings", maxCells)
+		}
+		var t xlsxRichText
+		if err := d.DecodeElement(&t, &se); err != nil {
+			return err
+		}
+		items = append(items, t.String())
+		return nil
+	})
+	return items, err
+}
+
+// readSheet reads the rows of a worksheet a cell at a time, so that the
+// limits on rows and cells hold before anything is allocated for them: a
+// small file can claim to fill a cell a million rows down.
+func readSheet(f *zip.File, shared []string) ([][]string, error) {
+	var rows [][]string
+	total := 0
+	row := -1
+	pos := 0
+	err := eachElement(f, func(d *xml.Decoder, se xml.StartElement) error {
+		switch {
+		case se.Name.Local == "row":
+			// Rows and cells may be sparse; their references say where
+			// they go.
+			r := 0
+			for _, a := range se.Attr {
+				if a.Name.Local == "r" {
+					n, err := strconv.Atoi(a.Value)
+					if err != nil || n < 1 || n > maxXLSXRows {
+						return fmt.Errorf("bad row number %q", a.Value)
+					}
+					r = n
+				}
+			}
+			row = max(r-1, len(rows))
+			if row >= maxRows {
+				return fmt.Errorf("more than %d rows", maxRows)
+			}
+			for len(rows) <= row {
+				rows = append(rows, nil)
+			}
+			pos = 0
+			return nil
+		case se.Name.Local != "c" || row < 0:
+			return nil
+		}
+
+		var c xlsxCell
+		if err := d.DecodeElement(&c, &se); err != nil {
+			return err
+		}
+		col := pos
+		pos++
+		if c.Ref != "" {
+			var err error
+			if col, err = columnIndex(c.Ref); err != nil {
+				return err
+			}
+		}
+		cells := rows[row]
+		if grow := col + 1 - len(cells); grow > 0 {
+			if total += grow; total > maxCells {
+				return fmt.Errorf("more than %d cells", maxCells)
+			}
+			cells = append(cells, make([]string, grow)...)
+		}
+
+		switch c.Type {
+		case "s":
+			n, err := strconv.Atoi(c.Value)
+			if err != nil || n < 0 || n >= len(shared) {
+				return fmt.Errorf("cell %s refers to a missing shared string", c.Ref)
+			}
+			cells[col] = shared[n]
+		case "inlineStr":
+			cells[col] = c.Inline.String()
+		case "b":
+			cells[col] = map[string]string{"1": "TRUE", "0": "FALSE"}[c.Value]
+		default:
+			cells[col] = c.Value
+		}
+		rows[row] = cells
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// eachElement calls fn with each element of a part as it starts; fn may
+// decode the element whole.
+func eachElement(f *zip.File, fn func(d *xml.Decoder, se xml.StartElement) error) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	d := xml.NewDecoder(io.LimitReader(rc, maxXLSXPart))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", f.Name, err)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			if err := fn(d, se); err != nil {
+				return fmt.Errorf("%s: %v", f.Name, err)
+			}
+		}
+	}
+}
+
+// firstSheet finds the part holding the first sheet in workbook order.
+func firstSheet(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wf, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("workbook.xml is missing")
+	}
+	var wb xlsxWorkbook
+	if err := decodePart(wf, &wb); err != nil {
+		return "", err
+	}
+	rf, ok := files["xl/_rels/workbook.xml.rels"]
+	if len(wb.Sheets) == 0 || !ok {
+		return fallback, nil
+	}
+	var rels xlsxRelationships
+	if err := decodePart(rf, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return fallback, nil
+}
+
+func decodePart(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, maxXLSXPart)).Decode(v); err != nil {
+		return fmt.Errorf("%s: %v", f.Name, err)
+	}
+	return nil
+}
+
+// columnIndex turns a cell reference such as "C7" into a zero-based column.
+func columnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		if col = col*26 + int(r-'A'+1); col > maxXLSXColumns {
+			return 0, fmt.Errorf("cell reference %q is beyond column XFD", ref)
+		}
+		n++
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("bad cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+// columnName is the inverse of columnIndex: 0 is "A", 26 is "AA".
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
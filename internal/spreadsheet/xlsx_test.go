@@ -0,0 +1,91 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"pos-api/internal/domain"
+)
+
+// testWorkbook zips a one-sheet workbook around the given sheetData.
+func testWorkbook(t *testing.T, sheetData, sharedStrings string) []byte {
+	t.Helper()
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook><sheets/></workbook>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	if sharedStrings != "" {
+		parts["xl/sharedStrings.xml"] = `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + sharedStrings + `</sst>`
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadXLSXSparse(t *testing.T) {
+	data := testWorkbook(t,
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="inlineStr"><is><t>price</t></is></c></row>`+
+			`<row r="3"><c r="B3" t="b"><v>1</v></c><c r="C3"><v>12000</v></c></row>`,
+		`<si><t>name</t></si>`)
+
+	rows, err := Read(data, XLSX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"name", "", "price"},
+		{"", "", ""},
+		{"", "TRUE", "12000"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+// TestReadXLSXLimits feeds sheets that are tiny on disk but describe huge
+// grids; each must be refused before the grid is allocated.
+func TestReadXLSXLimits(t *testing.T) {
+	tests := map[string]string{
+		"row past the sheet":    `<row r="2000000000"><c r="A2000000000"><v>1</v></c></row>`,
+		"column past XFD":       `<row r="1"><c r="XFDXFD1"><v>1</v></c></row>`,
+		"too many rows":         fmt.Sprintf(`<row r="%d"><c><v>1</v></c></row>`, maxRows+1),
+		"too many cells":        strings.Repeat(`<row><c r="XFD1"><v>1</v></c></row>`, maxCells/maxXLSXColumns+1),
+		"too much padding":      `<row><c r="XFD1"><v>1</v></c></row>` + strings.Repeat(`<row><c><v>1</v></c></row>`, maxCells/maxXLSXColumns),
+		"bad row number":        `<row r="-1"><c><v>1</v></c></row>`,
+		"missing shared string": `<row><c t="s"><v>1</v></c></row>`,
+	}
+	for name, sheetData := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(testWorkbook(t, sheetData, ""), XLSX)
+			if !errors.Is(err, domain.ErrInvalid) {
+				t.Errorf("Read = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+func TestReadCSVLimits(t *testing.T) {
+	data := strings.Repeat("a,b\n", maxRows+1)
+	if _, err := Read([]byte(data), CSV); !errors.Is(err, domain.ErrInvalid) {
+		t.Errorf("Read of %d rows = %v, want ErrInvalid", maxRows+1, err)
+	}
+}
@@ -0,0 +1,25 @@
+// Package tenant carries which merchant a request works for in a context,
+// so that the repositories only see and change that merchant's data.
+package tenant
+
+import "context"
+
+// Default is the tenant that data from before tenants belongs to, and the
+// one a deployment serving a single merchant works with.
+const Default = 1
+
+// Resolver returns the ID of the tenant ref names, by ID or slug.
+type Resolver func(ctx context.Context, ref string) (int, error)
+
+type contextKey struct{}
+
+// With returns ctx working for the tenant with the given ID.
+func With(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// From returns the ID of the tenant of ctx, or zero when it has none.
+func From(ctx context.Context) int {
+	id, _ := ctx.Value(contextKey{}).(int)
+	return id
+}
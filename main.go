@@ -1,14 +1,22 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"time"
 
 	"pos-api/database"
 	"pos-api/internal/config"
+	"pos-api/internal/domain"
+	"pos-api/internal/graphqlapi"
+	"pos-api/internal/grpcapi"
 	"pos-api/internal/http/handler"
+	"pos-api/internal/http/httputil"
+	"pos-api/internal/http/routes"
+	"pos-api/internal/ratelimit"
 	"pos-api/internal/repository_postgres"
 	"pos-api/internal/service"
 )
@@ -19,49 +27,202 @@ func main() {
 		log.Fatal("failed to load config: ", err)
 	}
 
-	db, err := database.InitDB(cfg.DatabaseURL)
+	db, err := database.InitDB(cfg.DatabaseURL, cfg.AllowRLSBypass)
 	if err != nil {
 		log.Fatal("failed to connect database: ", err)
 	}
 	defer db.Close()
 
-	// Product
-	productRepo := repository_postgres.NewProductRepo(db)
-	productService := service.NewProductService(productRepo)
-	productHandler := handler.NewProductHandler(productService)
-	http.HandleFunc("GET /api/products", productHandler.GetProducts)
-	http.HandleFunc("GET /api/products/", productHandler.GetProductByID)
-	http.HandleFunc("POST /api/products", productHandler.CreateProduct)
-	http.HandleFunc("PUT /api/products/", productHandler.UpdateProduct)
-	http.HandleFunc("DELETE /api/products/", productHandler.DeleteProduct)
+	// Tenant
+	tenantRepo := repository_postgres.NewTenantRepo(db)
+	tenantService := service.NewTenantService(tenantRepo, domain.Settings{
+		TaxRate:         cfg.TaxRate,
+		Location:        cfg.Location,
+		ValuationMethod: cfg.ValuationMethod,
+		StoreName:       cfg.StoreName,
+		StoreAddress:    cfg.StoreAddress,
+		StorePhone:      cfg.StorePhone,
+		ReceiptFooter:   cfg.ReceiptFooter,
+	})
+	tenantHandler := handler.NewTenantHandler(tenantService)
+
+	// API key
+	apiKeyRepo := repository_postgres.NewAPIKeyRepo(db)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
 
 	// Category
 	categoryRepo := repository_postgres.NewCategoryRepo(db)
 	categoryService := service.NewCategoryService(categoryRepo)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
-	http.HandleFunc("GET /api/categories", categoryHandler.GetCategories)
-	http.HandleFunc("GET /api/categories/", categoryHandler.GetCategoryByID)
-	http.HandleFunc("POST /api/categories", categoryHandler.CreateCategory)
-	http.HandleFunc("PUT /api/categories/", categoryHandler.UpdateCategory)
-	http.HandleFunc("DELETE /api/categories/", categoryHandler.DeleteCategory)
+
+	// Product
+	productRepo := repository_postgres.NewProductRepo(db)
+	productService := service.NewProductService(productRepo, categoryRepo)
+	productHandler := handler.NewProductHandler(productService)
+	stockAlerts := service.NewStockAlertEvaluator(productRepo)
+	stockAlerts.SetTenants(tenantService)
+	stockAlerts.Subscribe(func(a domain.StockAlert) {
+		log.Printf("stock alert: %s product=%d name=%q quantity=%d reorder_point=%d", a.Kind, a.ProductID, a.Name, a.Quantity, a.ReorderPoint)
+	})
+	productService.SetStockNotifier(stockAlerts)
+
+	// Price
+	priceRepo := repository_postgres.NewPriceRepo(db)
+	priceService := service.NewPriceService(priceRepo, productRepo)
+	priceService.SetTenants(tenantService)
+	priceHandler := handler.NewPriceHandler(priceService)
+
+	// Supplier
+	supplierRepo := repository_postgres.NewSupplierRepo(db)
+	supplierService := service.NewSupplierService(supplierRepo)
+	supplierHandler := handler.NewSupplierHandler(supplierService)
+
+	// Location
+	locationRepo := repository_postgres.NewLocationRepo(db)
+	locationService := service.NewLocationService(locationRepo)
+	locationHandler := handler.NewLocationHandler(locationService)
+
+	// Inventory
+	stockTransferRepo := repository_postgres.NewStockTransferRepo(db)
+	purchaseOrderRepo := repository_postgres.NewPurchaseOrderRepo(db)
+	inventoryService := service.NewInventoryService(productRepo, locationRepo, stockTransferRepo, purchaseOrderRepo, supplierRepo)
+	inventoryService.SetStockAlerts(stockAlerts)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService)
+
+	// Purchase order
+	purchaseOrderService := service.NewPurchaseOrderService(purchaseOrderRepo, supplierRepo, productRepo, locationRepo)
+	purchaseOrderService.SetStockNotifier(stockAlerts)
+	purchaseOrderHandler := handler.NewPurchaseOrderHandler(purchaseOrderService)
+
+	// Shift
+	shiftRepo := repository_postgres.NewShiftRepo(db)
+	orderRepo := repository_postgres.NewOrderRepo(db)
+	shiftService := service.NewShiftService(shiftRepo, orderRepo)
+	shiftHandler := handler.NewShiftHandler(shiftService)
+
+	// Order
+	orderService := service.NewOrderService(orderRepo, productRepo, shiftRepo, locationRepo, tenantService)
+	orderService.SetStockNotifier(stockAlerts)
+	orderHandler := handler.NewOrderHandler(orderService)
+
+	// Sync
+	syncRepo := repository_postgres.NewSyncRepo(db)
+	syncService := service.NewSyncService(syncRepo, orderService)
+	syncHandler := handler.NewSyncHandler(syncService)
+
+	// Report
+	reportRepo := repository_postgres.NewReportRepo(db)
+	reportService := service.NewReportService(reportRepo, categoryRepo, tenantService)
+	reportHandler := handler.NewReportHandler(reportService)
+
+	// Import
+	importService := service.NewImportService(productRepo, categoryRepo)
+	importService.SetStockNotifier(stockAlerts)
+	importHandler := handler.NewImportHandler(importService)
+
+	// Audit
+	auditRepo := repository_postgres.NewAuditRepo(db)
+	auditService := service.NewAuditService(auditRepo)
+	auditHandler := handler.NewAuditHandler(auditService)
+
+	// Events
+	outboxRepo := repository_postgres.NewOutboxRepo(db)
+	eventBus := service.NewEventBus()
+	eventRelay := service.NewEventRelay(outboxRepo, eventBus)
+	eventRelay.SetTenants(tenantService)
+
+	// Stream
+	eventStream := service.NewEventStream(outboxRepo)
+	eventBus.Subscribe(eventStream.HandleEvent)
+	streamHandler := handler.NewStreamHandler(eventStream, cfg.WSAllowedOrigins)
+
+	// Webhook
+	webhookRepo := repository_postgres.NewWebhookRepo(db)
+	webhookService := service.NewWebhookService(webhookRepo, nil)
+	webhookService.SetTenants(tenantService)
+	eventBus.Subscribe(webhookService.HandleEvent)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+
+	// GraphQL
+	graphqlHandler := handler.NewGraphQLHandler(graphqlapi.NewSchema(productService, categoryService))
 
 	// Docs
 	docsHandler := handler.NewDocsHandler("openapi.json")
-	http.HandleFunc("GET /openapi.json", docsHandler.ServeSpec)
-	http.HandleFunc("GET /docs", docsHandler.ServeDocs)
-	http.HandleFunc("GET /docs/", docsHandler.RedirectDocs)
-
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{
-			"status":  "OK",
-			"message": "API Running",
-		})
+
+	mux := http.NewServeMux()
+	routes.Register(mux, routes.Handlers{
+		Tenant:        tenantHandler,
+		APIKey:        apiKeyHandler,
+		Category:      categoryHandler,
+		Product:       productHandler,
+		Price:         priceHandler,
+		Supplier:      supplierHandler,
+		Location:      locationHandler,
+		Inventory:     inventoryHandler,
+		PurchaseOrder: purchaseOrderHandler,
+		Shift:         shiftHandler,
+		Order:         orderHandler,
+		Sync:          syncHandler,
+		Report:        reportHandler,
+		Import:        importHandler,
+		Audit:         auditHandler,
+		Stream:        streamHandler,
+		Webhook:       webhookHandler,
+		GraphQL:       graphqlHandler,
+		Docs:          docsHandler,
 	})
 
+	go func() {
+		if err := stockAlerts.Run(context.Background()); err != nil {
+			log.Println("stock alert evaluator stopped: ", err)
+		}
+	}()
+
+	go func() {
+		if err := priceService.Run(context.Background()); err != nil {
+			log.Println("price scheduler stopped: ", err)
+		}
+	}()
+
+	go func() {
+		if err := eventRelay.Run(context.Background()); err != nil {
+			log.Println("event relay stopped: ", err)
+		}
+	}()
+
+	go func() {
+		if err := webhookService.Run(context.Background()); err != nil {
+			log.Println("webhook dispatcher stopped: ", err)
+		}
+	}()
+
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+	if err != nil {
+		log.Fatal("failed to listen for gRPC: ", err)
+	}
+	grpcServer := grpcapi.NewServer(productService, categoryService, tenantService.Resolve, cfg.DefaultTenant, apiKeyService.Authenticate, cfg.APIKeysRequired)
+	go func() {
+		fmt.Printf("Starting gRPC server on :%d\n", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Println("gRPC server stopped: ", err)
+		}
+	}()
+
 	fmt.Println("Starting server on :8081")
 
-	err = http.ListenAndServe(":8081", nil)
+	// Requests that name no tenant can still check health, read the docs and
+	// provision tenants, and those without an API key the first two. Each
+	// address is rate limited before its API key is checked, and clients by
+	// their API key once it is, or else by their address, before anything
+	// else is done for them.
+	limits := ratelimit.NewMemoryStore()
+	server := httputil.WithIdempotency(mux, 24*time.Hour)
+	server = httputil.WithTenant(server, tenantService.Resolve, cfg.DefaultTenant, "/health", "/docs", "/openapi.json", "/api/tenants")
+	server = httputil.WithRateLimit(server, limits, cfg.RateLimits)
+	server = httputil.WithAPIKey(server, apiKeyService.Authenticate, cfg.APIKeysRequired, "/health", "/docs", "/openapi.json")
+	server = httputil.WithAddressRateLimit(server, limits, cfg.AddressRateLimit)
+	err = http.ListenAndServe(":8081", httputil.WithActor(server))
 	if err != nil {
 		fmt.Println("Failed to start server:", err)
 	}